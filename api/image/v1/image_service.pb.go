@@ -7,6 +7,7 @@
 package imagev1
 
 import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
@@ -22,6 +23,109 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// StorageGCMode GC执行模式
+type StorageGCMode int32
+
+const (
+	StorageGCMode_STORAGE_GC_MODE_UNSPECIFIED StorageGCMode = 0
+	StorageGCMode_STORAGE_GC_MODE_DRY_RUN     StorageGCMode = 1 // 只列出将被回收的对象
+	StorageGCMode_STORAGE_GC_MODE_RUN         StorageGCMode = 2 // 删除或归档过期对象并写入审计日志
+	StorageGCMode_STORAGE_GC_MODE_REPORT      StorageGCMode = 3 // 只统计各策略下的对象数、大小和过期情况
+)
+
+// Enum value maps for StorageGCMode.
+var (
+	StorageGCMode_name = map[int32]string{
+		0: "STORAGE_GC_MODE_UNSPECIFIED",
+		1: "STORAGE_GC_MODE_DRY_RUN",
+		2: "STORAGE_GC_MODE_RUN",
+		3: "STORAGE_GC_MODE_REPORT",
+	}
+	StorageGCMode_value = map[string]int32{
+		"STORAGE_GC_MODE_UNSPECIFIED": 0,
+		"STORAGE_GC_MODE_DRY_RUN":     1,
+		"STORAGE_GC_MODE_RUN":         2,
+		"STORAGE_GC_MODE_REPORT":      3,
+	}
+)
+
+func (x StorageGCMode) Enum() *StorageGCMode {
+	p := new(StorageGCMode)
+	*p = x
+	return p
+}
+
+func (x StorageGCMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (StorageGCMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_image_service_proto_enumTypes[0].Descriptor()
+}
+
+func (StorageGCMode) Type() protoreflect.EnumType {
+	return &file_proto_image_service_proto_enumTypes[0]
+}
+
+func (x StorageGCMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use StorageGCMode.Descriptor instead.
+func (StorageGCMode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{0}
+}
+
+// CacheMode 结果缓存的使用方式
+type CacheMode int32
+
+const (
+	CacheMode_CACHE_MODE_UNSPECIFIED CacheMode = 0 // 命中时返回缓存结果，未命中时写入缓存
+	CacheMode_CACHE_MODE_BYPASS      CacheMode = 1 // 不读取也不写入缓存
+	CacheMode_CACHE_MODE_REFRESH     CacheMode = 2 // 不读取缓存，重新生成后覆盖缓存
+)
+
+// Enum value maps for CacheMode.
+var (
+	CacheMode_name = map[int32]string{
+		0: "CACHE_MODE_UNSPECIFIED",
+		1: "CACHE_MODE_BYPASS",
+		2: "CACHE_MODE_REFRESH",
+	}
+	CacheMode_value = map[string]int32{
+		"CACHE_MODE_UNSPECIFIED": 0,
+		"CACHE_MODE_BYPASS":      1,
+		"CACHE_MODE_REFRESH":     2,
+	}
+)
+
+func (x CacheMode) Enum() *CacheMode {
+	p := new(CacheMode)
+	*p = x
+	return p
+}
+
+func (x CacheMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CacheMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_image_service_proto_enumTypes[1].Descriptor()
+}
+
+func (CacheMode) Type() protoreflect.EnumType {
+	return &file_proto_image_service_proto_enumTypes[1]
+}
+
+func (x CacheMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CacheMode.Descriptor instead.
+func (CacheMode) EnumDescriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{1}
+}
+
 // TaskStatus 任务状态
 type TaskStatus int32
 
@@ -62,11 +166,11 @@ func (x TaskStatus) String() string {
 }
 
 func (TaskStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_proto_image_service_proto_enumTypes[0].Descriptor()
+	return file_proto_image_service_proto_enumTypes[2].Descriptor()
 }
 
 func (TaskStatus) Type() protoreflect.EnumType {
-	return &file_proto_image_service_proto_enumTypes[0]
+	return &file_proto_image_service_proto_enumTypes[2]
 }
 
 func (x TaskStatus) Number() protoreflect.EnumNumber {
@@ -75,7 +179,7 @@ func (x TaskStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use TaskStatus.Descriptor instead.
 func (TaskStatus) EnumDescriptor() ([]byte, []int) {
-	return file_proto_image_service_proto_rawDescGZIP(), []int{0}
+	return file_proto_image_service_proto_rawDescGZIP(), []int{2}
 }
 
 // HealthStatus 健康状态
@@ -115,11 +219,11 @@ func (x HealthStatus) String() string {
 }
 
 func (HealthStatus) Descriptor() protoreflect.EnumDescriptor {
-	return file_proto_image_service_proto_enumTypes[1].Descriptor()
+	return file_proto_image_service_proto_enumTypes[3].Descriptor()
 }
 
 func (HealthStatus) Type() protoreflect.EnumType {
-	return &file_proto_image_service_proto_enumTypes[1]
+	return &file_proto_image_service_proto_enumTypes[3]
 }
 
 func (x HealthStatus) Number() protoreflect.EnumNumber {
@@ -128,20 +232,23 @@ func (x HealthStatus) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use HealthStatus.Descriptor instead.
 func (HealthStatus) EnumDescriptor() ([]byte, []int) {
-	return file_proto_image_service_proto_rawDescGZIP(), []int{1}
+	return file_proto_image_service_proto_rawDescGZIP(), []int{3}
 }
 
 // GenerateImageRequest 生成图片请求
 type GenerateImageRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Prompt        string                 `protobuf:"bytes,1,opt,name=prompt,proto3" json:"prompt,omitempty"`                                                                               // 提示词
-	ImageUrls     []string               `protobuf:"bytes,2,rep,name=image_urls,json=imageUrls,proto3" json:"image_urls,omitempty"`                                                        // 参考图片URL（可选）
-	Model         string                 `protobuf:"bytes,3,opt,name=model,proto3" json:"model,omitempty"`                                                                                 // 模型名称（可选）
-	Size          string                 `protobuf:"bytes,4,opt,name=size,proto3" json:"size,omitempty"`                                                                                   // 图片尺寸（可选）
-	Watermark     bool                   `protobuf:"varint,5,opt,name=watermark,proto3" json:"watermark,omitempty"`                                                                        // 是否添加水印
-	Metadata      map[string]string      `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 元数据
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Prompt          string                 `protobuf:"bytes,1,opt,name=prompt,proto3" json:"prompt,omitempty"`                                                                               // 提示词
+	ImageUrls       []string               `protobuf:"bytes,2,rep,name=image_urls,json=imageUrls,proto3" json:"image_urls,omitempty"`                                                        // 参考图片URL（可选）
+	Model           string                 `protobuf:"bytes,3,opt,name=model,proto3" json:"model,omitempty"`                                                                                 // 模型名称（可选）
+	Size            string                 `protobuf:"bytes,4,opt,name=size,proto3" json:"size,omitempty"`                                                                                   // 图片尺寸（可选）
+	Watermark       bool                   `protobuf:"varint,5,opt,name=watermark,proto3" json:"watermark,omitempty"`                                                                        // 是否添加水印
+	Metadata        map[string]string      `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 元数据
+	ResponseFormat  string                 `protobuf:"bytes,7,opt,name=response_format,json=responseFormat,proto3" json:"response_format,omitempty"`                                         // 返回格式：url（默认）或b64_json
+	CacheMode       CacheMode              `protobuf:"varint,8,opt,name=cache_mode,json=cacheMode,proto3,enum=image.v1.CacheMode" json:"cache_mode,omitempty"`                               // 结果缓存的使用方式
+	DisableFailover bool                   `protobuf:"varint,9,opt,name=disable_failover,json=disableFailover,proto3" json:"disable_failover,omitempty"`                                     // 不使用模型别名的故障转移链，主模型失败时直接返回错误
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *GenerateImageRequest) Reset() {
@@ -216,14 +323,42 @@ func (x *GenerateImageRequest) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *GenerateImageRequest) GetResponseFormat() string {
+	if x != nil {
+		return x.ResponseFormat
+	}
+	return ""
+}
+
+func (x *GenerateImageRequest) GetCacheMode() CacheMode {
+	if x != nil {
+		return x.CacheMode
+	}
+	return CacheMode_CACHE_MODE_UNSPECIFIED
+}
+
+func (x *GenerateImageRequest) GetDisableFailover() bool {
+	if x != nil {
+		return x.DisableFailover
+	}
+	return false
+}
+
 // GenerateImageResponse 生成图片响应
 type GenerateImageResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"` // 请求ID
-	Images        []*ImageData           `protobuf:"bytes,2,rep,name=images,proto3" json:"images,omitempty"`                        // 生成的图片
-	Usage         *Usage                 `protobuf:"bytes,3,opt,name=usage,proto3" json:"usage,omitempty"`                          // 使用统计
-	Model         string                 `protobuf:"bytes,4,opt,name=model,proto3" json:"model,omitempty"`                          // 使用的模型
-	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // 创建时间
+	RequestId     string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`            // 请求ID
+	Images        []*ImageData           `protobuf:"bytes,2,rep,name=images,proto3" json:"images,omitempty"`                                   // 生成的图片
+	Usage         *Usage                 `protobuf:"bytes,3,opt,name=usage,proto3" json:"usage,omitempty"`                                     // 使用统计
+	Model         string                 `protobuf:"bytes,4,opt,name=model,proto3" json:"model,omitempty"`                                     // 使用的模型
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`            // 创建时间
+	Cost          *Cost                  `protobuf:"bytes,6,opt,name=cost,proto3" json:"cost,omitempty"`                                       // 费用
+	CacheHit      bool                   `protobuf:"varint,7,opt,name=cache_hit,json=cacheHit,proto3" json:"cache_hit,omitempty"`              // 是否命中结果缓存（命中时不请求上游，不计用量）
+	CachedAt      *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=cached_at,json=cachedAt,proto3" json:"cached_at,omitempty"`               // 命中的缓存结果的生成时间
+	Coalesced     bool                   `protobuf:"varint,9,opt,name=coalesced,proto3" json:"coalesced,omitempty"`                            // 是否与同时进行的相同请求共享了上游调用（共享时不计用量）
+	Alias         string                 `protobuf:"bytes,10,opt,name=alias,proto3" json:"alias,omitempty"`                                    // 请求使用的模型别名（model为别名分配到的具体模型，未使用别名时为空）
+	FallbackUsed  bool                   `protobuf:"varint,11,opt,name=fallback_used,json=fallbackUsed,proto3" json:"fallback_used,omitempty"` // 是否故障转移到了别名的备用模型（model为实际使用的备用模型）
+	Provider      string                 `protobuf:"bytes,12,opt,name=provider,proto3" json:"provider,omitempty"`                              // 实际使用的上游服务
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -293,6 +428,55 @@ func (x *GenerateImageResponse) GetCreatedAt() *timestamppb.Timestamp {
 	return nil
 }
 
+func (x *GenerateImageResponse) GetCost() *Cost {
+	if x != nil {
+		return x.Cost
+	}
+	return nil
+}
+
+func (x *GenerateImageResponse) GetCacheHit() bool {
+	if x != nil {
+		return x.CacheHit
+	}
+	return false
+}
+
+func (x *GenerateImageResponse) GetCachedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CachedAt
+	}
+	return nil
+}
+
+func (x *GenerateImageResponse) GetCoalesced() bool {
+	if x != nil {
+		return x.Coalesced
+	}
+	return false
+}
+
+func (x *GenerateImageResponse) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+func (x *GenerateImageResponse) GetFallbackUsed() bool {
+	if x != nil {
+		return x.FallbackUsed
+	}
+	return false
+}
+
+func (x *GenerateImageResponse) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
 // GenerateImageAsyncResponse 异步生成图片响应
 type GenerateImageAsyncResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -356,15 +540,19 @@ func (x *GenerateImageAsyncResponse) GetCreatedAt() *timestamppb.Timestamp {
 
 // GenerateSequentialImagesRequest 生成序列图片请求
 type GenerateSequentialImagesRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Prompt        string                 `protobuf:"bytes,1,opt,name=prompt,proto3" json:"prompt,omitempty"`                                                                               // 提示词
-	MaxImages     int32                  `protobuf:"varint,2,opt,name=max_images,json=maxImages,proto3" json:"max_images,omitempty"`                                                       // 最大图片数量
-	Model         string                 `protobuf:"bytes,3,opt,name=model,proto3" json:"model,omitempty"`                                                                                 // 模型名称（可选）
-	Size          string                 `protobuf:"bytes,4,opt,name=size,proto3" json:"size,omitempty"`                                                                                   // 图片尺寸（可选）
-	Watermark     bool                   `protobuf:"varint,5,opt,name=watermark,proto3" json:"watermark,omitempty"`                                                                        // 是否添加水印
-	Metadata      map[string]string      `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 元数据
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Prompt          string                 `protobuf:"bytes,1,opt,name=prompt,proto3" json:"prompt,omitempty"`                                                                               // 提示词
+	MaxImages       int32                  `protobuf:"varint,2,opt,name=max_images,json=maxImages,proto3" json:"max_images,omitempty"`                                                       // 最大图片数量
+	Model           string                 `protobuf:"bytes,3,opt,name=model,proto3" json:"model,omitempty"`                                                                                 // 模型名称（可选）
+	Size            string                 `protobuf:"bytes,4,opt,name=size,proto3" json:"size,omitempty"`                                                                                   // 图片尺寸（可选）
+	Watermark       bool                   `protobuf:"varint,5,opt,name=watermark,proto3" json:"watermark,omitempty"`                                                                        // 是否添加水印
+	Metadata        map[string]string      `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // 元数据
+	ImageUrls       []string               `protobuf:"bytes,7,rep,name=image_urls,json=imageUrls,proto3" json:"image_urls,omitempty"`                                                        // 参考图片URL（可选）
+	ResponseFormat  string                 `protobuf:"bytes,8,opt,name=response_format,json=responseFormat,proto3" json:"response_format,omitempty"`                                         // 返回格式：url（默认）或b64_json
+	CacheMode       CacheMode              `protobuf:"varint,9,opt,name=cache_mode,json=cacheMode,proto3,enum=image.v1.CacheMode" json:"cache_mode,omitempty"`                               // 结果缓存的使用方式
+	DisableFailover bool                   `protobuf:"varint,10,opt,name=disable_failover,json=disableFailover,proto3" json:"disable_failover,omitempty"`                                    // 不使用模型别名的故障转移链，主模型失败时直接返回错误
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *GenerateSequentialImagesRequest) Reset() {
@@ -439,6 +627,34 @@ func (x *GenerateSequentialImagesRequest) GetMetadata() map[string]string {
 	return nil
 }
 
+func (x *GenerateSequentialImagesRequest) GetImageUrls() []string {
+	if x != nil {
+		return x.ImageUrls
+	}
+	return nil
+}
+
+func (x *GenerateSequentialImagesRequest) GetResponseFormat() string {
+	if x != nil {
+		return x.ResponseFormat
+	}
+	return ""
+}
+
+func (x *GenerateSequentialImagesRequest) GetCacheMode() CacheMode {
+	if x != nil {
+		return x.CacheMode
+	}
+	return CacheMode_CACHE_MODE_UNSPECIFIED
+}
+
+func (x *GenerateSequentialImagesRequest) GetDisableFailover() bool {
+	if x != nil {
+		return x.DisableFailover
+	}
+	return false
+}
+
 // GetImageTaskRequest 获取图片生成任务请求
 type GetImageTaskRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
@@ -673,6 +889,7 @@ type ImageData struct {
 	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`                                          // 图片URL
 	B64Json       string                 `protobuf:"bytes,2,opt,name=b64_json,json=b64Json,proto3" json:"b64_json,omitempty"`                   // Base64编码的图片数据（可选）
 	RevisedPrompt string                 `protobuf:"bytes,3,opt,name=revised_prompt,json=revisedPrompt,proto3" json:"revised_prompt,omitempty"` // 修订后的提示词
+	Stored        *StoredImage           `protobuf:"bytes,4,opt,name=stored,proto3" json:"stored,omitempty"`                                    // 持久化后的图片（未启用存储或保存失败时为空）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -728,19 +945,136 @@ func (x *ImageData) GetRevisedPrompt() string {
 	return ""
 }
 
+func (x *ImageData) GetStored() *StoredImage {
+	if x != nil {
+		return x.Stored
+	}
+	return nil
+}
+
+// StoredImage 保存到对象存储的图片，url为稳定地址，不随上游临时URL过期
+type StoredImage struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Url                string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`                                                             // 稳定URL（没有可用地址时为空，可通过key访问）
+	Key                string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`                                                             // 对象存储中的键
+	Sha256             string                 `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"`                                                       // 内容的SHA-256（十六进制）
+	SizeBytes          int64                  `protobuf:"varint,4,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`                               // 字节数
+	Width              int32                  `protobuf:"varint,5,opt,name=width,proto3" json:"width,omitempty"`                                                        // 宽度（像素，无法识别时为0）
+	Height             int32                  `protobuf:"varint,6,opt,name=height,proto3" json:"height,omitempty"`                                                      // 高度（像素，无法识别时为0）
+	ContentType        string                 `protobuf:"bytes,7,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`                          // 内容类型，如image/jpeg
+	SignedUrl          string                 `protobuf:"bytes,8,opt,name=signed_url,json=signedUrl,proto3" json:"signed_url,omitempty"`                                // 由本服务提供的签名地址（配置签名密钥时返回）
+	SignedUrlExpiresAt *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=signed_url_expires_at,json=signedUrlExpiresAt,proto3" json:"signed_url_expires_at,omitempty"` // 签名地址的过期时间
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *StoredImage) Reset() {
+	*x = StoredImage{}
+	mi := &file_proto_image_service_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StoredImage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StoredImage) ProtoMessage() {}
+
+func (x *StoredImage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StoredImage.ProtoReflect.Descriptor instead.
+func (*StoredImage) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StoredImage) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *StoredImage) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *StoredImage) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *StoredImage) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *StoredImage) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *StoredImage) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *StoredImage) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *StoredImage) GetSignedUrl() string {
+	if x != nil {
+		return x.SignedUrl
+	}
+	return ""
+}
+
+func (x *StoredImage) GetSignedUrlExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SignedUrlExpiresAt
+	}
+	return nil
+}
+
 // Usage 使用统计
 type Usage struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
-	PromptTokens     int32                  `protobuf:"varint,1,opt,name=prompt_tokens,json=promptTokens,proto3" json:"prompt_tokens,omitempty"`             // 提示词token数
-	CompletionTokens int32                  `protobuf:"varint,2,opt,name=completion_tokens,json=completionTokens,proto3" json:"completion_tokens,omitempty"` // 完成token数
+	PromptTokens     int32                  `protobuf:"varint,1,opt,name=prompt_tokens,json=promptTokens,proto3" json:"prompt_tokens,omitempty"`             // 提示词token数（上游不返回，恒为0）
+	CompletionTokens int32                  `protobuf:"varint,2,opt,name=completion_tokens,json=completionTokens,proto3" json:"completion_tokens,omitempty"` // 完成token数（即上游output_tokens）
 	TotalTokens      int32                  `protobuf:"varint,3,opt,name=total_tokens,json=totalTokens,proto3" json:"total_tokens,omitempty"`                // 总token数
+	GeneratedImages  int32                  `protobuf:"varint,4,opt,name=generated_images,json=generatedImages,proto3" json:"generated_images,omitempty"`    // 生成的图片数量
 	unknownFields    protoimpl.UnknownFields
 	sizeCache        protoimpl.SizeCache
 }
 
 func (x *Usage) Reset() {
 	*x = Usage{}
-	mi := &file_proto_image_service_proto_msgTypes[9]
+	mi := &file_proto_image_service_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -752,7 +1086,7 @@ func (x *Usage) String() string {
 func (*Usage) ProtoMessage() {}
 
 func (x *Usage) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_image_service_proto_msgTypes[9]
+	mi := &file_proto_image_service_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -765,7 +1099,7 @@ func (x *Usage) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Usage.ProtoReflect.Descriptor instead.
 func (*Usage) Descriptor() ([]byte, []int) {
-	return file_proto_image_service_proto_rawDescGZIP(), []int{9}
+	return file_proto_image_service_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *Usage) GetPromptTokens() int32 {
@@ -789,49 +1123,1713 @@ func (x *Usage) GetTotalTokens() int32 {
 	return 0
 }
 
-var File_proto_image_service_proto protoreflect.FileDescriptor
+func (x *Usage) GetGeneratedImages() int32 {
+	if x != nil {
+		return x.GeneratedImages
+	}
+	return 0
+}
 
-const file_proto_image_service_proto_rawDesc = "" +
-	"\n" +
-	"\x19proto/image_service.proto\x12\bimage.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x9c\x02\n" +
-	"\x14GenerateImageRequest\x12\x16\n" +
-	"\x06prompt\x18\x01 \x01(\tR\x06prompt\x12\x1d\n" +
-	"\n" +
-	"image_urls\x18\x02 \x03(\tR\timageUrls\x12\x14\n" +
-	"\x05model\x18\x03 \x01(\tR\x05model\x12\x12\n" +
-	"\x04size\x18\x04 \x01(\tR\x04size\x12\x1c\n" +
-	"\twatermark\x18\x05 \x01(\bR\twatermark\x12H\n" +
-	"\bmetadata\x18\x06 \x03(\v2,.image.v1.GenerateImageRequest.MetadataEntryR\bmetadata\x1a;\n" +
-	"\rMetadataEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xdb\x01\n" +
-	"\x15GenerateImageResponse\x12\x1d\n" +
-	"\n" +
-	"request_id\x18\x01 \x01(\tR\trequestId\x12+\n" +
-	"\x06images\x18\x02 \x03(\v2\x13.image.v1.ImageDataR\x06images\x12%\n" +
-	"\x05usage\x18\x03 \x01(\v2\x0f.image.v1.UsageR\x05usage\x12\x14\n" +
-	"\x05model\x18\x04 \x01(\tR\x05model\x129\n" +
-	"\n" +
-	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x9e\x01\n" +
-	"\x1aGenerateImageAsyncResponse\x12\x17\n" +
-	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12,\n" +
-	"\x06status\x18\x02 \x01(\x0e2\x14.image.v1.TaskStatusR\x06status\x129\n" +
-	"\n" +
-	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xb2\x02\n" +
-	"\x1fGenerateSequentialImagesRequest\x12\x16\n" +
-	"\x06prompt\x18\x01 \x01(\tR\x06prompt\x12\x1d\n" +
-	"\n" +
-	"max_images\x18\x02 \x01(\x05R\tmaxImages\x12\x14\n" +
-	"\x05model\x18\x03 \x01(\tR\x05model\x12\x12\n" +
-	"\x04size\x18\x04 \x01(\tR\x04size\x12\x1c\n" +
-	"\twatermark\x18\x05 \x01(\bR\twatermark\x12S\n" +
-	"\bmetadata\x18\x06 \x03(\v27.image.v1.GenerateSequentialImagesRequest.MetadataEntryR\bmetadata\x1a;\n" +
-	"\rMetadataEntry\x12\x10\n" +
-	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\".\n" +
-	"\x13GetImageTaskRequest\x12\x17\n" +
-	"\atask_id\x18\x01 \x01(\tR\x06taskId\"\xb1\x02\n" +
-	"\x14GetImageTaskResponse\x12\x17\n" +
+// Cost 费用
+type Cost struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Currency      string                 `protobuf:"bytes,1,opt,name=currency,proto3" json:"currency,omitempty"`                                // 币种
+	Estimated     float64                `protobuf:"fixed64,2,opt,name=estimated,proto3" json:"estimated,omitempty"`                            // 请求前的估算费用
+	Actual        float64                `protobuf:"fixed64,3,opt,name=actual,proto3" json:"actual,omitempty"`                                  // 按实际生成图片数计算的费用
+	Downgraded    bool                   `protobuf:"varint,4,opt,name=downgraded,proto3" json:"downgraded,omitempty"`                           // 是否因预算不足被降级
+	DowngradeNote string                 `protobuf:"bytes,5,opt,name=downgrade_note,json=downgradeNote,proto3" json:"downgrade_note,omitempty"` // 降级说明
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Cost) Reset() {
+	*x = Cost{}
+	mi := &file_proto_image_service_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Cost) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cost) ProtoMessage() {}
+
+func (x *Cost) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cost.ProtoReflect.Descriptor instead.
+func (*Cost) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *Cost) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Cost) GetEstimated() float64 {
+	if x != nil {
+		return x.Estimated
+	}
+	return 0
+}
+
+func (x *Cost) GetActual() float64 {
+	if x != nil {
+		return x.Actual
+	}
+	return 0
+}
+
+func (x *Cost) GetDowngraded() bool {
+	if x != nil {
+		return x.Downgraded
+	}
+	return false
+}
+
+func (x *Cost) GetDowngradeNote() string {
+	if x != nil {
+		return x.DowngradeNote
+	}
+	return ""
+}
+
+// GetUsageRequest 查询用量请求
+type GetUsageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tenant        string                 `protobuf:"bytes,1,opt,name=tenant,proto3" json:"tenant,omitempty"`                        // 租户（可选，默认当前租户；查询其他租户需要admin权限）
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"` // 开始时间（包含，按小时对齐）
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`       // 结束时间（不包含，默认当前时间）
+	Model         string                 `protobuf:"bytes,4,opt,name=model,proto3" json:"model,omitempty"`                          // 按模型过滤（可选）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsageRequest) Reset() {
+	*x = GetUsageRequest{}
+	mi := &file_proto_image_service_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageRequest) ProtoMessage() {}
+
+func (x *GetUsageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsageRequest.ProtoReflect.Descriptor instead.
+func (*GetUsageRequest) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetUsageRequest) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+func (x *GetUsageRequest) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *GetUsageRequest) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *GetUsageRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+// GetUsageResponse 查询用量响应
+type GetUsageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tenant        string                 `protobuf:"bytes,1,opt,name=tenant,proto3" json:"tenant,omitempty"`                        // 租户（为空表示全部租户）
+	StartTime     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"` // 实际统计的开始时间
+	EndTime       *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`       // 实际统计的结束时间
+	Total         *UsageSummary          `protobuf:"bytes,4,opt,name=total,proto3" json:"total,omitempty"`                          // 合计
+	Models        []*ModelUsage          `protobuf:"bytes,5,rep,name=models,proto3" json:"models,omitempty"`                        // 按模型拆分
+	Quotas        []*QuotaStatus         `protobuf:"bytes,6,rep,name=quotas,proto3" json:"quotas,omitempty"`                        // 当前配额状态
+	Currency      string                 `protobuf:"bytes,7,opt,name=currency,proto3" json:"currency,omitempty"`                    // 费用币种
+	Budgets       []*BudgetStatus        `protobuf:"bytes,8,rep,name=budgets,proto3" json:"budgets,omitempty"`                      // 当前预算状态
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUsageResponse) Reset() {
+	*x = GetUsageResponse{}
+	mi := &file_proto_image_service_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUsageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsageResponse) ProtoMessage() {}
+
+func (x *GetUsageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsageResponse.ProtoReflect.Descriptor instead.
+func (*GetUsageResponse) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetUsageResponse) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+func (x *GetUsageResponse) GetStartTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartTime
+	}
+	return nil
+}
+
+func (x *GetUsageResponse) GetEndTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndTime
+	}
+	return nil
+}
+
+func (x *GetUsageResponse) GetTotal() *UsageSummary {
+	if x != nil {
+		return x.Total
+	}
+	return nil
+}
+
+func (x *GetUsageResponse) GetModels() []*ModelUsage {
+	if x != nil {
+		return x.Models
+	}
+	return nil
+}
+
+func (x *GetUsageResponse) GetQuotas() []*QuotaStatus {
+	if x != nil {
+		return x.Quotas
+	}
+	return nil
+}
+
+func (x *GetUsageResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *GetUsageResponse) GetBudgets() []*BudgetStatus {
+	if x != nil {
+		return x.Budgets
+	}
+	return nil
+}
+
+// UsageSummary 用量汇总
+type UsageSummary struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Requests        int64                  `protobuf:"varint,1,opt,name=requests,proto3" json:"requests,omitempty"`                                      // 成功请求数
+	GeneratedImages int64                  `protobuf:"varint,2,opt,name=generated_images,json=generatedImages,proto3" json:"generated_images,omitempty"` // 生成的图片数量
+	OutputTokens    int64                  `protobuf:"varint,3,opt,name=output_tokens,json=outputTokens,proto3" json:"output_tokens,omitempty"`          // 输出token数
+	TotalTokens     int64                  `protobuf:"varint,4,opt,name=total_tokens,json=totalTokens,proto3" json:"total_tokens,omitempty"`             // 总token数
+	Cost            float64                `protobuf:"fixed64,5,opt,name=cost,proto3" json:"cost,omitempty"`                                             // 费用
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *UsageSummary) Reset() {
+	*x = UsageSummary{}
+	mi := &file_proto_image_service_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UsageSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UsageSummary) ProtoMessage() {}
+
+func (x *UsageSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UsageSummary.ProtoReflect.Descriptor instead.
+func (*UsageSummary) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *UsageSummary) GetRequests() int64 {
+	if x != nil {
+		return x.Requests
+	}
+	return 0
+}
+
+func (x *UsageSummary) GetGeneratedImages() int64 {
+	if x != nil {
+		return x.GeneratedImages
+	}
+	return 0
+}
+
+func (x *UsageSummary) GetOutputTokens() int64 {
+	if x != nil {
+		return x.OutputTokens
+	}
+	return 0
+}
+
+func (x *UsageSummary) GetTotalTokens() int64 {
+	if x != nil {
+		return x.TotalTokens
+	}
+	return 0
+}
+
+func (x *UsageSummary) GetCost() float64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+// ModelUsage 单个模型的用量
+type ModelUsage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Model         string                 `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"` // 模型名称
+	Usage         *UsageSummary          `protobuf:"bytes,2,opt,name=usage,proto3" json:"usage,omitempty"` // 用量
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModelUsage) Reset() {
+	*x = ModelUsage{}
+	mi := &file_proto_image_service_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModelUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModelUsage) ProtoMessage() {}
+
+func (x *ModelUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModelUsage.ProtoReflect.Descriptor instead.
+func (*ModelUsage) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ModelUsage) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *ModelUsage) GetUsage() *UsageSummary {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+// QuotaStatus 配额状态
+type QuotaStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Window        string                 `protobuf:"bytes,1,opt,name=window,proto3" json:"window,omitempty"`                     // 统计窗口：daily, monthly
+	Resource      string                 `protobuf:"bytes,2,opt,name=resource,proto3" json:"resource,omitempty"`                 // 资源：images, tokens
+	Used          int64                  `protobuf:"varint,3,opt,name=used,proto3" json:"used,omitempty"`                        // 已使用
+	Limit         int64                  `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`                      // 上限（0表示不限制）
+	ResetsAt      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=resets_at,json=resetsAt,proto3" json:"resets_at,omitempty"` // 重置时间
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QuotaStatus) Reset() {
+	*x = QuotaStatus{}
+	mi := &file_proto_image_service_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QuotaStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QuotaStatus) ProtoMessage() {}
+
+func (x *QuotaStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QuotaStatus.ProtoReflect.Descriptor instead.
+func (*QuotaStatus) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *QuotaStatus) GetWindow() string {
+	if x != nil {
+		return x.Window
+	}
+	return ""
+}
+
+func (x *QuotaStatus) GetResource() string {
+	if x != nil {
+		return x.Resource
+	}
+	return ""
+}
+
+func (x *QuotaStatus) GetUsed() int64 {
+	if x != nil {
+		return x.Used
+	}
+	return 0
+}
+
+func (x *QuotaStatus) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *QuotaStatus) GetResetsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ResetsAt
+	}
+	return nil
+}
+
+// BudgetStatus 预算状态
+type BudgetStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Window        string                 `protobuf:"bytes,1,opt,name=window,proto3" json:"window,omitempty"`                     // 统计窗口：daily, monthly
+	Spent         float64                `protobuf:"fixed64,2,opt,name=spent,proto3" json:"spent,omitempty"`                     // 已花费
+	Limit         float64                `protobuf:"fixed64,3,opt,name=limit,proto3" json:"limit,omitempty"`                     // 上限（0表示不限制）
+	ResetsAt      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=resets_at,json=resetsAt,proto3" json:"resets_at,omitempty"` // 重置时间
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BudgetStatus) Reset() {
+	*x = BudgetStatus{}
+	mi := &file_proto_image_service_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BudgetStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BudgetStatus) ProtoMessage() {}
+
+func (x *BudgetStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BudgetStatus.ProtoReflect.Descriptor instead.
+func (*BudgetStatus) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *BudgetStatus) GetWindow() string {
+	if x != nil {
+		return x.Window
+	}
+	return ""
+}
+
+func (x *BudgetStatus) GetSpent() float64 {
+	if x != nil {
+		return x.Spent
+	}
+	return 0
+}
+
+func (x *BudgetStatus) GetLimit() float64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *BudgetStatus) GetResetsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ResetsAt
+	}
+	return nil
+}
+
+// EstimateCostRequest 估算费用请求
+type EstimateCostRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Model         string                 `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`            // 模型名称（可选）
+	Size          string                 `protobuf:"bytes,2,opt,name=size,proto3" json:"size,omitempty"`              // 图片尺寸（可选）
+	Images        int32                  `protobuf:"varint,3,opt,name=images,proto3" json:"images,omitempty"`         // 图片数量（默认1，序列图片为max_images）
+	Sequential    bool                   `protobuf:"varint,4,opt,name=sequential,proto3" json:"sequential,omitempty"` // 是否为序列图片请求（降级时允许减少图片数量）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EstimateCostRequest) Reset() {
+	*x = EstimateCostRequest{}
+	mi := &file_proto_image_service_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EstimateCostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EstimateCostRequest) ProtoMessage() {}
+
+func (x *EstimateCostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EstimateCostRequest.ProtoReflect.Descriptor instead.
+func (*EstimateCostRequest) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *EstimateCostRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *EstimateCostRequest) GetSize() string {
+	if x != nil {
+		return x.Size
+	}
+	return ""
+}
+
+func (x *EstimateCostRequest) GetImages() int32 {
+	if x != nil {
+		return x.Images
+	}
+	return 0
+}
+
+func (x *EstimateCostRequest) GetSequential() bool {
+	if x != nil {
+		return x.Sequential
+	}
+	return false
+}
+
+// EstimateCostResponse 估算费用响应
+type EstimateCostResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Model         string                 `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`                                        // 模型名称
+	Size          string                 `protobuf:"bytes,2,opt,name=size,proto3" json:"size,omitempty"`                                          // 图片尺寸
+	Images        int32                  `protobuf:"varint,3,opt,name=images,proto3" json:"images,omitempty"`                                     // 图片数量
+	Currency      string                 `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`                                  // 币种
+	UnitPrice     float64                `protobuf:"fixed64,5,opt,name=unit_price,json=unitPrice,proto3" json:"unit_price,omitempty"`             // 单图价格
+	EstimatedCost float64                `protobuf:"fixed64,6,opt,name=estimated_cost,json=estimatedCost,proto3" json:"estimated_cost,omitempty"` // 估算费用
+	BudgetAction  string                 `protobuf:"bytes,7,opt,name=budget_action,json=budgetAction,proto3" json:"budget_action,omitempty"`      // 预算策略结果：allow, downgrade, reject
+	PlannedSize   string                 `protobuf:"bytes,8,opt,name=planned_size,json=plannedSize,proto3" json:"planned_size,omitempty"`         // 实际将使用的尺寸（降级后）
+	PlannedImages int32                  `protobuf:"varint,9,opt,name=planned_images,json=plannedImages,proto3" json:"planned_images,omitempty"`  // 实际将请求的图片数量（降级后）
+	PlannedCost   float64                `protobuf:"fixed64,10,opt,name=planned_cost,json=plannedCost,proto3" json:"planned_cost,omitempty"`      // 降级后的估算费用
+	Budgets       []*BudgetStatus        `protobuf:"bytes,11,rep,name=budgets,proto3" json:"budgets,omitempty"`                                   // 当前预算状态
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EstimateCostResponse) Reset() {
+	*x = EstimateCostResponse{}
+	mi := &file_proto_image_service_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EstimateCostResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EstimateCostResponse) ProtoMessage() {}
+
+func (x *EstimateCostResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EstimateCostResponse.ProtoReflect.Descriptor instead.
+func (*EstimateCostResponse) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *EstimateCostResponse) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *EstimateCostResponse) GetSize() string {
+	if x != nil {
+		return x.Size
+	}
+	return ""
+}
+
+func (x *EstimateCostResponse) GetImages() int32 {
+	if x != nil {
+		return x.Images
+	}
+	return 0
+}
+
+func (x *EstimateCostResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *EstimateCostResponse) GetUnitPrice() float64 {
+	if x != nil {
+		return x.UnitPrice
+	}
+	return 0
+}
+
+func (x *EstimateCostResponse) GetEstimatedCost() float64 {
+	if x != nil {
+		return x.EstimatedCost
+	}
+	return 0
+}
+
+func (x *EstimateCostResponse) GetBudgetAction() string {
+	if x != nil {
+		return x.BudgetAction
+	}
+	return ""
+}
+
+func (x *EstimateCostResponse) GetPlannedSize() string {
+	if x != nil {
+		return x.PlannedSize
+	}
+	return ""
+}
+
+func (x *EstimateCostResponse) GetPlannedImages() int32 {
+	if x != nil {
+		return x.PlannedImages
+	}
+	return 0
+}
+
+func (x *EstimateCostResponse) GetPlannedCost() float64 {
+	if x != nil {
+		return x.PlannedCost
+	}
+	return 0
+}
+
+func (x *EstimateCostResponse) GetBudgets() []*BudgetStatus {
+	if x != nil {
+		return x.Budgets
+	}
+	return nil
+}
+
+// ListModelsRequest 列出模型请求
+type ListModelsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Provider      string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"` // 按上游服务过滤（可选）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListModelsRequest) Reset() {
+	*x = ListModelsRequest{}
+	mi := &file_proto_image_service_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListModelsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListModelsRequest) ProtoMessage() {}
+
+func (x *ListModelsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListModelsRequest.ProtoReflect.Descriptor instead.
+func (*ListModelsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListModelsRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+// ListModelsResponse 列出模型响应
+type ListModelsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Models        []*ModelInfo           `protobuf:"bytes,1,rep,name=models,proto3" json:"models,omitempty"`                                 // 模型，按名称排序
+	DefaultModel  string                 `protobuf:"bytes,2,opt,name=default_model,json=defaultModel,proto3" json:"default_model,omitempty"` // 未指定模型时使用的模型
+	DefaultSize   string                 `protobuf:"bytes,3,opt,name=default_size,json=defaultSize,proto3" json:"default_size,omitempty"`    // 未指定尺寸时使用的尺寸
+	Currency      string                 `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`                             // 价格币种
+	Aliases       []*ModelAlias          `protobuf:"bytes,5,rep,name=aliases,proto3" json:"aliases,omitempty"`                               // 模型别名，按名称排序
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListModelsResponse) Reset() {
+	*x = ListModelsResponse{}
+	mi := &file_proto_image_service_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListModelsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListModelsResponse) ProtoMessage() {}
+
+func (x *ListModelsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListModelsResponse.ProtoReflect.Descriptor instead.
+func (*ListModelsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListModelsResponse) GetModels() []*ModelInfo {
+	if x != nil {
+		return x.Models
+	}
+	return nil
+}
+
+func (x *ListModelsResponse) GetDefaultModel() string {
+	if x != nil {
+		return x.DefaultModel
+	}
+	return ""
+}
+
+func (x *ListModelsResponse) GetDefaultSize() string {
+	if x != nil {
+		return x.DefaultSize
+	}
+	return ""
+}
+
+func (x *ListModelsResponse) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *ListModelsResponse) GetAliases() []*ModelAlias {
+	if x != nil {
+		return x.Aliases
+	}
+	return nil
+}
+
+// ModelAlias 模型别名，按权重把请求分配到具体模型
+type ModelAlias struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                               // 别名
+	Targets       []*AliasTarget         `protobuf:"bytes,2,rep,name=targets,proto3" json:"targets,omitempty"`                         // 指向的具体模型
+	StickyKey     string                 `protobuf:"bytes,3,opt,name=sticky_key,json=stickyKey,proto3" json:"sticky_key,omitempty"`    // 请求metadata中该键的值相同时分配到同一个模型
+	Fallbacks     []*ModelFallback       `protobuf:"bytes,4,rep,name=fallbacks,proto3" json:"fallbacks,omitempty"`                     // 分配到的模型失败时依次尝试的备用模型
+	FailoverOn    []string               `protobuf:"bytes,5,rep,name=failover_on,json=failoverOn,proto3" json:"failover_on,omitempty"` // 触发故障转移的错误类型
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModelAlias) Reset() {
+	*x = ModelAlias{}
+	mi := &file_proto_image_service_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModelAlias) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModelAlias) ProtoMessage() {}
+
+func (x *ModelAlias) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModelAlias.ProtoReflect.Descriptor instead.
+func (*ModelAlias) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ModelAlias) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ModelAlias) GetTargets() []*AliasTarget {
+	if x != nil {
+		return x.Targets
+	}
+	return nil
+}
+
+func (x *ModelAlias) GetStickyKey() string {
+	if x != nil {
+		return x.StickyKey
+	}
+	return ""
+}
+
+func (x *ModelAlias) GetFallbacks() []*ModelFallback {
+	if x != nil {
+		return x.Fallbacks
+	}
+	return nil
+}
+
+func (x *ModelAlias) GetFailoverOn() []string {
+	if x != nil {
+		return x.FailoverOn
+	}
+	return nil
+}
+
+// ModelFallback 故障转移的备用模型
+type ModelFallback struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Model         string                 `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`       // 备用模型
+	Provider      string                 `protobuf:"bytes,2,opt,name=provider,proto3" json:"provider,omitempty"` // 上游服务，为空时使用模型自身的上游服务
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModelFallback) Reset() {
+	*x = ModelFallback{}
+	mi := &file_proto_image_service_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModelFallback) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModelFallback) ProtoMessage() {}
+
+func (x *ModelFallback) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModelFallback.ProtoReflect.Descriptor instead.
+func (*ModelFallback) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ModelFallback) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *ModelFallback) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+// AliasTarget 别名指向的具体模型及其权重
+type AliasTarget struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Model         string                 `protobuf:"bytes,1,opt,name=model,proto3" json:"model,omitempty"`    // 具体模型
+	Weight        int32                  `protobuf:"varint,2,opt,name=weight,proto3" json:"weight,omitempty"` // 权重
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AliasTarget) Reset() {
+	*x = AliasTarget{}
+	mi := &file_proto_image_service_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AliasTarget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AliasTarget) ProtoMessage() {}
+
+func (x *AliasTarget) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AliasTarget.ProtoReflect.Descriptor instead.
+func (*AliasTarget) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *AliasTarget) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *AliasTarget) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+// ModelInfo 模型及其能力
+type ModelInfo struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Name               string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`                                                          // 模型名称
+	Provider           string                 `protobuf:"bytes,2,opt,name=provider,proto3" json:"provider,omitempty"`                                                  // 上游服务
+	Sizes              []string               `protobuf:"bytes,3,rep,name=sizes,proto3" json:"sizes,omitempty"`                                                        // 支持的尺寸
+	AspectRatios       []string               `protobuf:"bytes,4,rep,name=aspect_ratios,json=aspectRatios,proto3" json:"aspect_ratios,omitempty"`                      // WIDTHxHEIGHT形式的尺寸允许的宽高比
+	MaxReferenceImages int32                  `protobuf:"varint,5,opt,name=max_reference_images,json=maxReferenceImages,proto3" json:"max_reference_images,omitempty"` // 最多参考图片数量（0表示不支持参考图片）
+	Sequential         bool                   `protobuf:"varint,6,opt,name=sequential,proto3" json:"sequential,omitempty"`                                             // 是否支持序列图片
+	MaxImages          int32                  `protobuf:"varint,7,opt,name=max_images,json=maxImages,proto3" json:"max_images,omitempty"`                              // 单次请求最多生成的图片数量
+	Pricing            *ModelPricing          `protobuf:"bytes,8,opt,name=pricing,proto3" json:"pricing,omitempty"`                                                    // 价格（未定价时为空）
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *ModelInfo) Reset() {
+	*x = ModelInfo{}
+	mi := &file_proto_image_service_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModelInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModelInfo) ProtoMessage() {}
+
+func (x *ModelInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModelInfo.ProtoReflect.Descriptor instead.
+func (*ModelInfo) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ModelInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ModelInfo) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *ModelInfo) GetSizes() []string {
+	if x != nil {
+		return x.Sizes
+	}
+	return nil
+}
+
+func (x *ModelInfo) GetAspectRatios() []string {
+	if x != nil {
+		return x.AspectRatios
+	}
+	return nil
+}
+
+func (x *ModelInfo) GetMaxReferenceImages() int32 {
+	if x != nil {
+		return x.MaxReferenceImages
+	}
+	return 0
+}
+
+func (x *ModelInfo) GetSequential() bool {
+	if x != nil {
+		return x.Sequential
+	}
+	return false
+}
+
+func (x *ModelInfo) GetMaxImages() int32 {
+	if x != nil {
+		return x.MaxImages
+	}
+	return 0
+}
+
+func (x *ModelInfo) GetPricing() *ModelPricing {
+	if x != nil {
+		return x.Pricing
+	}
+	return nil
+}
+
+// ModelPricing 模型价格
+type ModelPricing struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PerImage      float64                `protobuf:"fixed64,1,opt,name=per_image,json=perImage,proto3" json:"per_image,omitempty"`                                                     // 单图价格
+	Sizes         map[string]float64     `protobuf:"bytes,2,rep,name=sizes,proto3" json:"sizes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"` // 按尺寸覆盖的单图价格
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ModelPricing) Reset() {
+	*x = ModelPricing{}
+	mi := &file_proto_image_service_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ModelPricing) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ModelPricing) ProtoMessage() {}
+
+func (x *ModelPricing) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ModelPricing.ProtoReflect.Descriptor instead.
+func (*ModelPricing) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ModelPricing) GetPerImage() float64 {
+	if x != nil {
+		return x.PerImage
+	}
+	return 0
+}
+
+func (x *ModelPricing) GetSizes() map[string]float64 {
+	if x != nil {
+		return x.Sizes
+	}
+	return nil
+}
+
+// RunStorageGCRequest 存储GC请求
+type RunStorageGCRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mode          StorageGCMode          `protobuf:"varint,1,opt,name=mode,proto3,enum=image.v1.StorageGCMode" json:"mode,omitempty"` // 执行模式，未指定时为DRY_RUN
+	Tenant        string                 `protobuf:"bytes,2,opt,name=tenant,proto3" json:"tenant,omitempty"`                          // 只处理该租户的图片（可选）
+	MaxItems      int32                  `protobuf:"varint,3,opt,name=max_items,json=maxItems,proto3" json:"max_items,omitempty"`     // 最多列出的对象数（默认100，最大1000）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunStorageGCRequest) Reset() {
+	*x = RunStorageGCRequest{}
+	mi := &file_proto_image_service_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunStorageGCRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunStorageGCRequest) ProtoMessage() {}
+
+func (x *RunStorageGCRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunStorageGCRequest.ProtoReflect.Descriptor instead.
+func (*RunStorageGCRequest) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *RunStorageGCRequest) GetMode() StorageGCMode {
+	if x != nil {
+		return x.Mode
+	}
+	return StorageGCMode_STORAGE_GC_MODE_UNSPECIFIED
+}
+
+func (x *RunStorageGCRequest) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+func (x *RunStorageGCRequest) GetMaxItems() int32 {
+	if x != nil {
+		return x.MaxItems
+	}
+	return 0
+}
+
+// RunStorageGCResponse 存储GC结果
+type RunStorageGCResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Report        *StorageGCReport       `protobuf:"bytes,1,opt,name=report,proto3" json:"report,omitempty"`                  // 本次执行的结果
+	LastRun       *StorageGCReport       `protobuf:"bytes,2,opt,name=last_run,json=lastRun,proto3" json:"last_run,omitempty"` // 最近一次实际回收的汇总（不含明细）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RunStorageGCResponse) Reset() {
+	*x = RunStorageGCResponse{}
+	mi := &file_proto_image_service_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RunStorageGCResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RunStorageGCResponse) ProtoMessage() {}
+
+func (x *RunStorageGCResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RunStorageGCResponse.ProtoReflect.Descriptor instead.
+func (*RunStorageGCResponse) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *RunStorageGCResponse) GetReport() *StorageGCReport {
+	if x != nil {
+		return x.Report
+	}
+	return nil
+}
+
+func (x *RunStorageGCResponse) GetLastRun() *StorageGCReport {
+	if x != nil {
+		return x.LastRun
+	}
+	return nil
+}
+
+// StorageGCReport GC执行结果
+type StorageGCReport struct {
+	state          protoimpl.MessageState   `protogen:"open.v1"`
+	RunId          string                   `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	Mode           StorageGCMode            `protobuf:"varint,2,opt,name=mode,proto3,enum=image.v1.StorageGCMode" json:"mode,omitempty"`
+	StartedAt      *timestamppb.Timestamp   `protobuf:"bytes,3,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt     *timestamppb.Timestamp   `protobuf:"bytes,4,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	Totals         *StorageGCTotals         `protobuf:"bytes,5,opt,name=totals,proto3" json:"totals,omitempty"`
+	Policies       []*StorageGCPolicyTotals `protobuf:"bytes,6,rep,name=policies,proto3" json:"policies,omitempty"`                                    // 按策略统计
+	Items          []*StorageGCItem         `protobuf:"bytes,7,rep,name=items,proto3" json:"items,omitempty"`                                          // 过期的对象（REPORT模式不列出）
+	ItemsTruncated bool                     `protobuf:"varint,8,opt,name=items_truncated,json=itemsTruncated,proto3" json:"items_truncated,omitempty"` // 过期对象超过max_items
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StorageGCReport) Reset() {
+	*x = StorageGCReport{}
+	mi := &file_proto_image_service_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StorageGCReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageGCReport) ProtoMessage() {}
+
+func (x *StorageGCReport) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageGCReport.ProtoReflect.Descriptor instead.
+func (*StorageGCReport) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *StorageGCReport) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *StorageGCReport) GetMode() StorageGCMode {
+	if x != nil {
+		return x.Mode
+	}
+	return StorageGCMode_STORAGE_GC_MODE_UNSPECIFIED
+}
+
+func (x *StorageGCReport) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *StorageGCReport) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+func (x *StorageGCReport) GetTotals() *StorageGCTotals {
+	if x != nil {
+		return x.Totals
+	}
+	return nil
+}
+
+func (x *StorageGCReport) GetPolicies() []*StorageGCPolicyTotals {
+	if x != nil {
+		return x.Policies
+	}
+	return nil
+}
+
+func (x *StorageGCReport) GetItems() []*StorageGCItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *StorageGCReport) GetItemsTruncated() bool {
+	if x != nil {
+		return x.ItemsTruncated
+	}
+	return false
+}
+
+// StorageGCTotals GC统计
+type StorageGCTotals struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ScannedObjects  int64                  `protobuf:"varint,1,opt,name=scanned_objects,json=scannedObjects,proto3" json:"scanned_objects,omitempty"`
+	ScannedBytes    int64                  `protobuf:"varint,2,opt,name=scanned_bytes,json=scannedBytes,proto3" json:"scanned_bytes,omitempty"`
+	ExpiredObjects  int64                  `protobuf:"varint,3,opt,name=expired_objects,json=expiredObjects,proto3" json:"expired_objects,omitempty"`
+	ExpiredBytes    int64                  `protobuf:"varint,4,opt,name=expired_bytes,json=expiredBytes,proto3" json:"expired_bytes,omitempty"`
+	DeletedObjects  int64                  `protobuf:"varint,5,opt,name=deleted_objects,json=deletedObjects,proto3" json:"deleted_objects,omitempty"`
+	ArchivedObjects int64                  `protobuf:"varint,6,opt,name=archived_objects,json=archivedObjects,proto3" json:"archived_objects,omitempty"`
+	FailedObjects   int64                  `protobuf:"varint,7,opt,name=failed_objects,json=failedObjects,proto3" json:"failed_objects,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *StorageGCTotals) Reset() {
+	*x = StorageGCTotals{}
+	mi := &file_proto_image_service_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StorageGCTotals) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageGCTotals) ProtoMessage() {}
+
+func (x *StorageGCTotals) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageGCTotals.ProtoReflect.Descriptor instead.
+func (*StorageGCTotals) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *StorageGCTotals) GetScannedObjects() int64 {
+	if x != nil {
+		return x.ScannedObjects
+	}
+	return 0
+}
+
+func (x *StorageGCTotals) GetScannedBytes() int64 {
+	if x != nil {
+		return x.ScannedBytes
+	}
+	return 0
+}
+
+func (x *StorageGCTotals) GetExpiredObjects() int64 {
+	if x != nil {
+		return x.ExpiredObjects
+	}
+	return 0
+}
+
+func (x *StorageGCTotals) GetExpiredBytes() int64 {
+	if x != nil {
+		return x.ExpiredBytes
+	}
+	return 0
+}
+
+func (x *StorageGCTotals) GetDeletedObjects() int64 {
+	if x != nil {
+		return x.DeletedObjects
+	}
+	return 0
+}
+
+func (x *StorageGCTotals) GetArchivedObjects() int64 {
+	if x != nil {
+		return x.ArchivedObjects
+	}
+	return 0
+}
+
+func (x *StorageGCTotals) GetFailedObjects() int64 {
+	if x != nil {
+		return x.FailedObjects
+	}
+	return 0
+}
+
+// StorageGCPolicyTotals 单个策略的统计
+type StorageGCPolicyTotals struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Policy        string                 `protobuf:"bytes,1,opt,name=policy,proto3" json:"policy,omitempty"`
+	Totals        *StorageGCTotals       `protobuf:"bytes,2,opt,name=totals,proto3" json:"totals,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StorageGCPolicyTotals) Reset() {
+	*x = StorageGCPolicyTotals{}
+	mi := &file_proto_image_service_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StorageGCPolicyTotals) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageGCPolicyTotals) ProtoMessage() {}
+
+func (x *StorageGCPolicyTotals) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageGCPolicyTotals.ProtoReflect.Descriptor instead.
+func (*StorageGCPolicyTotals) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *StorageGCPolicyTotals) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *StorageGCPolicyTotals) GetTotals() *StorageGCTotals {
+	if x != nil {
+		return x.Totals
+	}
+	return nil
+}
+
+// StorageGCItem 过期对象
+type StorageGCItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Tenant        string                 `protobuf:"bytes,2,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	Policy        string                 `protobuf:"bytes,3,opt,name=policy,proto3" json:"policy,omitempty"` // 匹配的保留策略
+	Action        string                 `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"` // delete, archive
+	Reason        string                 `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"` // 过期原因
+	SizeBytes     int64                  `protobuf:"varint,6,opt,name=size_bytes,json=sizeBytes,proto3" json:"size_bytes,omitempty"`
+	ModifiedAt    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=modified_at,json=modifiedAt,proto3" json:"modified_at,omitempty"`
+	Error         string                 `protobuf:"bytes,8,opt,name=error,proto3" json:"error,omitempty"` // 处理失败的原因（RUN模式）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StorageGCItem) Reset() {
+	*x = StorageGCItem{}
+	mi := &file_proto_image_service_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StorageGCItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageGCItem) ProtoMessage() {}
+
+func (x *StorageGCItem) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_image_service_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageGCItem.ProtoReflect.Descriptor instead.
+func (*StorageGCItem) Descriptor() ([]byte, []int) {
+	return file_proto_image_service_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *StorageGCItem) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *StorageGCItem) GetTenant() string {
+	if x != nil {
+		return x.Tenant
+	}
+	return ""
+}
+
+func (x *StorageGCItem) GetPolicy() string {
+	if x != nil {
+		return x.Policy
+	}
+	return ""
+}
+
+func (x *StorageGCItem) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *StorageGCItem) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *StorageGCItem) GetSizeBytes() int64 {
+	if x != nil {
+		return x.SizeBytes
+	}
+	return 0
+}
+
+func (x *StorageGCItem) GetModifiedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ModifiedAt
+	}
+	return nil
+}
+
+func (x *StorageGCItem) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_proto_image_service_proto protoreflect.FileDescriptor
+
+const file_proto_image_service_proto_rawDesc = "" +
+	"\n" +
+	"\x19proto/image_service.proto\x12\bimage.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa4\x03\n" +
+	"\x14GenerateImageRequest\x12\x16\n" +
+	"\x06prompt\x18\x01 \x01(\tR\x06prompt\x12\x1d\n" +
+	"\n" +
+	"image_urls\x18\x02 \x03(\tR\timageUrls\x12\x14\n" +
+	"\x05model\x18\x03 \x01(\tR\x05model\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\tR\x04size\x12\x1c\n" +
+	"\twatermark\x18\x05 \x01(\bR\twatermark\x12H\n" +
+	"\bmetadata\x18\x06 \x03(\v2,.image.v1.GenerateImageRequest.MetadataEntryR\bmetadata\x12'\n" +
+	"\x0fresponse_format\x18\a \x01(\tR\x0eresponseFormat\x122\n" +
+	"\n" +
+	"cache_mode\x18\b \x01(\x0e2\x13.image.v1.CacheModeR\tcacheMode\x12)\n" +
+	"\x10disable_failover\x18\t \x01(\bR\x0fdisableFailover\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xca\x03\n" +
+	"\x15GenerateImageResponse\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12+\n" +
+	"\x06images\x18\x02 \x03(\v2\x13.image.v1.ImageDataR\x06images\x12%\n" +
+	"\x05usage\x18\x03 \x01(\v2\x0f.image.v1.UsageR\x05usage\x12\x14\n" +
+	"\x05model\x18\x04 \x01(\tR\x05model\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\"\n" +
+	"\x04cost\x18\x06 \x01(\v2\x0e.image.v1.CostR\x04cost\x12\x1b\n" +
+	"\tcache_hit\x18\a \x01(\bR\bcacheHit\x127\n" +
+	"\tcached_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\bcachedAt\x12\x1c\n" +
+	"\tcoalesced\x18\t \x01(\bR\tcoalesced\x12\x14\n" +
+	"\x05alias\x18\n" +
+	" \x01(\tR\x05alias\x12#\n" +
+	"\rfallback_used\x18\v \x01(\bR\ffallbackUsed\x12\x1a\n" +
+	"\bprovider\x18\f \x01(\tR\bprovider\"\x9e\x01\n" +
+	"\x1aGenerateImageAsyncResponse\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12,\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x14.image.v1.TaskStatusR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xd9\x03\n" +
+	"\x1fGenerateSequentialImagesRequest\x12\x16\n" +
+	"\x06prompt\x18\x01 \x01(\tR\x06prompt\x12\x1d\n" +
+	"\n" +
+	"max_images\x18\x02 \x01(\x05R\tmaxImages\x12\x14\n" +
+	"\x05model\x18\x03 \x01(\tR\x05model\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\tR\x04size\x12\x1c\n" +
+	"\twatermark\x18\x05 \x01(\bR\twatermark\x12S\n" +
+	"\bmetadata\x18\x06 \x03(\v27.image.v1.GenerateSequentialImagesRequest.MetadataEntryR\bmetadata\x12\x1d\n" +
+	"\n" +
+	"image_urls\x18\a \x03(\tR\timageUrls\x12'\n" +
+	"\x0fresponse_format\x18\b \x01(\tR\x0eresponseFormat\x122\n" +
+	"\n" +
+	"cache_mode\x18\t \x01(\x0e2\x13.image.v1.CacheModeR\tcacheMode\x12)\n" +
+	"\x10disable_failover\x18\n" +
+	" \x01(\bR\x0fdisableFailover\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\".\n" +
+	"\x13GetImageTaskRequest\x12\x17\n" +
+	"\atask_id\x18\x01 \x01(\tR\x06taskId\"\xb1\x02\n" +
+	"\x14GetImageTaskResponse\x12\x17\n" +
 	"\atask_id\x18\x01 \x01(\tR\x06taskId\x12,\n" +
 	"\x06status\x18\x02 \x01(\x0e2\x14.image.v1.TaskStatusR\x06status\x127\n" +
 	"\x06result\x18\x03 \x01(\v2\x1f.image.v1.GenerateImageResponseR\x06result\x12#\n" +
@@ -847,15 +2845,186 @@ const file_proto_image_service_proto_rawDesc = "" +
 	"\adetails\x18\x03 \x03(\v2*.image.v1.HealthCheckResponse.DetailsEntryR\adetails\x1a:\n" +
 	"\fDetailsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"_\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8e\x01\n" +
 	"\tImageData\x12\x10\n" +
 	"\x03url\x18\x01 \x01(\tR\x03url\x12\x19\n" +
 	"\bb64_json\x18\x02 \x01(\tR\ab64Json\x12%\n" +
-	"\x0erevised_prompt\x18\x03 \x01(\tR\rrevisedPrompt\"|\n" +
+	"\x0erevised_prompt\x18\x03 \x01(\tR\rrevisedPrompt\x12-\n" +
+	"\x06stored\x18\x04 \x01(\v2\x15.image.v1.StoredImageR\x06stored\"\xa7\x02\n" +
+	"\vStoredImage\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x12\x16\n" +
+	"\x06sha256\x18\x03 \x01(\tR\x06sha256\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x04 \x01(\x03R\tsizeBytes\x12\x14\n" +
+	"\x05width\x18\x05 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x06 \x01(\x05R\x06height\x12!\n" +
+	"\fcontent_type\x18\a \x01(\tR\vcontentType\x12\x1d\n" +
+	"\n" +
+	"signed_url\x18\b \x01(\tR\tsignedUrl\x12M\n" +
+	"\x15signed_url_expires_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\x12signedUrlExpiresAt\"\xa7\x01\n" +
 	"\x05Usage\x12#\n" +
 	"\rprompt_tokens\x18\x01 \x01(\x05R\fpromptTokens\x12+\n" +
 	"\x11completion_tokens\x18\x02 \x01(\x05R\x10completionTokens\x12!\n" +
-	"\ftotal_tokens\x18\x03 \x01(\x05R\vtotalTokens*\x91\x01\n" +
+	"\ftotal_tokens\x18\x03 \x01(\x05R\vtotalTokens\x12)\n" +
+	"\x10generated_images\x18\x04 \x01(\x05R\x0fgeneratedImages\"\x9f\x01\n" +
+	"\x04Cost\x12\x1a\n" +
+	"\bcurrency\x18\x01 \x01(\tR\bcurrency\x12\x1c\n" +
+	"\testimated\x18\x02 \x01(\x01R\testimated\x12\x16\n" +
+	"\x06actual\x18\x03 \x01(\x01R\x06actual\x12\x1e\n" +
+	"\n" +
+	"downgraded\x18\x04 \x01(\bR\n" +
+	"downgraded\x12%\n" +
+	"\x0edowngrade_note\x18\x05 \x01(\tR\rdowngradeNote\"\xb1\x01\n" +
+	"\x0fGetUsageRequest\x12\x16\n" +
+	"\x06tenant\x18\x01 \x01(\tR\x06tenant\x129\n" +
+	"\n" +
+	"start_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12\x14\n" +
+	"\x05model\x18\x04 \x01(\tR\x05model\"\xf5\x02\n" +
+	"\x10GetUsageResponse\x12\x16\n" +
+	"\x06tenant\x18\x01 \x01(\tR\x06tenant\x129\n" +
+	"\n" +
+	"start_time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tstartTime\x125\n" +
+	"\bend_time\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\aendTime\x12,\n" +
+	"\x05total\x18\x04 \x01(\v2\x16.image.v1.UsageSummaryR\x05total\x12,\n" +
+	"\x06models\x18\x05 \x03(\v2\x14.image.v1.ModelUsageR\x06models\x12-\n" +
+	"\x06quotas\x18\x06 \x03(\v2\x15.image.v1.QuotaStatusR\x06quotas\x12\x1a\n" +
+	"\bcurrency\x18\a \x01(\tR\bcurrency\x120\n" +
+	"\abudgets\x18\b \x03(\v2\x16.image.v1.BudgetStatusR\abudgets\"\xb1\x01\n" +
+	"\fUsageSummary\x12\x1a\n" +
+	"\brequests\x18\x01 \x01(\x03R\brequests\x12)\n" +
+	"\x10generated_images\x18\x02 \x01(\x03R\x0fgeneratedImages\x12#\n" +
+	"\routput_tokens\x18\x03 \x01(\x03R\foutputTokens\x12!\n" +
+	"\ftotal_tokens\x18\x04 \x01(\x03R\vtotalTokens\x12\x12\n" +
+	"\x04cost\x18\x05 \x01(\x01R\x04cost\"P\n" +
+	"\n" +
+	"ModelUsage\x12\x14\n" +
+	"\x05model\x18\x01 \x01(\tR\x05model\x12,\n" +
+	"\x05usage\x18\x02 \x01(\v2\x16.image.v1.UsageSummaryR\x05usage\"\xa4\x01\n" +
+	"\vQuotaStatus\x12\x16\n" +
+	"\x06window\x18\x01 \x01(\tR\x06window\x12\x1a\n" +
+	"\bresource\x18\x02 \x01(\tR\bresource\x12\x12\n" +
+	"\x04used\x18\x03 \x01(\x03R\x04used\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x03R\x05limit\x127\n" +
+	"\tresets_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\bresetsAt\"\x8b\x01\n" +
+	"\fBudgetStatus\x12\x16\n" +
+	"\x06window\x18\x01 \x01(\tR\x06window\x12\x14\n" +
+	"\x05spent\x18\x02 \x01(\x01R\x05spent\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x01R\x05limit\x127\n" +
+	"\tresets_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\bresetsAt\"w\n" +
+	"\x13EstimateCostRequest\x12\x14\n" +
+	"\x05model\x18\x01 \x01(\tR\x05model\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\tR\x04size\x12\x16\n" +
+	"\x06images\x18\x03 \x01(\x05R\x06images\x12\x1e\n" +
+	"\n" +
+	"sequential\x18\x04 \x01(\bR\n" +
+	"sequential\"\xfe\x02\n" +
+	"\x14EstimateCostResponse\x12\x14\n" +
+	"\x05model\x18\x01 \x01(\tR\x05model\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\tR\x04size\x12\x16\n" +
+	"\x06images\x18\x03 \x01(\x05R\x06images\x12\x1a\n" +
+	"\bcurrency\x18\x04 \x01(\tR\bcurrency\x12\x1d\n" +
+	"\n" +
+	"unit_price\x18\x05 \x01(\x01R\tunitPrice\x12%\n" +
+	"\x0eestimated_cost\x18\x06 \x01(\x01R\restimatedCost\x12#\n" +
+	"\rbudget_action\x18\a \x01(\tR\fbudgetAction\x12!\n" +
+	"\fplanned_size\x18\b \x01(\tR\vplannedSize\x12%\n" +
+	"\x0eplanned_images\x18\t \x01(\x05R\rplannedImages\x12!\n" +
+	"\fplanned_cost\x18\n" +
+	" \x01(\x01R\vplannedCost\x120\n" +
+	"\abudgets\x18\v \x03(\v2\x16.image.v1.BudgetStatusR\abudgets\"/\n" +
+	"\x11ListModelsRequest\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\"\xd5\x01\n" +
+	"\x12ListModelsResponse\x12+\n" +
+	"\x06models\x18\x01 \x03(\v2\x13.image.v1.ModelInfoR\x06models\x12#\n" +
+	"\rdefault_model\x18\x02 \x01(\tR\fdefaultModel\x12!\n" +
+	"\fdefault_size\x18\x03 \x01(\tR\vdefaultSize\x12\x1a\n" +
+	"\bcurrency\x18\x04 \x01(\tR\bcurrency\x12.\n" +
+	"\aaliases\x18\x05 \x03(\v2\x14.image.v1.ModelAliasR\aaliases\"\xc8\x01\n" +
+	"\n" +
+	"ModelAlias\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12/\n" +
+	"\atargets\x18\x02 \x03(\v2\x15.image.v1.AliasTargetR\atargets\x12\x1d\n" +
+	"\n" +
+	"sticky_key\x18\x03 \x01(\tR\tstickyKey\x125\n" +
+	"\tfallbacks\x18\x04 \x03(\v2\x17.image.v1.ModelFallbackR\tfallbacks\x12\x1f\n" +
+	"\vfailover_on\x18\x05 \x03(\tR\n" +
+	"failoverOn\"A\n" +
+	"\rModelFallback\x12\x14\n" +
+	"\x05model\x18\x01 \x01(\tR\x05model\x12\x1a\n" +
+	"\bprovider\x18\x02 \x01(\tR\bprovider\";\n" +
+	"\vAliasTarget\x12\x14\n" +
+	"\x05model\x18\x01 \x01(\tR\x05model\x12\x16\n" +
+	"\x06weight\x18\x02 \x01(\x05R\x06weight\"\x99\x02\n" +
+	"\tModelInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bprovider\x18\x02 \x01(\tR\bprovider\x12\x14\n" +
+	"\x05sizes\x18\x03 \x03(\tR\x05sizes\x12#\n" +
+	"\raspect_ratios\x18\x04 \x03(\tR\faspectRatios\x120\n" +
+	"\x14max_reference_images\x18\x05 \x01(\x05R\x12maxReferenceImages\x12\x1e\n" +
+	"\n" +
+	"sequential\x18\x06 \x01(\bR\n" +
+	"sequential\x12\x1d\n" +
+	"\n" +
+	"max_images\x18\a \x01(\x05R\tmaxImages\x120\n" +
+	"\apricing\x18\b \x01(\v2\x16.image.v1.ModelPricingR\apricing\"\x9e\x01\n" +
+	"\fModelPricing\x12\x1b\n" +
+	"\tper_image\x18\x01 \x01(\x01R\bperImage\x127\n" +
+	"\x05sizes\x18\x02 \x03(\v2!.image.v1.ModelPricing.SizesEntryR\x05sizes\x1a8\n" +
+	"\n" +
+	"SizesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01\"w\n" +
+	"\x13RunStorageGCRequest\x12+\n" +
+	"\x04mode\x18\x01 \x01(\x0e2\x17.image.v1.StorageGCModeR\x04mode\x12\x16\n" +
+	"\x06tenant\x18\x02 \x01(\tR\x06tenant\x12\x1b\n" +
+	"\tmax_items\x18\x03 \x01(\x05R\bmaxItems\"\x7f\n" +
+	"\x14RunStorageGCResponse\x121\n" +
+	"\x06report\x18\x01 \x01(\v2\x19.image.v1.StorageGCReportR\x06report\x124\n" +
+	"\blast_run\x18\x02 \x01(\v2\x19.image.v1.StorageGCReportR\alastRun\"\x95\x03\n" +
+	"\x0fStorageGCReport\x12\x15\n" +
+	"\x06run_id\x18\x01 \x01(\tR\x05runId\x12+\n" +
+	"\x04mode\x18\x02 \x01(\x0e2\x17.image.v1.StorageGCModeR\x04mode\x129\n" +
+	"\n" +
+	"started_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\x121\n" +
+	"\x06totals\x18\x05 \x01(\v2\x19.image.v1.StorageGCTotalsR\x06totals\x12;\n" +
+	"\bpolicies\x18\x06 \x03(\v2\x1f.image.v1.StorageGCPolicyTotalsR\bpolicies\x12-\n" +
+	"\x05items\x18\a \x03(\v2\x17.image.v1.StorageGCItemR\x05items\x12'\n" +
+	"\x0fitems_truncated\x18\b \x01(\bR\x0eitemsTruncated\"\xa8\x02\n" +
+	"\x0fStorageGCTotals\x12'\n" +
+	"\x0fscanned_objects\x18\x01 \x01(\x03R\x0escannedObjects\x12#\n" +
+	"\rscanned_bytes\x18\x02 \x01(\x03R\fscannedBytes\x12'\n" +
+	"\x0fexpired_objects\x18\x03 \x01(\x03R\x0eexpiredObjects\x12#\n" +
+	"\rexpired_bytes\x18\x04 \x01(\x03R\fexpiredBytes\x12'\n" +
+	"\x0fdeleted_objects\x18\x05 \x01(\x03R\x0edeletedObjects\x12)\n" +
+	"\x10archived_objects\x18\x06 \x01(\x03R\x0farchivedObjects\x12%\n" +
+	"\x0efailed_objects\x18\a \x01(\x03R\rfailedObjects\"b\n" +
+	"\x15StorageGCPolicyTotals\x12\x16\n" +
+	"\x06policy\x18\x01 \x01(\tR\x06policy\x121\n" +
+	"\x06totals\x18\x02 \x01(\v2\x19.image.v1.StorageGCTotalsR\x06totals\"\xf3\x01\n" +
+	"\rStorageGCItem\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x16\n" +
+	"\x06tenant\x18\x02 \x01(\tR\x06tenant\x12\x16\n" +
+	"\x06policy\x18\x03 \x01(\tR\x06policy\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\x12\x1d\n" +
+	"\n" +
+	"size_bytes\x18\x06 \x01(\x03R\tsizeBytes\x12;\n" +
+	"\vmodified_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"modifiedAt\x12\x14\n" +
+	"\x05error\x18\b \x01(\tR\x05error*\x82\x01\n" +
+	"\rStorageGCMode\x12\x1f\n" +
+	"\x1bSTORAGE_GC_MODE_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17STORAGE_GC_MODE_DRY_RUN\x10\x01\x12\x17\n" +
+	"\x13STORAGE_GC_MODE_RUN\x10\x02\x12\x1a\n" +
+	"\x16STORAGE_GC_MODE_REPORT\x10\x03*V\n" +
+	"\tCacheMode\x12\x1a\n" +
+	"\x16CACHE_MODE_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11CACHE_MODE_BYPASS\x10\x01\x12\x16\n" +
+	"\x12CACHE_MODE_REFRESH\x10\x02*\x91\x01\n" +
 	"\n" +
 	"TaskStatus\x12\x1b\n" +
 	"\x17TASK_STATUS_UNSPECIFIED\x10\x00\x12\x17\n" +
@@ -867,13 +3036,20 @@ const file_proto_image_service_proto_rawDesc = "" +
 	"\x19HEALTH_STATUS_UNSPECIFIED\x10\x00\x12\x19\n" +
 	"\x15HEALTH_STATUS_SERVING\x10\x01\x12\x1d\n" +
 	"\x19HEALTH_STATUS_NOT_SERVING\x10\x02\x12\x19\n" +
-	"\x15HEALTH_STATUS_UNKNOWN\x10\x032\xbf\x03\n" +
-	"\fImageService\x12P\n" +
-	"\rGenerateImage\x12\x1e.image.v1.GenerateImageRequest\x1a\x1f.image.v1.GenerateImageResponse\x12Z\n" +
-	"\x12GenerateImageAsync\x12\x1e.image.v1.GenerateImageRequest\x1a$.image.v1.GenerateImageAsyncResponse\x12M\n" +
-	"\fGetImageTask\x12\x1d.image.v1.GetImageTaskRequest\x1a\x1e.image.v1.GetImageTaskResponse\x12f\n" +
-	"\x18GenerateSequentialImages\x12).image.v1.GenerateSequentialImagesRequest\x1a\x1f.image.v1.GenerateImageResponse\x12J\n" +
-	"\vHealthCheck\x12\x1c.image.v1.HealthCheckRequest\x1a\x1d.image.v1.HealthCheckResponseB\x1aZ\x18sia/api/image/v1;imagev1b\x06proto3"
+	"\x15HEALTH_STATUS_UNKNOWN\x10\x032\xf0\a\n" +
+	"\fImageService\x12p\n" +
+	"\rGenerateImage\x12\x1e.image.v1.GenerateImageRequest\x1a\x1f.image.v1.GenerateImageResponse\"\x1e\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/v1/images:generate\x12\x7f\n" +
+	"\x12GenerateImageAsync\x12\x1e.image.v1.GenerateImageRequest\x1a$.image.v1.GenerateImageAsyncResponse\"#\x82\xd3\xe4\x93\x02\x1d:\x01*\"\x18/v1/images:generateAsync\x12j\n" +
+	"\fGetImageTask\x12\x1d.image.v1.GetImageTaskRequest\x1a\x1e.image.v1.GetImageTaskResponse\"\x1b\x82\xd3\xe4\x93\x02\x15\x12\x13/v1/tasks/{task_id}\x12\x90\x01\n" +
+	"\x18GenerateSequentialImages\x12).image.v1.GenerateSequentialImagesRequest\x1a\x1f.image.v1.GenerateImageResponse\"(\x82\xd3\xe4\x93\x02\":\x01*\"\x1d/v1/images:generateSequential\x12^\n" +
+	"\vHealthCheck\x12\x1c.image.v1.HealthCheckRequest\x1a\x1d.image.v1.HealthCheckResponse\"\x12\x82\xd3\xe4\x93\x02\f\x12\n" +
+	"/v1/health\x12T\n" +
+	"\bGetUsage\x12\x19.image.v1.GetUsageRequest\x1a\x1a.image.v1.GetUsageResponse\"\x11\x82\xd3\xe4\x93\x02\v\x12\t/v1/usage\x12q\n" +
+	"\fEstimateCost\x12\x1d.image.v1.EstimateCostRequest\x1a\x1e.image.v1.EstimateCostResponse\"\"\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/v1/images:estimateCost\x12[\n" +
+	"\n" +
+	"ListModels\x12\x1b.image.v1.ListModelsRequest\x1a\x1c.image.v1.ListModelsResponse\"\x12\x82\xd3\xe4\x93\x02\f\x12\n" +
+	"/v1/models\x12h\n" +
+	"\fRunStorageGC\x12\x1d.image.v1.RunStorageGCRequest\x1a\x1e.image.v1.RunStorageGCResponse\"\x19\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/v1/storage:gcB\x1aZ\x18sia/api/image/v1;imagev1b\x06proto3"
 
 var (
 	file_proto_image_service_proto_rawDescOnce sync.Once
@@ -887,55 +3063,124 @@ func file_proto_image_service_proto_rawDescGZIP() []byte {
 	return file_proto_image_service_proto_rawDescData
 }
 
-var file_proto_image_service_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_proto_image_service_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_proto_image_service_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_proto_image_service_proto_msgTypes = make([]protoimpl.MessageInfo, 37)
 var file_proto_image_service_proto_goTypes = []any{
-	(TaskStatus)(0),                         // 0: image.v1.TaskStatus
-	(HealthStatus)(0),                       // 1: image.v1.HealthStatus
-	(*GenerateImageRequest)(nil),            // 2: image.v1.GenerateImageRequest
-	(*GenerateImageResponse)(nil),           // 3: image.v1.GenerateImageResponse
-	(*GenerateImageAsyncResponse)(nil),      // 4: image.v1.GenerateImageAsyncResponse
-	(*GenerateSequentialImagesRequest)(nil), // 5: image.v1.GenerateSequentialImagesRequest
-	(*GetImageTaskRequest)(nil),             // 6: image.v1.GetImageTaskRequest
-	(*GetImageTaskResponse)(nil),            // 7: image.v1.GetImageTaskResponse
-	(*HealthCheckRequest)(nil),              // 8: image.v1.HealthCheckRequest
-	(*HealthCheckResponse)(nil),             // 9: image.v1.HealthCheckResponse
-	(*ImageData)(nil),                       // 10: image.v1.ImageData
-	(*Usage)(nil),                           // 11: image.v1.Usage
-	nil,                                     // 12: image.v1.GenerateImageRequest.MetadataEntry
-	nil,                                     // 13: image.v1.GenerateSequentialImagesRequest.MetadataEntry
-	nil,                                     // 14: image.v1.HealthCheckResponse.DetailsEntry
-	(*timestamppb.Timestamp)(nil),           // 15: google.protobuf.Timestamp
+	(StorageGCMode)(0),                      // 0: image.v1.StorageGCMode
+	(CacheMode)(0),                          // 1: image.v1.CacheMode
+	(TaskStatus)(0),                         // 2: image.v1.TaskStatus
+	(HealthStatus)(0),                       // 3: image.v1.HealthStatus
+	(*GenerateImageRequest)(nil),            // 4: image.v1.GenerateImageRequest
+	(*GenerateImageResponse)(nil),           // 5: image.v1.GenerateImageResponse
+	(*GenerateImageAsyncResponse)(nil),      // 6: image.v1.GenerateImageAsyncResponse
+	(*GenerateSequentialImagesRequest)(nil), // 7: image.v1.GenerateSequentialImagesRequest
+	(*GetImageTaskRequest)(nil),             // 8: image.v1.GetImageTaskRequest
+	(*GetImageTaskResponse)(nil),            // 9: image.v1.GetImageTaskResponse
+	(*HealthCheckRequest)(nil),              // 10: image.v1.HealthCheckRequest
+	(*HealthCheckResponse)(nil),             // 11: image.v1.HealthCheckResponse
+	(*ImageData)(nil),                       // 12: image.v1.ImageData
+	(*StoredImage)(nil),                     // 13: image.v1.StoredImage
+	(*Usage)(nil),                           // 14: image.v1.Usage
+	(*Cost)(nil),                            // 15: image.v1.Cost
+	(*GetUsageRequest)(nil),                 // 16: image.v1.GetUsageRequest
+	(*GetUsageResponse)(nil),                // 17: image.v1.GetUsageResponse
+	(*UsageSummary)(nil),                    // 18: image.v1.UsageSummary
+	(*ModelUsage)(nil),                      // 19: image.v1.ModelUsage
+	(*QuotaStatus)(nil),                     // 20: image.v1.QuotaStatus
+	(*BudgetStatus)(nil),                    // 21: image.v1.BudgetStatus
+	(*EstimateCostRequest)(nil),             // 22: image.v1.EstimateCostRequest
+	(*EstimateCostResponse)(nil),            // 23: image.v1.EstimateCostResponse
+	(*ListModelsRequest)(nil),               // 24: image.v1.ListModelsRequest
+	(*ListModelsResponse)(nil),              // 25: image.v1.ListModelsResponse
+	(*ModelAlias)(nil),                      // 26: image.v1.ModelAlias
+	(*ModelFallback)(nil),                   // 27: image.v1.ModelFallback
+	(*AliasTarget)(nil),                     // 28: image.v1.AliasTarget
+	(*ModelInfo)(nil),                       // 29: image.v1.ModelInfo
+	(*ModelPricing)(nil),                    // 30: image.v1.ModelPricing
+	(*RunStorageGCRequest)(nil),             // 31: image.v1.RunStorageGCRequest
+	(*RunStorageGCResponse)(nil),            // 32: image.v1.RunStorageGCResponse
+	(*StorageGCReport)(nil),                 // 33: image.v1.StorageGCReport
+	(*StorageGCTotals)(nil),                 // 34: image.v1.StorageGCTotals
+	(*StorageGCPolicyTotals)(nil),           // 35: image.v1.StorageGCPolicyTotals
+	(*StorageGCItem)(nil),                   // 36: image.v1.StorageGCItem
+	nil,                                     // 37: image.v1.GenerateImageRequest.MetadataEntry
+	nil,                                     // 38: image.v1.GenerateSequentialImagesRequest.MetadataEntry
+	nil,                                     // 39: image.v1.HealthCheckResponse.DetailsEntry
+	nil,                                     // 40: image.v1.ModelPricing.SizesEntry
+	(*timestamppb.Timestamp)(nil),           // 41: google.protobuf.Timestamp
 }
 var file_proto_image_service_proto_depIdxs = []int32{
-	12, // 0: image.v1.GenerateImageRequest.metadata:type_name -> image.v1.GenerateImageRequest.MetadataEntry
-	10, // 1: image.v1.GenerateImageResponse.images:type_name -> image.v1.ImageData
-	11, // 2: image.v1.GenerateImageResponse.usage:type_name -> image.v1.Usage
-	15, // 3: image.v1.GenerateImageResponse.created_at:type_name -> google.protobuf.Timestamp
-	0,  // 4: image.v1.GenerateImageAsyncResponse.status:type_name -> image.v1.TaskStatus
-	15, // 5: image.v1.GenerateImageAsyncResponse.created_at:type_name -> google.protobuf.Timestamp
-	13, // 6: image.v1.GenerateSequentialImagesRequest.metadata:type_name -> image.v1.GenerateSequentialImagesRequest.MetadataEntry
-	0,  // 7: image.v1.GetImageTaskResponse.status:type_name -> image.v1.TaskStatus
-	3,  // 8: image.v1.GetImageTaskResponse.result:type_name -> image.v1.GenerateImageResponse
-	15, // 9: image.v1.GetImageTaskResponse.created_at:type_name -> google.protobuf.Timestamp
-	15, // 10: image.v1.GetImageTaskResponse.updated_at:type_name -> google.protobuf.Timestamp
-	1,  // 11: image.v1.HealthCheckResponse.status:type_name -> image.v1.HealthStatus
-	14, // 12: image.v1.HealthCheckResponse.details:type_name -> image.v1.HealthCheckResponse.DetailsEntry
-	2,  // 13: image.v1.ImageService.GenerateImage:input_type -> image.v1.GenerateImageRequest
-	2,  // 14: image.v1.ImageService.GenerateImageAsync:input_type -> image.v1.GenerateImageRequest
-	6,  // 15: image.v1.ImageService.GetImageTask:input_type -> image.v1.GetImageTaskRequest
-	5,  // 16: image.v1.ImageService.GenerateSequentialImages:input_type -> image.v1.GenerateSequentialImagesRequest
-	8,  // 17: image.v1.ImageService.HealthCheck:input_type -> image.v1.HealthCheckRequest
-	3,  // 18: image.v1.ImageService.GenerateImage:output_type -> image.v1.GenerateImageResponse
-	4,  // 19: image.v1.ImageService.GenerateImageAsync:output_type -> image.v1.GenerateImageAsyncResponse
-	7,  // 20: image.v1.ImageService.GetImageTask:output_type -> image.v1.GetImageTaskResponse
-	3,  // 21: image.v1.ImageService.GenerateSequentialImages:output_type -> image.v1.GenerateImageResponse
-	9,  // 22: image.v1.ImageService.HealthCheck:output_type -> image.v1.HealthCheckResponse
-	18, // [18:23] is the sub-list for method output_type
-	13, // [13:18] is the sub-list for method input_type
-	13, // [13:13] is the sub-list for extension type_name
-	13, // [13:13] is the sub-list for extension extendee
-	0,  // [0:13] is the sub-list for field type_name
+	37, // 0: image.v1.GenerateImageRequest.metadata:type_name -> image.v1.GenerateImageRequest.MetadataEntry
+	1,  // 1: image.v1.GenerateImageRequest.cache_mode:type_name -> image.v1.CacheMode
+	12, // 2: image.v1.GenerateImageResponse.images:type_name -> image.v1.ImageData
+	14, // 3: image.v1.GenerateImageResponse.usage:type_name -> image.v1.Usage
+	41, // 4: image.v1.GenerateImageResponse.created_at:type_name -> google.protobuf.Timestamp
+	15, // 5: image.v1.GenerateImageResponse.cost:type_name -> image.v1.Cost
+	41, // 6: image.v1.GenerateImageResponse.cached_at:type_name -> google.protobuf.Timestamp
+	2,  // 7: image.v1.GenerateImageAsyncResponse.status:type_name -> image.v1.TaskStatus
+	41, // 8: image.v1.GenerateImageAsyncResponse.created_at:type_name -> google.protobuf.Timestamp
+	38, // 9: image.v1.GenerateSequentialImagesRequest.metadata:type_name -> image.v1.GenerateSequentialImagesRequest.MetadataEntry
+	1,  // 10: image.v1.GenerateSequentialImagesRequest.cache_mode:type_name -> image.v1.CacheMode
+	2,  // 11: image.v1.GetImageTaskResponse.status:type_name -> image.v1.TaskStatus
+	5,  // 12: image.v1.GetImageTaskResponse.result:type_name -> image.v1.GenerateImageResponse
+	41, // 13: image.v1.GetImageTaskResponse.created_at:type_name -> google.protobuf.Timestamp
+	41, // 14: image.v1.GetImageTaskResponse.updated_at:type_name -> google.protobuf.Timestamp
+	3,  // 15: image.v1.HealthCheckResponse.status:type_name -> image.v1.HealthStatus
+	39, // 16: image.v1.HealthCheckResponse.details:type_name -> image.v1.HealthCheckResponse.DetailsEntry
+	13, // 17: image.v1.ImageData.stored:type_name -> image.v1.StoredImage
+	41, // 18: image.v1.StoredImage.signed_url_expires_at:type_name -> google.protobuf.Timestamp
+	41, // 19: image.v1.GetUsageRequest.start_time:type_name -> google.protobuf.Timestamp
+	41, // 20: image.v1.GetUsageRequest.end_time:type_name -> google.protobuf.Timestamp
+	41, // 21: image.v1.GetUsageResponse.start_time:type_name -> google.protobuf.Timestamp
+	41, // 22: image.v1.GetUsageResponse.end_time:type_name -> google.protobuf.Timestamp
+	18, // 23: image.v1.GetUsageResponse.total:type_name -> image.v1.UsageSummary
+	19, // 24: image.v1.GetUsageResponse.models:type_name -> image.v1.ModelUsage
+	20, // 25: image.v1.GetUsageResponse.quotas:type_name -> image.v1.QuotaStatus
+	21, // 26: image.v1.GetUsageResponse.budgets:type_name -> image.v1.BudgetStatus
+	18, // 27: image.v1.ModelUsage.usage:type_name -> image.v1.UsageSummary
+	41, // 28: image.v1.QuotaStatus.resets_at:type_name -> google.protobuf.Timestamp
+	41, // 29: image.v1.BudgetStatus.resets_at:type_name -> google.protobuf.Timestamp
+	21, // 30: image.v1.EstimateCostResponse.budgets:type_name -> image.v1.BudgetStatus
+	29, // 31: image.v1.ListModelsResponse.models:type_name -> image.v1.ModelInfo
+	26, // 32: image.v1.ListModelsResponse.aliases:type_name -> image.v1.ModelAlias
+	28, // 33: image.v1.ModelAlias.targets:type_name -> image.v1.AliasTarget
+	27, // 34: image.v1.ModelAlias.fallbacks:type_name -> image.v1.ModelFallback
+	30, // 35: image.v1.ModelInfo.pricing:type_name -> image.v1.ModelPricing
+	40, // 36: image.v1.ModelPricing.sizes:type_name -> image.v1.ModelPricing.SizesEntry
+	0,  // 37: image.v1.RunStorageGCRequest.mode:type_name -> image.v1.StorageGCMode
+	33, // 38: image.v1.RunStorageGCResponse.report:type_name -> image.v1.StorageGCReport
+	33, // 39: image.v1.RunStorageGCResponse.last_run:type_name -> image.v1.StorageGCReport
+	0,  // 40: image.v1.StorageGCReport.mode:type_name -> image.v1.StorageGCMode
+	41, // 41: image.v1.StorageGCReport.started_at:type_name -> google.protobuf.Timestamp
+	41, // 42: image.v1.StorageGCReport.finished_at:type_name -> google.protobuf.Timestamp
+	34, // 43: image.v1.StorageGCReport.totals:type_name -> image.v1.StorageGCTotals
+	35, // 44: image.v1.StorageGCReport.policies:type_name -> image.v1.StorageGCPolicyTotals
+	36, // 45: image.v1.StorageGCReport.items:type_name -> image.v1.StorageGCItem
+	34, // 46: image.v1.StorageGCPolicyTotals.totals:type_name -> image.v1.StorageGCTotals
+	41, // 47: image.v1.StorageGCItem.modified_at:type_name -> google.protobuf.Timestamp
+	4,  // 48: image.v1.ImageService.GenerateImage:input_type -> image.v1.GenerateImageRequest
+	4,  // 49: image.v1.ImageService.GenerateImageAsync:input_type -> image.v1.GenerateImageRequest
+	8,  // 50: image.v1.ImageService.GetImageTask:input_type -> image.v1.GetImageTaskRequest
+	7,  // 51: image.v1.ImageService.GenerateSequentialImages:input_type -> image.v1.GenerateSequentialImagesRequest
+	10, // 52: image.v1.ImageService.HealthCheck:input_type -> image.v1.HealthCheckRequest
+	16, // 53: image.v1.ImageService.GetUsage:input_type -> image.v1.GetUsageRequest
+	22, // 54: image.v1.ImageService.EstimateCost:input_type -> image.v1.EstimateCostRequest
+	24, // 55: image.v1.ImageService.ListModels:input_type -> image.v1.ListModelsRequest
+	31, // 56: image.v1.ImageService.RunStorageGC:input_type -> image.v1.RunStorageGCRequest
+	5,  // 57: image.v1.ImageService.GenerateImage:output_type -> image.v1.GenerateImageResponse
+	6,  // 58: image.v1.ImageService.GenerateImageAsync:output_type -> image.v1.GenerateImageAsyncResponse
+	9,  // 59: image.v1.ImageService.GetImageTask:output_type -> image.v1.GetImageTaskResponse
+	5,  // 60: image.v1.ImageService.GenerateSequentialImages:output_type -> image.v1.GenerateImageResponse
+	11, // 61: image.v1.ImageService.HealthCheck:output_type -> image.v1.HealthCheckResponse
+	17, // 62: image.v1.ImageService.GetUsage:output_type -> image.v1.GetUsageResponse
+	23, // 63: image.v1.ImageService.EstimateCost:output_type -> image.v1.EstimateCostResponse
+	25, // 64: image.v1.ImageService.ListModels:output_type -> image.v1.ListModelsResponse
+	32, // 65: image.v1.ImageService.RunStorageGC:output_type -> image.v1.RunStorageGCResponse
+	57, // [57:66] is the sub-list for method output_type
+	48, // [48:57] is the sub-list for method input_type
+	48, // [48:48] is the sub-list for extension type_name
+	48, // [48:48] is the sub-list for extension extendee
+	0,  // [0:48] is the sub-list for field type_name
 }
 
 func init() { file_proto_image_service_proto_init() }
@@ -948,8 +3193,8 @@ func file_proto_image_service_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_image_service_proto_rawDesc), len(file_proto_image_service_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   13,
+			NumEnums:      4,
+			NumMessages:   37,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
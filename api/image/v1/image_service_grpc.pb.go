@@ -24,6 +24,10 @@ const (
 	ImageService_GetImageTask_FullMethodName             = "/image.v1.ImageService/GetImageTask"
 	ImageService_GenerateSequentialImages_FullMethodName = "/image.v1.ImageService/GenerateSequentialImages"
 	ImageService_HealthCheck_FullMethodName              = "/image.v1.ImageService/HealthCheck"
+	ImageService_GetUsage_FullMethodName                 = "/image.v1.ImageService/GetUsage"
+	ImageService_EstimateCost_FullMethodName             = "/image.v1.ImageService/EstimateCost"
+	ImageService_ListModels_FullMethodName               = "/image.v1.ImageService/ListModels"
+	ImageService_RunStorageGC_FullMethodName             = "/image.v1.ImageService/RunStorageGC"
 )
 
 // ImageServiceClient is the client API for ImageService service.
@@ -42,6 +46,14 @@ type ImageServiceClient interface {
 	GenerateSequentialImages(ctx context.Context, in *GenerateSequentialImagesRequest, opts ...grpc.CallOption) (*GenerateImageResponse, error)
 	// HealthCheck 健康检查
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
+	// GetUsage 查询用量
+	GetUsage(ctx context.Context, in *GetUsageRequest, opts ...grpc.CallOption) (*GetUsageResponse, error)
+	// EstimateCost 估算请求费用并预检预算
+	EstimateCost(ctx context.Context, in *EstimateCostRequest, opts ...grpc.CallOption) (*EstimateCostResponse, error)
+	// ListModels 列出可用的模型及其能力和价格，以及模型别名
+	ListModels(ctx context.Context, in *ListModelsRequest, opts ...grpc.CallOption) (*ListModelsResponse, error)
+	// RunStorageGC 按保留策略回收存储中的图片（需要admin权限）
+	RunStorageGC(ctx context.Context, in *RunStorageGCRequest, opts ...grpc.CallOption) (*RunStorageGCResponse, error)
 }
 
 type imageServiceClient struct {
@@ -102,6 +114,46 @@ func (c *imageServiceClient) HealthCheck(ctx context.Context, in *HealthCheckReq
 	return out, nil
 }
 
+func (c *imageServiceClient) GetUsage(ctx context.Context, in *GetUsageRequest, opts ...grpc.CallOption) (*GetUsageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUsageResponse)
+	err := c.cc.Invoke(ctx, ImageService_GetUsage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) EstimateCost(ctx context.Context, in *EstimateCostRequest, opts ...grpc.CallOption) (*EstimateCostResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EstimateCostResponse)
+	err := c.cc.Invoke(ctx, ImageService_EstimateCost_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) ListModels(ctx context.Context, in *ListModelsRequest, opts ...grpc.CallOption) (*ListModelsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListModelsResponse)
+	err := c.cc.Invoke(ctx, ImageService_ListModels_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *imageServiceClient) RunStorageGC(ctx context.Context, in *RunStorageGCRequest, opts ...grpc.CallOption) (*RunStorageGCResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RunStorageGCResponse)
+	err := c.cc.Invoke(ctx, ImageService_RunStorageGC_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ImageServiceServer is the server API for ImageService service.
 // All implementations must embed UnimplementedImageServiceServer
 // for forward compatibility.
@@ -118,6 +170,14 @@ type ImageServiceServer interface {
 	GenerateSequentialImages(context.Context, *GenerateSequentialImagesRequest) (*GenerateImageResponse, error)
 	// HealthCheck 健康检查
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
+	// GetUsage 查询用量
+	GetUsage(context.Context, *GetUsageRequest) (*GetUsageResponse, error)
+	// EstimateCost 估算请求费用并预检预算
+	EstimateCost(context.Context, *EstimateCostRequest) (*EstimateCostResponse, error)
+	// ListModels 列出可用的模型及其能力和价格，以及模型别名
+	ListModels(context.Context, *ListModelsRequest) (*ListModelsResponse, error)
+	// RunStorageGC 按保留策略回收存储中的图片（需要admin权限）
+	RunStorageGC(context.Context, *RunStorageGCRequest) (*RunStorageGCResponse, error)
 	mustEmbedUnimplementedImageServiceServer()
 }
 
@@ -143,6 +203,18 @@ func (UnimplementedImageServiceServer) GenerateSequentialImages(context.Context,
 func (UnimplementedImageServiceServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
 }
+func (UnimplementedImageServiceServer) GetUsage(context.Context, *GetUsageRequest) (*GetUsageResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUsage not implemented")
+}
+func (UnimplementedImageServiceServer) EstimateCost(context.Context, *EstimateCostRequest) (*EstimateCostResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EstimateCost not implemented")
+}
+func (UnimplementedImageServiceServer) ListModels(context.Context, *ListModelsRequest) (*ListModelsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListModels not implemented")
+}
+func (UnimplementedImageServiceServer) RunStorageGC(context.Context, *RunStorageGCRequest) (*RunStorageGCResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunStorageGC not implemented")
+}
 func (UnimplementedImageServiceServer) mustEmbedUnimplementedImageServiceServer() {}
 func (UnimplementedImageServiceServer) testEmbeddedByValue()                      {}
 
@@ -254,6 +326,78 @@ func _ImageService_HealthCheck_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ImageService_GetUsage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUsageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).GetUsage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_GetUsage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).GetUsage(ctx, req.(*GetUsageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_EstimateCost_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EstimateCostRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).EstimateCost(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_EstimateCost_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).EstimateCost(ctx, req.(*EstimateCostRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_ListModels_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListModelsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).ListModels(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_ListModels_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).ListModels(ctx, req.(*ListModelsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ImageService_RunStorageGC_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunStorageGCRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ImageServiceServer).RunStorageGC(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ImageService_RunStorageGC_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ImageServiceServer).RunStorageGC(ctx, req.(*RunStorageGCRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ImageService_ServiceDesc is the grpc.ServiceDesc for ImageService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -281,6 +425,22 @@ var ImageService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "HealthCheck",
 			Handler:    _ImageService_HealthCheck_Handler,
 		},
+		{
+			MethodName: "GetUsage",
+			Handler:    _ImageService_GetUsage_Handler,
+		},
+		{
+			MethodName: "EstimateCost",
+			Handler:    _ImageService_EstimateCost_Handler,
+		},
+		{
+			MethodName: "ListModels",
+			Handler:    _ImageService_ListModels_Handler,
+		},
+		{
+			MethodName: "RunStorageGC",
+			Handler:    _ImageService_RunStorageGC_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/image_service.proto",
@@ -0,0 +1,9 @@
+// Package openapi 提供由proto生成的OpenAPI文档
+package openapi
+
+import _ "embed"
+
+// ImageService ImageService REST网关的OpenAPI (Swagger 2.0) 文档
+//
+//go:embed image_service.swagger.json
+var ImageService []byte
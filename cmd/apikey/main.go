@@ -0,0 +1,53 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"sia/internal/auth"
+)
+
+// 生成新的API密钥，并输出可写入密钥文件的配置项
+// 明文密钥只在此处输出一次，服务端只保存哈希
+func main() {
+	id := flag.String("id", "", "密钥ID（必需）")
+	clientID := flag.String("client", "", "客户端ID（默认与密钥ID相同）")
+	tenant := flag.String("tenant", "", "租户（必需）")
+	tier := flag.String("tier", "", "限流分级（默认使用服务端默认分级）")
+	scopes := flag.String("scopes", strings.Join([]string{auth.ScopeGenerate, auth.ScopeAsync, auth.ScopeTasksRead, auth.ScopeUsageRead}, ","), "权限范围，逗号分隔")
+	flag.Parse()
+
+	if *id == "" || *tenant == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		log.Fatalf("Failed to generate key: %v", err)
+	}
+	secret := "sia_" + base64.RawURLEncoding.EncodeToString(raw)
+
+	entry := auth.APIKeyEntry{
+		ID:         *id,
+		ClientID:   *clientID,
+		Tenant:     *tenant,
+		Tier:       *tier,
+		Scopes:     strings.Split(*scopes, ","),
+		SecretHash: "sha256:" + auth.HashSecret(secret),
+	}
+
+	out, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to encode entry: %v", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "API key (store it securely, it will not be shown again):\n%s\n\n", secret)
+	fmt.Println(string(out))
+}
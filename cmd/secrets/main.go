@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"sia/internal/secrets"
+)
+
+// 管理加密的本地密钥文件（SECRETS_FILE）
+// 加密密钥从SECRETS_KEY或SECRETS_KEY_FILE读取，与服务端相同
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+	if command == "keygen" {
+		key, err := secrets.GenerateKey()
+		if err != nil {
+			log.Fatalf("Failed to generate key: %v", err)
+		}
+		fmt.Println(key)
+		return
+	}
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	file := flags.String("file", os.Getenv("SECRETS_FILE"), "加密密钥文件（默认使用SECRETS_FILE）")
+	flags.Parse(args)
+	if *file == "" {
+		log.Fatal("-file or SECRETS_FILE is required")
+	}
+
+	key := loadKey()
+	values, err := secrets.ReadEncryptedFile(*file, key)
+	if errors.Is(err, os.ErrNotExist) && command == "set" {
+		values, err = make(map[string]string), nil
+	}
+	if err != nil {
+		log.Fatalf("Failed to read secrets file: %v", err)
+	}
+
+	switch command {
+	case "list":
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return
+
+	case "set":
+		// 未在命令行给出值时从标准输入读取，避免密钥留在shell历史中
+		if flags.NArg() < 1 || flags.NArg() > 2 {
+			usage()
+		}
+		name, value := flags.Arg(0), flags.Arg(1)
+		if flags.NArg() == 1 {
+			fmt.Fprintf(os.Stderr, "Value for %s: ", name)
+			line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+			if err != nil && line == "" {
+				log.Fatalf("Failed to read value: %v", err)
+			}
+			value = strings.TrimRight(line, "\r\n")
+		}
+		values[name] = value
+
+	case "delete":
+		if flags.NArg() != 1 {
+			usage()
+		}
+		if _, ok := values[flags.Arg(0)]; !ok {
+			log.Fatalf("Secret %s not found", flags.Arg(0))
+		}
+		delete(values, flags.Arg(0))
+
+	default:
+		usage()
+	}
+
+	if err := secrets.WriteEncryptedFile(*file, key, values); err != nil {
+		log.Fatalf("Failed to write secrets file: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "Updated %s (%d secrets)\n", *file, len(values))
+}
+
+// loadKey 读取加密密钥
+func loadKey() []byte {
+	chain := secrets.Chain{secrets.EnvProvider{}, secrets.FileProvider{}}
+	encoded, _, ok, err := chain.Lookup(context.Background(), "SECRETS_KEY")
+	if err != nil {
+		log.Fatalf("Failed to read key: %v", err)
+	}
+	if !ok {
+		log.Fatal("SECRETS_KEY or SECRETS_KEY_FILE is required (generate one with: secrets keygen)")
+	}
+	key, err := secrets.ParseKey(encoded)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return key
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `Usage:
+  secrets keygen                           生成加密密钥
+  secrets list   [-file F]                 列出密钥名称
+  secrets set    [-file F] NAME [VALUE]    设置密钥，未给出VALUE时从标准输入读取
+  secrets delete [-file F] NAME            删除密钥`)
+	os.Exit(2)
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"net"
 	"net/http"
@@ -15,21 +17,36 @@ import (
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
+	"sia/internal/auth"
 	"sia/internal/config"
 	"sia/internal/server"
 	"sia/internal/service"
+	"sia/internal/tracing"
 	"sia/pkg/logger"
 )
 
-func main() {
-	// 初始化日志
-	logger := logger.New()
+// healthWatchInterval 同步gRPC健康状态的间隔（上游探测结果另有缓存）
+const healthWatchInterval = 5 * time.Second
 
+func main() {
 	// 加载配置
-	cfg, err := config.Load()
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		// 配置不可用时使用默认日志配置输出错误
+		bootstrap, _ := logger.New(logger.Options{})
+		bootstrap.Fatal("Failed to load config", "error", err)
+	}
+
+	// 初始化日志
+	logger, err := logger.New(logOptions(cfg.Log))
 	if err != nil {
-		logger.Fatal("Failed to load config", "error", err)
+		fmt.Fprintf(os.Stderr, "failed to initialize logger: %v\n", err)
+		os.Exit(1)
 	}
+	defer logger.Close()
 
 	logger.Info("Starting SIA Image Service",
 		"version", cfg.App.Version,
@@ -38,19 +55,71 @@ func main() {
 		"http_port", cfg.Server.HTTPPort,
 	)
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 初始化链路追踪
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing, cfg.App)
+	if err != nil {
+		logger.Fatal("Failed to initialize tracing", "error", err)
+	}
+	defer func() {
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer flushCancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			logger.Error("Failed to flush traces", "error", err)
+		}
+	}()
+
 	// 创建服务
-	imageService := service.NewImageService(cfg, logger)
+	imageService, err := service.NewImageService(cfg, logger)
+	if err != nil {
+		logger.Fatal("Failed to create image service", "error", err)
+	}
+	defer imageService.Close()
+
+	// 创建认证器
+	authenticator, err := newAuthenticator(ctx, cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize authentication", "error", err)
+	}
 
 	// 创建gRPC服务器
-	grpcServer := server.NewGRPCServer(cfg, logger, imageService)
+	grpcServer := server.NewGRPCServer(cfg, logger, imageService, authenticator)
 
-	// 创建HTTP服务器（用于健康检查和指标）
-	httpServer := server.NewHTTPServer(cfg, logger)
+	// 创建HTTP服务器（用于健康检查、指标、管理端点和REST网关）
+	var gateway http.Handler
+	if cfg.Server.GatewayEnabled {
+		gateway, err = server.NewGateway(ctx, cfg, logger)
+		if err != nil {
+			logger.Fatal("Failed to create REST gateway", "error", err)
+		}
+	}
+	httpServer := server.NewHTTPServer(cfg, logger, authenticator, imageService, gateway)
 
-	// 启动服务器
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// 注册gRPC健康检查，并定期同步就绪检查结果
+	healthServer := health.NewServer()
+	grpc_health_v1.RegisterHealthServer(grpcServer, healthServer)
+	go server.WatchHealth(ctx, healthServer, imageService.Readiness(), healthWatchInterval, logger)
+	go imageService.RunRetention(ctx)
 
+	// 定期刷新密钥
+	if err := refreshSecrets(ctx, cfg, logger, imageService); err != nil {
+		logger.Fatal("Failed to initialize secret refresh", "error", err)
+	}
+
+	// 监听配置变化和SIGHUP，热加载可以在运行时修改的配置
+	reload, err := newReloader(cfg, logger, imageService, authenticator)
+	if err != nil {
+		logger.Fatal("Failed to initialize config reload", "error", err)
+	}
+	go func() {
+		if err := config.Watch(ctx, os.Args[1:], cfg, reload.apply); err != nil {
+			logger.Error("Config watcher failed, hot reload is disabled", "error", err)
+		}
+	}()
+
+	// 启动服务器
 	// 启动gRPC服务器
 	go func() {
 		if err := startGRPCServer(grpcServer, cfg.Server.GRPCPort, logger); err != nil {
@@ -71,6 +140,73 @@ func main() {
 	waitForShutdown(ctx, cancel, grpcServer, httpServer, logger)
 }
 
+// logOptions 将日志配置转换为日志器选项
+func logOptions(cfg config.LogConfig) logger.Options {
+	patterns := make([]logger.RedactionPattern, 0, len(cfg.Redaction.Patterns))
+	for _, pattern := range cfg.Redaction.Patterns {
+		patterns = append(patterns, logger.RedactionPattern{Pattern: pattern.Pattern, Replacement: pattern.Replacement})
+	}
+
+	return logger.Options{
+		Level:      cfg.Level,
+		Format:     cfg.Format,
+		Output:     cfg.Output,
+		File:       cfg.File,
+		MaxSize:    cfg.MaxSize,
+		MaxAge:     cfg.MaxAge,
+		MaxBackups: cfg.MaxBackups,
+		Compress:   cfg.Compress,
+		Redaction: logger.RedactionOptions{
+			Enabled:      cfg.Redaction.Enabled,
+			Prompts:      cfg.Redaction.Prompts,
+			PromptLength: cfg.Redaction.PromptLength,
+			PromptKeys:   cfg.Redaction.PromptKeys,
+			SecretKeys:   cfg.Redaction.SecretKeys,
+			MaskURLQuery: cfg.Redaction.MaskURLQuery,
+			Patterns:     patterns,
+		},
+	}
+}
+
+// newAuthenticator 根据配置创建认证器，未启用认证时返回nil
+func newAuthenticator(ctx context.Context, cfg *config.Config) (auth.Authenticator, error) {
+	if !cfg.Auth.Enabled {
+		return nil, nil
+	}
+
+	multi := &auth.MultiAuthenticator{}
+
+	if cfg.Auth.APIKeysFile != "" {
+		apiKeyAuth, err := auth.LoadAPIKeyAuthenticator(cfg.Auth.APIKeysFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load API keys: %w", err)
+		}
+		multi.APIKey = apiKeyAuth
+	}
+
+	if jwtCfg := cfg.Auth.JWT; jwtCfg.Enabled {
+		source := jwtCfg.JWKSFile
+		if source == "" {
+			source = jwtCfg.JWKSURL
+		}
+		jwks := auth.NewJWKS(source, time.Duration(jwtCfg.JWKSCacheTTL)*time.Second)
+		if err := jwks.Load(ctx); err != nil {
+			return nil, fmt.Errorf("failed to load JWKS: %w", err)
+		}
+		multi.JWT = auth.NewJWTAuthenticator(jwks, auth.JWTOptions{
+			Issuer:        jwtCfg.Issuer,
+			Audiences:     jwtCfg.Audiences,
+			TenantClaim:   jwtCfg.TenantClaim,
+			ScopeClaim:    jwtCfg.ScopeClaim,
+			ClientIDClaim: jwtCfg.ClientIDClaim,
+			ClockSkew:     time.Duration(jwtCfg.ClockSkew) * time.Second,
+			TierClaim:     jwtCfg.TierClaim,
+		})
+	}
+
+	return multi, nil
+}
+
 func startGRPCServer(server *grpc.Server, port int, logger *logger.Logger) error {
 	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
@@ -82,11 +218,6 @@ func startGRPCServer(server *grpc.Server, port int, logger *logger.Logger) error
 	// 启用反射（开发环境）
 	reflection.Register(server)
 
-	// 注册健康检查
-	healthServer := health.NewServer()
-	grpc_health_v1.RegisterHealthServer(server, healthServer)
-	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
-
 	return server.Serve(lis)
 }
 
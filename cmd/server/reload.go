@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"sia/internal/auth"
+	"sia/internal/config"
+	"sia/internal/metrics"
+	"sia/internal/service"
+	"sia/pkg/logger"
+)
+
+// reloader 校验并应用热加载的配置
+type reloader struct {
+	current      *config.Config
+	logger       *logger.Logger
+	imageService *service.ImageService
+	apiKeyAuth   *auth.APIKeyAuthenticator // 未启用API密钥认证时为nil
+	apiKeys      []auth.APIKeyEntry        // 当前生效的API密钥
+}
+
+// newReloader 创建配置热加载器
+func newReloader(cfg *config.Config, logger *logger.Logger, imageService *service.ImageService, authenticator auth.Authenticator) (*reloader, error) {
+	r := &reloader{current: cfg, logger: logger, imageService: imageService}
+
+	if multi, ok := authenticator.(*auth.MultiAuthenticator); ok {
+		if apiKeyAuth, ok := multi.APIKey.(*auth.APIKeyAuthenticator); ok {
+			entries, err := auth.LoadAPIKeyFile(cfg.Auth.APIKeysFile)
+			if err != nil {
+				return nil, err
+			}
+			r.apiKeyAuth, r.apiKeys = apiKeyAuth, entries
+		}
+	}
+	return r, nil
+}
+
+// apply 应用新配置，返回生效的配置；配置无效或修改了需要重启的配置项时保留原配置并返回nil
+func (r *reloader) apply(cfg *config.Config, err error) *config.Config {
+	if err != nil {
+		r.logger.Error("Config reload failed, keeping current config", "error", err)
+		metrics.ConfigReloads.WithLabelValues("failed").Inc()
+		return nil
+	}
+
+	changes := config.Diff(r.current, cfg)
+
+	var restart []string
+	for _, change := range changes {
+		if change.Restart {
+			restart = append(restart, change.Path)
+		}
+	}
+	if len(restart) > 0 {
+		r.logger.Error("Config reload rejected, changed settings require a restart", "settings", restart)
+		metrics.ConfigReloads.WithLabelValues("rejected").Inc()
+		return nil
+	}
+
+	// API密钥文件的内容不在配置中，每次重新加载时都重新读取
+	keysReloaded, err := r.reloadAPIKeys(cfg)
+	if err != nil {
+		r.logger.Error("Config reload failed, keeping current config", "error", err)
+		metrics.ConfigReloads.WithLabelValues("failed").Inc()
+		return nil
+	}
+
+	if len(changes) == 0 && !keysReloaded {
+		r.logger.Info("Config reloaded, no changes")
+		metrics.ConfigReloads.WithLabelValues("unchanged").Inc()
+		return cfg
+	}
+
+	// 日志级别只在配置变化时设置，不覆盖通过管理端点临时调整的级别
+	if cfg.Log.Level != r.current.Log.Level {
+		if err := r.logger.SetLevel(cfg.Log.Level); err != nil {
+			r.logger.Error("Config reload failed, keeping current config", "error", err)
+			metrics.ConfigReloads.WithLabelValues("failed").Inc()
+			return nil
+		}
+	}
+	r.imageService.Reload(cfg)
+	r.current = cfg
+
+	for _, change := range changes {
+		r.logger.Info("Config setting changed", "setting", change.Path, "old", change.Old, "new", change.New)
+	}
+	r.logger.Info("Config reloaded", "changes", len(changes), "api_keys_reloaded", keysReloaded)
+	metrics.ConfigReloads.WithLabelValues("applied").Inc()
+	metrics.ConfigLastReload.SetToCurrentTime()
+	return cfg
+}
+
+// reloadAPIKeys 重新读取API密钥文件，内容变化时替换全部密钥并返回true
+func (r *reloader) reloadAPIKeys(cfg *config.Config) (bool, error) {
+	if r.apiKeyAuth == nil {
+		return false, nil
+	}
+
+	entries, err := auth.LoadAPIKeyFile(cfg.Auth.APIKeysFile)
+	if err != nil {
+		return false, fmt.Errorf("failed to load API keys: %w", err)
+	}
+	if reflect.DeepEqual(entries, r.apiKeys) {
+		return false, nil
+	}
+	if err := r.apiKeyAuth.SetKeys(entries); err != nil {
+		return false, fmt.Errorf("invalid API keys: %w", err)
+	}
+
+	// 只记录密钥ID
+	previous := make(map[string]auth.APIKeyEntry, len(r.apiKeys))
+	for _, entry := range r.apiKeys {
+		previous[entry.ID] = entry
+	}
+	var added, updated []string
+	for _, entry := range entries {
+		old, ok := previous[entry.ID]
+		switch {
+		case !ok:
+			added = append(added, entry.ID)
+		case !reflect.DeepEqual(old, entry):
+			updated = append(updated, entry.ID)
+		}
+		delete(previous, entry.ID)
+	}
+	removed := make([]string, 0, len(previous))
+	for id := range previous {
+		removed = append(removed, id)
+	}
+	sort.Strings(removed)
+	r.logger.Info("API keys changed", "added", added, "updated", updated, "removed", removed)
+
+	r.apiKeys = entries
+	return true, nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"sia/internal/config"
+	"sia/internal/secrets"
+	"sia/internal/service"
+	"sia/pkg/logger"
+)
+
+// refreshSecrets 定期重新读取上游API Key（IMAGE_API_KEY、IMAGE_API_KEYS和备用上游服务的PROVIDER_<NAME>_API_KEYS），
+// 轮换后的密钥在下一个请求生效；未配置刷新间隔时直接返回
+func refreshSecrets(ctx context.Context, cfg *config.Config, logger *logger.Logger, imageService *service.ImageService) error {
+	if cfg.Secrets.RefreshInterval <= 0 {
+		return nil
+	}
+
+	providers, err := cfg.SecretProviders()
+	if err != nil {
+		return err
+	}
+
+	initial := map[string]string{
+		"IMAGE_API_KEY":  cfg.Image.APIKey,
+		"IMAGE_API_KEYS": config.FormatUpstreamKeys(cfg.Image.APIKeys),
+	}
+	// 密钥名到备用上游服务名称
+	providerKeys := make(map[string]string, len(cfg.Models.Providers))
+	for name, provider := range cfg.Models.Providers {
+		providerKeys[config.ProviderKeysEnv(name)] = name
+		initial[config.ProviderKeysEnv(name)] = config.FormatUpstreamKeys(provider.APIKeys)
+	}
+
+	refresher := secrets.NewRefresher(providers, initial)
+	go refresher.Run(ctx, time.Duration(cfg.Secrets.RefreshInterval)*time.Second,
+		func(changed map[string]string) {
+			for secret, name := range providerKeys {
+				if keys, ok := changed[secret]; ok {
+					rotated := config.ParseUpstreamKeys(keys)
+					imageService.RotateProviderKeys(name, rotated)
+					logger.Info("Provider API keys rotated", "provider", name, "keys", keyIDs(rotated))
+				}
+			}
+			apiKey, apiKeyChanged := changed["IMAGE_API_KEY"]
+			apiKeys, apiKeysChanged := changed["IMAGE_API_KEYS"]
+			if !apiKeyChanged && !apiKeysChanged {
+				return
+			}
+
+			// 以服务当前的配置为基础，保留热加载后的设置
+			image := imageService.Config().Image
+			if apiKeyChanged {
+				image.APIKey = apiKey
+			}
+			if apiKeysChanged {
+				image.APIKeys = config.ParseUpstreamKeys(apiKeys)
+			}
+			imageService.RotateAPIKeys(image)
+
+			logger.Info("Upstream API keys rotated", "keys", keyIDs(image.UpstreamKeys()))
+		},
+		func(err error) {
+			logger.Warn("Secret refresh failed, keeping current secrets", "error", err)
+		},
+	)
+	return nil
+}
+
+// keyIDs 返回密钥ID，日志中只记录ID
+func keyIDs(keys []config.UpstreamKeyConfig) []string {
+	ids := make([]string, len(keys))
+	for i, key := range keys {
+		ids[i] = key.ID
+	}
+	return ids
+}
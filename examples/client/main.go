@@ -3,10 +3,12 @@ package main
 import (
 	"context"
 	"log"
+	"os"
 	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 
 	imagev1 "sia/api/image/v1"
 )
@@ -22,9 +24,15 @@ func main() {
 	// 创建客户端
 	client := imagev1.NewImageServiceClient(conn)
 
+	// 携带API密钥（服务端启用认证时需要）
+	ctx := context.Background()
+	if apiKey := os.Getenv("SIA_API_KEY"); apiKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+apiKey)
+	}
+
 	// 测试健康检查
 	log.Println("=== 健康检查 ===")
-	healthResp, err := client.HealthCheck(context.Background(), &imagev1.HealthCheckRequest{})
+	healthResp, err := client.HealthCheck(ctx, &imagev1.HealthCheckRequest{})
 	if err != nil {
 		log.Printf("Health check failed: %v", err)
 	} else {
@@ -44,7 +52,7 @@ func main() {
 		},
 	}
 
-	generateResp, err := client.GenerateImage(context.Background(), generateReq)
+	generateResp, err := client.GenerateImage(ctx, generateReq)
 	if err != nil {
 		log.Printf("Generate image failed: %v", err)
 	} else {
@@ -62,7 +70,7 @@ func main() {
 		Watermark: true,
 	}
 
-	asyncResp, err := client.GenerateImageAsync(context.Background(), asyncReq)
+	asyncResp, err := client.GenerateImageAsync(ctx, asyncReq)
 	if err != nil {
 		log.Printf("Async generate image failed: %v", err)
 	} else {
@@ -73,7 +81,7 @@ func main() {
 		for i := 0; i < 30; i++ { // 最多等待30秒
 			time.Sleep(1 * time.Second)
 
-			taskResp, err := client.GetImageTask(context.Background(), &imagev1.GetImageTaskRequest{
+			taskResp, err := client.GetImageTask(ctx, &imagev1.GetImageTaskRequest{
 				TaskId: taskId,
 			})
 			if err != nil {
@@ -105,7 +113,7 @@ func main() {
 		Watermark: true,
 	}
 
-	sequentialResp, err := client.GenerateSequentialImages(context.Background(), sequentialReq)
+	sequentialResp, err := client.GenerateSequentialImages(ctx, sequentialReq)
 	if err != nil {
 		log.Printf("Generate sequential images failed: %v", err)
 	} else {
@@ -0,0 +1,154 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hashPrefix 密钥哈希前缀
+const hashPrefix = "sha256:"
+
+// APIKeyEntry API密钥配置项
+type APIKeyEntry struct {
+	ID         string     `json:"id"`
+	ClientID   string     `json:"client_id"`
+	Tenant     string     `json:"tenant"`
+	Scopes     []string   `json:"scopes"`
+	Tier       string     `json:"tier,omitempty"`
+	SecretHash string     `json:"secret_hash"` // 形如 "sha256:<hex>"
+	Disabled   bool       `json:"disabled,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// APIKeyFile API密钥文件格式
+type APIKeyFile struct {
+	Keys []APIKeyEntry `json:"keys"`
+}
+
+// APIKeyAuthenticator 基于API密钥的认证器
+type APIKeyAuthenticator struct {
+	mutex sync.RWMutex
+	keys  map[string]APIKeyEntry // 以密钥哈希为索引
+}
+
+// NewAPIKeyAuthenticator 创建API密钥认证器
+func NewAPIKeyAuthenticator(entries []APIKeyEntry) (*APIKeyAuthenticator, error) {
+	a := &APIKeyAuthenticator{}
+	if err := a.SetKeys(entries); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// LoadAPIKeyAuthenticator 从文件加载API密钥认证器
+func LoadAPIKeyAuthenticator(path string) (*APIKeyAuthenticator, error) {
+	entries, err := LoadAPIKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewAPIKeyAuthenticator(entries)
+}
+
+// LoadAPIKeyFile 读取API密钥文件
+func LoadAPIKeyFile(path string) ([]APIKeyEntry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read api keys file: %w", err)
+	}
+
+	var file APIKeyFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse api keys file: %w", err)
+	}
+
+	return file.Keys, nil
+}
+
+// SetKeys 替换全部密钥
+func (a *APIKeyAuthenticator) SetKeys(entries []APIKeyEntry) error {
+	keys := make(map[string]APIKeyEntry, len(entries))
+	for i, entry := range entries {
+		if entry.ID == "" {
+			return fmt.Errorf("api key #%d: id is required", i)
+		}
+		if entry.Tenant == "" {
+			return fmt.Errorf("api key %s: tenant is required", entry.ID)
+		}
+		digest, err := parseSecretHash(entry.SecretHash)
+		if err != nil {
+			return fmt.Errorf("api key %s: %w", entry.ID, err)
+		}
+		if _, exists := keys[digest]; exists {
+			return fmt.Errorf("api key %s: duplicate secret hash", entry.ID)
+		}
+		if entry.ClientID == "" {
+			entry.ClientID = entry.ID
+		}
+		keys[digest] = entry
+	}
+
+	a.mutex.Lock()
+	a.keys = keys
+	a.mutex.Unlock()
+
+	return nil
+}
+
+// Authenticate 校验API密钥
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context, credential string) (*Principal, error) {
+	if credential == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	digest := HashSecret(credential)
+
+	a.mutex.RLock()
+	entry, exists := a.keys[digest]
+	a.mutex.RUnlock()
+
+	// 以摘要查找，原始密钥不参与比较
+	if !exists || entry.Disabled {
+		return nil, ErrInvalidCredentials
+	}
+
+	if entry.ExpiresAt != nil && time.Now().After(*entry.ExpiresAt) {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Principal{
+		ClientID: entry.ClientID,
+		Tenant:   entry.Tenant,
+		Scopes:   append([]string(nil), entry.Scopes...),
+		Tier:     entry.Tier,
+		Method:   "api_key",
+		KeyID:    entry.ID,
+	}, nil
+}
+
+// HashSecret 计算密钥的SHA-256摘要（十六进制）
+func HashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// parseSecretHash 解析密钥哈希配置
+func parseSecretHash(value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if !strings.HasPrefix(value, hashPrefix) {
+		return "", fmt.Errorf("secret_hash must start with %q", hashPrefix)
+	}
+
+	digest := strings.TrimPrefix(value, hashPrefix)
+	if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != sha256.Size {
+		return "", fmt.Errorf("secret_hash is not a valid sha256 digest")
+	}
+
+	return digest, nil
+}
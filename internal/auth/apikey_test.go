@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAPIKeyAuthenticatorAuthenticate(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	authenticator, err := NewAPIKeyAuthenticator([]APIKeyEntry{
+		{ID: "active", Tenant: "acme", Scopes: []string{"images:generate"}, Tier: "pro", SecretHash: hashPrefix + HashSecret("active-secret")},
+		{ID: "named", ClientID: "named-client", Tenant: "acme", SecretHash: "SHA256:" + HashSecret("named-secret")},
+		{ID: "disabled", Tenant: "acme", SecretHash: hashPrefix + HashSecret("disabled-secret"), Disabled: true},
+		{ID: "expired", Tenant: "acme", SecretHash: hashPrefix + HashSecret("expired-secret"), ExpiresAt: &past},
+		{ID: "valid", Tenant: "acme", SecretHash: hashPrefix + HashSecret("valid-secret"), ExpiresAt: &future},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		credential string
+		wantErr    error
+		wantClient string
+	}{
+		{name: "missing", credential: "", wantErr: ErrMissingCredentials},
+		{name: "unknown", credential: "unknown-secret", wantErr: ErrInvalidCredentials},
+		{name: "active", credential: "active-secret", wantClient: "active"},
+		{name: "explicit client id", credential: "named-secret", wantClient: "named-client"},
+		{name: "disabled", credential: "disabled-secret", wantErr: ErrInvalidCredentials},
+		{name: "expired", credential: "expired-secret", wantErr: ErrInvalidCredentials},
+		{name: "not yet expired", credential: "valid-secret", wantClient: "valid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := authenticator.Authenticate(context.Background(), tt.credential)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if principal.ClientID != tt.wantClient || principal.Tenant != "acme" || principal.Method != "api_key" {
+				t.Fatalf("Authenticate() = %+v", principal)
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuthenticatorCopiesScopes(t *testing.T) {
+	authenticator, err := NewAPIKeyAuthenticator([]APIKeyEntry{
+		{ID: "key", Tenant: "acme", Scopes: []string{"images:generate"}, SecretHash: hashPrefix + HashSecret("secret")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	principal, err := authenticator.Authenticate(context.Background(), "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	principal.Scopes[0] = "admin"
+
+	again, err := authenticator.Authenticate(context.Background(), "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Scopes[0] != "images:generate" {
+		t.Fatalf("scopes were shared between principals: %v", again.Scopes)
+	}
+}
+
+func TestAPIKeyAuthenticatorSetKeys(t *testing.T) {
+	valid := hashPrefix + HashSecret("secret")
+
+	tests := []struct {
+		name    string
+		entries []APIKeyEntry
+		wantErr bool
+	}{
+		{name: "valid", entries: []APIKeyEntry{{ID: "a", Tenant: "acme", SecretHash: valid}}},
+		{name: "empty", entries: nil},
+		{name: "missing id", entries: []APIKeyEntry{{Tenant: "acme", SecretHash: valid}}, wantErr: true},
+		{name: "missing tenant", entries: []APIKeyEntry{{ID: "a", SecretHash: valid}}, wantErr: true},
+		{name: "missing prefix", entries: []APIKeyEntry{{ID: "a", Tenant: "acme", SecretHash: HashSecret("secret")}}, wantErr: true},
+		{name: "short digest", entries: []APIKeyEntry{{ID: "a", Tenant: "acme", SecretHash: hashPrefix + "abcd"}}, wantErr: true},
+		{name: "not hex", entries: []APIKeyEntry{{ID: "a", Tenant: "acme", SecretHash: hashPrefix + "zz"}}, wantErr: true},
+		{name: "duplicate hash", entries: []APIKeyEntry{
+			{ID: "a", Tenant: "acme", SecretHash: valid},
+			{ID: "b", Tenant: "acme", SecretHash: valid},
+		}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewAPIKeyAuthenticator(tt.entries)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewAPIKeyAuthenticator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuthenticatorRotation(t *testing.T) {
+	authenticator, err := NewAPIKeyAuthenticator([]APIKeyEntry{
+		{ID: "old", Tenant: "acme", SecretHash: hashPrefix + HashSecret("old-secret")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 无效的新配置不影响现有密钥
+	if err := authenticator.SetKeys([]APIKeyEntry{{ID: "new"}}); err == nil {
+		t.Fatal("SetKeys() accepted an entry without tenant")
+	}
+	if _, err := authenticator.Authenticate(context.Background(), "old-secret"); err != nil {
+		t.Fatalf("old key rejected after failed rotation: %v", err)
+	}
+
+	if err := authenticator.SetKeys([]APIKeyEntry{
+		{ID: "new", Tenant: "acme", SecretHash: hashPrefix + HashSecret("new-secret")},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := authenticator.Authenticate(context.Background(), "old-secret"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("old key still accepted after rotation: %v", err)
+	}
+	if _, err := authenticator.Authenticate(context.Background(), "new-secret"); err != nil {
+		t.Fatalf("new key rejected: %v", err)
+	}
+}
+
+func TestLoadAPIKeyAuthenticator(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "api_keys.json")
+	content := `{"keys":[{"id":"dev","tenant":"default","secret_hash":"sha256:` + HashSecret("dev-secret") + `"}]}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	authenticator, err := LoadAPIKeyAuthenticator(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := authenticator.Authenticate(context.Background(), "dev-secret"); err != nil {
+		t.Fatalf("Authenticate() error = %v", err)
+	}
+
+	if _, err := LoadAPIKeyAuthenticator(filepath.Join(dir, "missing.json")); err == nil {
+		t.Fatal("LoadAPIKeyAuthenticator() accepted a missing file")
+	}
+}
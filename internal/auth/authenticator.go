@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrMissingCredentials 未提供认证凭据
+	ErrMissingCredentials = errors.New("missing credentials")
+	// ErrInvalidCredentials 认证凭据无效
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Authenticator 认证器
+type Authenticator interface {
+	// Authenticate 校验凭据并返回对应的请求主体
+	Authenticate(ctx context.Context, credential string) (*Principal, error)
+}
+
+// ParseAuthorization 从authorization头中提取凭据
+// 支持 "Bearer <token>"、"ApiKey <key>" 以及直接传递密钥
+func ParseAuthorization(header string) (string, error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return "", ErrMissingCredentials
+	}
+
+	scheme, credential, found := strings.Cut(header, " ")
+	if !found {
+		return header, nil
+	}
+
+	switch strings.ToLower(scheme) {
+	case "bearer", "apikey":
+		credential = strings.TrimSpace(credential)
+		if credential == "" {
+			return "", ErrMissingCredentials
+		}
+		return credential, nil
+	default:
+		return "", ErrInvalidCredentials
+	}
+}
+
+// MultiAuthenticator 按凭据格式分派的认证器：JWT格式交给JWT认证器，其余按API密钥处理
+type MultiAuthenticator struct {
+	APIKey Authenticator
+	JWT    Authenticator
+}
+
+// Authenticate 分派认证
+func (m *MultiAuthenticator) Authenticate(ctx context.Context, credential string) (*Principal, error) {
+	if m.JWT != nil && LooksLikeJWT(credential) {
+		return m.JWT.Authenticate(ctx, credential)
+	}
+	if m.APIKey != nil {
+		return m.APIKey.Authenticate(ctx, credential)
+	}
+	return nil, ErrInvalidCredentials
+}
@@ -0,0 +1,299 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minRefreshInterval 两次刷新之间的最小间隔，避免未知kid导致频繁拉取
+const minRefreshInterval = 30 * time.Second
+
+// JSONWebKey 解析后的JWK公钥
+type JSONWebKey struct {
+	KeyID     string
+	KeyType   string
+	Algorithm string
+	Use       string
+	Key       crypto.PublicKey
+}
+
+// rawJWK JWK的JSON表示
+type rawJWK struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// JWKS 带缓存的JWKS密钥集，支持从本地文件或URL加载
+type JWKS struct {
+	source     string
+	ttl        time.Duration
+	httpClient *http.Client
+
+	mutex       sync.RWMutex
+	keys        []JSONWebKey
+	fetchedAt   time.Time
+	lastAttempt time.Time
+	refreshing  *jwksRefresh // 进行中的刷新，并发的刷新请求共享同一次拉取
+}
+
+// jwksRefresh 一次进行中的刷新
+type jwksRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+// NewJWKS 创建JWKS密钥集，source为文件路径或http(s) URL
+func NewJWKS(source string, ttl time.Duration) *JWKS {
+	return &JWKS{
+		source:     source,
+		ttl:        ttl,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Load 立即加载密钥集
+func (j *JWKS) Load(ctx context.Context) error {
+	return j.refresh(ctx, true)
+}
+
+// Key 按kid查找密钥；缓存过期或kid未知时会重新加载
+func (j *JWKS) Key(ctx context.Context, kid string) ([]JSONWebKey, error) {
+	j.mutex.RLock()
+	expired := j.ttl > 0 && time.Since(j.fetchedAt) > j.ttl
+	keys := j.match(kid)
+	j.mutex.RUnlock()
+
+	if len(keys) > 0 && !expired {
+		return keys, nil
+	}
+
+	// 刷新失败时继续使用旧的密钥集
+	err := j.refresh(ctx, false)
+
+	j.mutex.RLock()
+	keys = j.match(kid)
+	j.mutex.RUnlock()
+
+	if len(keys) == 0 {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("no matching key for kid %q", kid)
+	}
+	return keys, nil
+}
+
+// match 匹配密钥；kid为空时返回全部签名密钥，调用方需持有锁
+func (j *JWKS) match(kid string) []JSONWebKey {
+	var matched []JSONWebKey
+	for _, key := range j.keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		if kid == "" || key.KeyID == kid {
+			matched = append(matched, key)
+		}
+	}
+	return matched
+}
+
+// refresh 重新加载密钥集；拉取在锁外进行，并发调用合并为一次拉取。
+// force为false时距上次尝试不足最小刷新间隔则直接返回
+func (j *JWKS) refresh(ctx context.Context, force bool) error {
+	j.mutex.Lock()
+	call := j.refreshing
+	if call == nil {
+		if !force && time.Since(j.lastAttempt) < minRefreshInterval {
+			j.mutex.Unlock()
+			return nil
+		}
+		call = &jwksRefresh{done: make(chan struct{})}
+		j.refreshing = call
+		j.lastAttempt = time.Now()
+		// 拉取不随发起请求取消，等待的其他请求仍然可以拿到结果；超时由httpClient限制
+		go j.load(context.WithoutCancel(ctx), call)
+	}
+	j.mutex.Unlock()
+
+	select {
+	case <-call.done:
+		return call.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// load 拉取并解析密钥集，在写锁内替换
+func (j *JWKS) load(ctx context.Context, call *jwksRefresh) {
+	keys, err := j.fetchKeys(ctx)
+
+	j.mutex.Lock()
+	if err == nil {
+		j.keys = keys
+		j.fetchedAt = time.Now()
+	}
+	call.err = err
+	j.refreshing = nil
+	j.mutex.Unlock()
+
+	close(call.done)
+}
+
+// fetchKeys 读取并解析JWKS
+func (j *JWKS) fetchKeys(ctx context.Context) ([]JSONWebKey, error) {
+	content, err := j.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(content)
+}
+
+// fetch 读取JWKS原始内容
+func (j *JWKS) fetch(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(j.source, "http://") && !strings.HasPrefix(j.source, "https://") {
+		content, err := os.ReadFile(j.source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jwks file: %w", err)
+		}
+		return content, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.source, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jwks request: %w", err)
+	}
+
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch jwks: status %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jwks response: %w", err)
+	}
+	return content, nil
+}
+
+// ParseJWKS 解析JWKS文档
+func ParseJWKS(content []byte) ([]JSONWebKey, error) {
+	var doc struct {
+		Keys []rawJWK `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse jwks: %w", err)
+	}
+
+	keys := make([]JSONWebKey, 0, len(doc.Keys))
+	for _, raw := range doc.Keys {
+		key, err := raw.publicKey()
+		if err != nil {
+			// 跳过不支持的密钥类型，其余密钥仍可使用
+			continue
+		}
+		keys = append(keys, JSONWebKey{
+			KeyID:     raw.Kid,
+			KeyType:   raw.Kty,
+			Algorithm: raw.Alg,
+			Use:       raw.Use,
+			Key:       key,
+		})
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("jwks contains no usable keys")
+	}
+	return keys, nil
+}
+
+// publicKey 将JWK转换为公钥
+func (r rawJWK) publicKey() (crypto.PublicKey, error) {
+	switch r.Kty {
+	case "RSA":
+		n, err := decodeBigInt(r.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(r.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, fmt.Errorf("invalid rsa exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch r.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", r.Crv)
+		}
+		x, err := decodeBigInt(r.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(r.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if r.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", r.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(r.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", r.Kty)
+	}
+}
+
+// decodeBigInt 解码base64url编码的大整数
+func decodeBigInt(value string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("invalid base64url integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
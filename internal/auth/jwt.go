@@ -0,0 +1,274 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// JWTOptions JWT认证器选项
+type JWTOptions struct {
+	Issuer        string
+	Audiences     []string
+	TenantClaim   string // 租户声明，默认 "tenant"
+	ScopeClaim    string // 权限声明，默认 "scope"（空格分隔字符串或数组）
+	ClientIDClaim string // 客户端ID声明，默认 "azp"，缺失时回退到 "sub"
+	TierClaim     string // 分级声明，默认 "tier"
+	ClockSkew     time.Duration
+}
+
+// JWTAuthenticator 基于JWT（OIDC）的认证器
+type JWTAuthenticator struct {
+	keys    *JWKS
+	options JWTOptions
+}
+
+// NewJWTAuthenticator 创建JWT认证器
+func NewJWTAuthenticator(keys *JWKS, options JWTOptions) *JWTAuthenticator {
+	if options.TenantClaim == "" {
+		options.TenantClaim = "tenant"
+	}
+	if options.ScopeClaim == "" {
+		options.ScopeClaim = "scope"
+	}
+	if options.ClientIDClaim == "" {
+		options.ClientIDClaim = "azp"
+	}
+	if options.TierClaim == "" {
+		options.TierClaim = "tier"
+	}
+	return &JWTAuthenticator{
+		keys:    keys,
+		options: options,
+	}
+}
+
+// jwtHeader JWT头部
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// Authenticate 校验JWT并映射为请求主体
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, credential string) (*Principal, error) {
+	claims, err := a.verify(ctx, credential)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	tenant, _ := claims[a.options.TenantClaim].(string)
+	if tenant == "" {
+		return nil, fmt.Errorf("%w: missing %s claim", ErrInvalidCredentials, a.options.TenantClaim)
+	}
+
+	clientID, _ := claims[a.options.ClientIDClaim].(string)
+	if clientID == "" {
+		clientID, _ = claims["sub"].(string)
+	}
+
+	tier, _ := claims[a.options.TierClaim].(string)
+
+	return &Principal{
+		ClientID: clientID,
+		Tenant:   tenant,
+		Scopes:   claimStrings(claims[a.options.ScopeClaim]),
+		Tier:     tier,
+		Method:   "jwt",
+	}, nil
+}
+
+// verify 校验签名与标准声明，返回全部声明
+func (a *JWTAuthenticator) verify(ctx context.Context, token string) (map[string]interface{}, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature encoding")
+	}
+
+	keys, err := a.keys.Key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, key := range keys {
+		if key.Algorithm != "" && key.Algorithm != header.Alg {
+			continue
+		}
+		if verifySignature(header.Alg, key.Key, signed, signature) == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, fmt.Errorf("signature verification failed")
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("invalid claims: %w", err)
+	}
+
+	if err := a.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// validateClaims 校验iss、aud、exp、nbf
+func (a *JWTAuthenticator) validateClaims(claims map[string]interface{}) error {
+	now := time.Now()
+	skew := a.options.ClockSkew
+
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("missing exp claim")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(skew)) {
+		return fmt.Errorf("token expired")
+	}
+
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(skew).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("token not yet valid")
+	}
+
+	if a.options.Issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != a.options.Issuer {
+			return fmt.Errorf("unexpected issuer %q", iss)
+		}
+	}
+
+	if len(a.options.Audiences) > 0 {
+		audiences := claimStrings(claims["aud"])
+		if !containsAny(audiences, a.options.Audiences) {
+			return fmt.Errorf("unexpected audience %v", audiences)
+		}
+	}
+
+	return nil
+}
+
+// verifySignature 按算法校验签名，不支持"none"
+func verifySignature(alg string, key crypto.PublicKey, signed, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "PS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "PS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512", "PS512", "ES512":
+		hash = crypto.SHA512
+	case "EdDSA":
+		edKey, ok := key.(ed25519.PublicKey)
+		if !ok || !ed25519.Verify(edKey, signed, signature) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported algorithm %q", alg)
+	}
+
+	hasher := hash.New()
+	hasher.Write(signed)
+	digest := hasher.Sum(nil)
+
+	switch alg[:2] {
+	case "RS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type mismatch")
+		}
+		return rsa.VerifyPKCS1v15(rsaKey, hash, digest, signature)
+	case "PS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type mismatch")
+		}
+		return rsa.VerifyPSS(rsaKey, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	default: // ES
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("key type mismatch")
+		}
+		size := (ecKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("invalid signature length")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return fmt.Errorf("invalid signature")
+		}
+		return nil
+	}
+}
+
+// decodeSegment 解码JWT的base64url JSON片段
+func decodeSegment(segment string, v interface{}) error {
+	raw, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, v)
+}
+
+// claimStrings 将字符串（空格分隔）或字符串数组声明转换为切片
+func claimStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// containsAny 检查两个切片是否有交集
+func containsAny(values, candidates []string) bool {
+	for _, v := range values {
+		for _, c := range candidates {
+			if v == c {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// LooksLikeJWT 判断凭据是否为JWT格式
+func LooksLikeJWT(credential string) bool {
+	if strings.Count(credential, ".") != 2 {
+		return false
+	}
+	var header jwtHeader
+	return decodeSegment(credential[:strings.Index(credential, ".")], &header) == nil && header.Alg != ""
+}
@@ -0,0 +1,405 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testKey 测试用签名密钥
+type testKey struct {
+	kid     string
+	alg     string
+	private crypto.Signer
+}
+
+func newRSAKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKey{kid: kid, alg: "RS256", private: key}
+}
+
+func newECKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKey{kid: kid, alg: "ES256", private: key}
+}
+
+func newEdKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testKey{kid: kid, alg: "EdDSA", private: key}
+}
+
+// jwk 返回公钥的JWK表示
+func (k testKey) jwk() map[string]string {
+	encode := base64.RawURLEncoding.EncodeToString
+	jwk := map[string]string{"kid": k.kid, "alg": k.alg, "use": "sig"}
+	switch public := k.private.Public().(type) {
+	case *rsa.PublicKey:
+		jwk["kty"] = "RSA"
+		jwk["n"] = encode(public.N.Bytes())
+		jwk["e"] = encode(big.NewInt(int64(public.E)).Bytes())
+	case *ecdsa.PublicKey:
+		jwk["kty"], jwk["crv"] = "EC", "P-256"
+		jwk["x"] = encode(public.X.FillBytes(make([]byte, 32)))
+		jwk["y"] = encode(public.Y.FillBytes(make([]byte, 32)))
+	case ed25519.PublicKey:
+		jwk["kty"], jwk["crv"] = "OKP", "Ed25519"
+		jwk["x"] = encode(public)
+	}
+	return jwk
+}
+
+// sign 签发JWT
+func (k testKey) sign(t *testing.T, header map[string]string, claims map[string]interface{}) string {
+	t.Helper()
+	if header == nil {
+		header = map[string]string{"alg": k.alg, "kid": k.kid, "typ": "JWT"}
+	}
+	headerJSON, _ := json.Marshal(header)
+	claimsJSON, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+
+	var signature []byte
+	var err error
+	switch key := k.private.(type) {
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		digest := sha256.Sum256([]byte(signed))
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, key, digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case ed25519.PrivateKey:
+		signature = ed25519.Sign(key, []byte(signed))
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// writeJWKS 将公钥写入JWKS文件
+func writeJWKS(t *testing.T, path string, keys ...testKey) {
+	t.Helper()
+	doc := map[string]interface{}{"keys": jwks(keys...)}
+	data, _ := json.Marshal(doc)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func jwks(keys ...testKey) []map[string]string {
+	list := make([]map[string]string, len(keys))
+	for i, key := range keys {
+		list[i] = key.jwk()
+	}
+	return list
+}
+
+// validClaims 返回可以通过校验的声明，overrides中值为nil的声明会被删除
+func validClaims(overrides map[string]interface{}) map[string]interface{} {
+	claims := map[string]interface{}{
+		"iss":    "https://issuer.example.com",
+		"aud":    "sia",
+		"sub":    "user-1",
+		"azp":    "client-1",
+		"tenant": "acme",
+		"scope":  "images:generate tasks:read",
+		"tier":   "pro",
+		"exp":    time.Now().Add(time.Hour).Unix(),
+	}
+	for name, value := range overrides {
+		if value == nil {
+			delete(claims, name)
+			continue
+		}
+		claims[name] = value
+	}
+	return claims
+}
+
+func newTestAuthenticator(t *testing.T, keys ...testKey) (*JWTAuthenticator, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	writeJWKS(t, path, keys...)
+	set := NewJWKS(path, time.Hour)
+	if err := set.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return NewJWTAuthenticator(set, JWTOptions{
+		Issuer:    "https://issuer.example.com",
+		Audiences: []string{"sia", "sia-admin"},
+		ClockSkew: time.Minute,
+	}), path
+}
+
+func TestJWTAuthenticate(t *testing.T) {
+	rsaKey := newRSAKey(t, "rsa-1")
+	ecKey := newECKey(t, "ec-1")
+	edKey := newEdKey(t, "ed-1")
+	unknownKey := newRSAKey(t, "rsa-1") // 与rsaKey的kid相同但不在JWKS中
+	authenticator, _ := newTestAuthenticator(t, rsaKey, ecKey, edKey)
+
+	now := time.Now()
+	tests := []struct {
+		name    string
+		token   string
+		wantErr string
+	}{
+		{name: "rsa", token: rsaKey.sign(t, nil, validClaims(nil))},
+		{name: "ecdsa", token: ecKey.sign(t, nil, validClaims(nil))},
+		{name: "ed25519", token: edKey.sign(t, nil, validClaims(nil))},
+		{name: "audience array", token: rsaKey.sign(t, nil, validClaims(map[string]interface{}{"aud": []string{"other", "sia-admin"}}))},
+		{name: "expired within clock skew", token: rsaKey.sign(t, nil, validClaims(map[string]interface{}{"exp": now.Add(-30 * time.Second).Unix()}))},
+		{name: "not before within clock skew", token: rsaKey.sign(t, nil, validClaims(map[string]interface{}{"nbf": now.Add(30 * time.Second).Unix()}))},
+		{name: "expired", token: rsaKey.sign(t, nil, validClaims(map[string]interface{}{"exp": now.Add(-2 * time.Minute).Unix()})), wantErr: "token expired"},
+		{name: "not yet valid", token: rsaKey.sign(t, nil, validClaims(map[string]interface{}{"nbf": now.Add(2 * time.Minute).Unix()})), wantErr: "token not yet valid"},
+		{name: "missing exp", token: rsaKey.sign(t, nil, validClaims(map[string]interface{}{"exp": nil})), wantErr: "missing exp claim"},
+		{name: "wrong issuer", token: rsaKey.sign(t, nil, validClaims(map[string]interface{}{"iss": "https://evil.example.com"})), wantErr: "unexpected issuer"},
+		{name: "wrong audience", token: rsaKey.sign(t, nil, validClaims(map[string]interface{}{"aud": "other"})), wantErr: "unexpected audience"},
+		{name: "missing tenant", token: rsaKey.sign(t, nil, validClaims(map[string]interface{}{"tenant": nil})), wantErr: "missing tenant claim"},
+		{name: "unknown kid", token: newRSAKey(t, "rsa-2").sign(t, nil, validClaims(nil)), wantErr: `no matching key for kid "rsa-2"`},
+		{name: "signed by another key", token: unknownKey.sign(t, nil, validClaims(nil)), wantErr: "signature verification failed"},
+		{name: "algorithm mismatch", token: rsaKey.sign(t, map[string]string{"alg": "RS384", "kid": "rsa-1"}, validClaims(nil)), wantErr: "signature verification failed"},
+		{name: "alg none", token: rsaKey.sign(t, map[string]string{"alg": "none", "kid": "rsa-1"}, validClaims(nil)), wantErr: "signature verification failed"},
+		{name: "malformed", token: "not.a-jwt", wantErr: "malformed token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := authenticator.Authenticate(context.Background(), tt.token)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Authenticate() error = %v, want %q", err, tt.wantErr)
+				}
+				if !errors.Is(err, ErrInvalidCredentials) {
+					t.Errorf("Authenticate() error = %v, want ErrInvalidCredentials", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() error = %v", err)
+			}
+			if principal.Tenant != "acme" || principal.ClientID != "client-1" || principal.Tier != "pro" || principal.Method != "jwt" {
+				t.Errorf("Authenticate() = %+v", principal)
+			}
+			if !principal.HasScope("images:generate") || !principal.HasScope("tasks:read") {
+				t.Errorf("Authenticate() scopes = %v", principal.Scopes)
+			}
+		})
+	}
+}
+
+func TestJWTClientIDFallsBackToSubject(t *testing.T) {
+	key := newRSAKey(t, "rsa-1")
+	authenticator, _ := newTestAuthenticator(t, key)
+
+	principal, err := authenticator.Authenticate(context.Background(), key.sign(t, nil, validClaims(map[string]interface{}{"azp": nil})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if principal.ClientID != "user-1" {
+		t.Errorf("ClientID = %q, want sub", principal.ClientID)
+	}
+}
+
+func TestJWKSKeyRotation(t *testing.T) {
+	oldKey := newRSAKey(t, "2024-01")
+	newKey := newECKey(t, "2024-02")
+	authenticator, path := newTestAuthenticator(t, oldKey)
+	ctx := context.Background()
+
+	if _, err := authenticator.Authenticate(ctx, oldKey.sign(t, nil, validClaims(nil))); err != nil {
+		t.Fatalf("old key before rotation: %v", err)
+	}
+
+	// 发布新密钥；刚刷新过时未知kid不会立即触发重新加载
+	writeJWKS(t, path, oldKey, newKey)
+	token := newKey.sign(t, nil, validClaims(nil))
+	if _, err := authenticator.Authenticate(ctx, token); err == nil {
+		t.Fatal("new kid accepted within the minimum refresh interval")
+	}
+
+	// 超过最小刷新间隔后未知kid触发重新加载
+	authenticator.keys.lastAttempt = time.Now().Add(-minRefreshInterval)
+	if _, err := authenticator.Authenticate(ctx, token); err != nil {
+		t.Fatalf("new key after rotation: %v", err)
+	}
+
+	// 撤下旧密钥，缓存过期后旧密钥签发的令牌被拒绝
+	writeJWKS(t, path, newKey)
+	authenticator.keys.fetchedAt = time.Now().Add(-2 * time.Hour)
+	authenticator.keys.lastAttempt = time.Now().Add(-minRefreshInterval)
+	if _, err := authenticator.Authenticate(ctx, oldKey.sign(t, nil, validClaims(nil))); err == nil {
+		t.Fatal("retired key still accepted after the cache expired")
+	}
+	if _, err := authenticator.Authenticate(ctx, token); err != nil {
+		t.Fatalf("new key after retiring the old one: %v", err)
+	}
+}
+
+func TestJWKSKeepsKeysWhenRefreshFails(t *testing.T) {
+	key := newRSAKey(t, "rsa-1")
+	var fail atomic.Bool
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if fail.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": jwks(key)})
+	}))
+	defer server.Close()
+
+	set := NewJWKS(server.URL, time.Minute)
+	if _, err := set.Key(context.Background(), "rsa-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 缓存过期后刷新失败，继续使用旧的密钥集
+	fail.Store(true)
+	set.fetchedAt = time.Now().Add(-2 * time.Minute)
+	set.lastAttempt = time.Now().Add(-minRefreshInterval)
+	if _, err := set.Key(context.Background(), "rsa-1"); err != nil {
+		t.Fatalf("Key() after failed refresh: %v", err)
+	}
+	if got := fetches.Load(); got != 2 {
+		t.Errorf("fetches = %d, want 2", got)
+	}
+
+	// 最小刷新间隔内不再重复拉取
+	if _, err := set.Key(context.Background(), "unknown"); err == nil {
+		t.Fatal("unknown kid accepted")
+	}
+	if got := fetches.Load(); got != 2 {
+		t.Errorf("fetches = %d, want 2 within the minimum refresh interval", got)
+	}
+}
+
+func TestJWKSRefreshOutsideLock(t *testing.T) {
+	key := newRSAKey(t, "rsa-1")
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 第一次拉取立即返回，之后的拉取阻塞到测试放行
+		if fetches.Add(1) > 1 {
+			<-release
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": jwks(key)})
+	}))
+	defer server.Close()
+
+	set := NewJWKS(server.URL, time.Hour)
+	if err := set.Load(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 未知kid触发的刷新阻塞时，并发请求合并为一次拉取
+	set.mutex.Lock()
+	set.lastAttempt = time.Now().Add(-minRefreshInterval)
+	set.mutex.Unlock()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < cap(errs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := set.Key(context.Background(), "unknown")
+			errs <- err
+		}()
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for fetches.Load() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("refresh was not started")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// 拉取进行中时已缓存的密钥可以立即取到
+	done := make(chan error, 1)
+	go func() {
+		_, err := set.Key(context.Background(), "rsa-1")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Key() during refresh: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Key() blocked behind an in-flight refresh")
+	}
+
+	// 等待方的上下文取消时不再等待拉取
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	set.mutex.Lock()
+	set.lastAttempt = time.Now().Add(-minRefreshInterval)
+	set.mutex.Unlock()
+	if _, err := set.Key(ctx, "other"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Key() with a cancelled context = %v, want context.Canceled", err)
+	}
+
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err == nil {
+			t.Fatal("unknown kid accepted")
+		}
+	}
+	if got := fetches.Load(); got != 2 {
+		t.Errorf("fetches = %d, want 2", got)
+	}
+}
+
+func TestLooksLikeJWT(t *testing.T) {
+	key := newEdKey(t, "ed-1")
+	tests := []struct {
+		credential string
+		want       bool
+	}{
+		{key.sign(t, nil, validClaims(nil)), true},
+		{"sia_0123456789abcdef", false},
+		{"a.b.c", false},
+	}
+	for _, tt := range tests {
+		if got := LooksLikeJWT(tt.credential); got != tt.want {
+			t.Errorf("LooksLikeJWT(%.20q) = %v, want %v", tt.credential, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package auth
+
+import "context"
+
+// 权限范围
+const (
+	ScopeGenerate  = "images:generate" // 同步生成图片（含序列图片）
+	ScopeAsync     = "images:async"    // 创建异步生成任务
+	ScopeTasksRead = "tasks:read"      // 查询任务状态
+	ScopeUsageRead = "usage:read"      // 查询本租户用量
+	ScopeAdmin     = "admin"           // 管理类接口，隐含全部权限
+)
+
+// Principal 请求主体（调用方身份）
+type Principal struct {
+	ClientID string   `json:"client_id"`
+	Tenant   string   `json:"tenant"`
+	Scopes   []string `json:"scopes"`
+	Tier     string   `json:"tier,omitempty"` // 限流/配额分级，为空时使用默认分级
+	Method   string   `json:"method"`         // 认证方式：api_key、jwt
+	KeyID    string   `json:"key_id,omitempty"`
+}
+
+// HasScope 检查主体是否拥有指定权限
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// NewContext 将主体附加到上下文
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext 从上下文中获取主体
+func FromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
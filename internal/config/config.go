@@ -1,162 +1,1045 @@
 package config
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
-	"strconv"
+	"reflect"
+	"regexp"
+	"sort"
 	"strings"
+
+	"sia/internal/models"
 )
 
 // Config 应用配置
+// 带reload:"hot"标签的配置项（包括其子项）可以热加载，其余的修改需要重启；带secret:"true"标签的值不会出现在日志中
 type Config struct {
-	App    AppConfig    `json:"app"`
-	Server ServerConfig `json:"server"`
-	Image  ImageConfig  `json:"image"`
-	Log    LogConfig    `json:"log"`
+	App       AppConfig       `json:"app"`
+	Server    ServerConfig    `json:"server"`
+	Image     ImageConfig     `json:"image"`
+	Models    ModelsConfig    `json:"models"`
+	Log       LogConfig       `json:"log"`
+	Auth      AuthConfig      `json:"auth"`
+	RateLimit RateLimitConfig `json:"rate_limit"`
+	Usage     UsageConfig     `json:"usage"`
+	Tracing   TracingConfig   `json:"tracing"`
+	Health    HealthConfig    `json:"health"`
+	Storage   StorageConfig   `json:"storage"`
+	Cache     CacheConfig     `json:"cache"`
+	Secrets   SecretsConfig   `json:"secrets"`
+
+	file string // 配置文件路径，热加载时重新读取
 }
 
 // AppConfig 应用配置
 type AppConfig struct {
-	Name        string `json:"name"`
-	Version     string `json:"version"`
-	Environment string `json:"environment"`
+	Name        string `json:"name" env:"APP_NAME"`
+	Version     string `json:"version" env:"APP_VERSION"`
+	Environment string `json:"environment" env:"APP_ENVIRONMENT"`
+	WatchConfig bool   `json:"watch_config" env:"CONFIG_WATCH"` // 监听配置文件变化并热加载（SIGHUP始终有效）
 }
 
 // ServerConfig 服务器配置
 type ServerConfig struct {
-	GRPCPort int `json:"grpc_port"`
-	HTTPPort int `json:"http_port"`
+	GRPCPort       int  `json:"grpc_port" env:"GRPC_PORT"`
+	HTTPPort       int  `json:"http_port" env:"HTTP_PORT"`
+	GatewayEnabled bool `json:"gateway_enabled" env:"HTTP_GATEWAY_ENABLED"` // 在HTTP端口上提供REST/JSON网关
 }
 
 // ImageConfig 图片生成配置
 type ImageConfig struct {
-	APIKey      string `json:"api_key"`
-	BaseURL     string `json:"base_url"`
-	Model       string `json:"model"`
-	DefaultSize string `json:"default_size"`
-	Timeout     int    `json:"timeout"`
-	MaxRetries  int    `json:"max_retries"`
+	APIKey      string `json:"api_key" env:"IMAGE_API_KEY" reload:"hot" secret:"true"`
+	BaseURL     string `json:"base_url" env:"IMAGE_BASE_URL"`
+	Model       string `json:"model" env:"IMAGE_MODEL" reload:"hot"`
+	DefaultSize string `json:"default_size" env:"IMAGE_DEFAULT_SIZE" reload:"hot"`
+	Timeout     int    `json:"timeout" env:"IMAGE_TIMEOUT"`
+	MaxRetries  int    `json:"max_retries" env:"IMAGE_MAX_RETRIES"`
+
+	BreakerFailures int `json:"breaker_failures" env:"IMAGE_BREAKER_FAILURES"` // 连续失败多少次后熔断，0表示不熔断
+	BreakerCooldown int `json:"breaker_cooldown" env:"IMAGE_BREAKER_COOLDOWN"` // 熔断后多久允许试探请求（秒）
+
+	Coalesce bool `json:"coalesce" env:"IMAGE_COALESCE_REQUESTS" reload:"hot"` // 合并同时进行的相同请求，共享一次上游调用
+
+	APIKeys              []UpstreamKeyConfig `json:"api_keys" reload:"hot" secret:"true"`                                      // 上游API Key池，与api_key（ID为default）一起分担请求
+	KeySelection         string              `json:"key_selection" env:"IMAGE_KEY_SELECTION" reload:"hot"`                     // round_robin, least_used
+	KeyRateLimitCooldown int                 `json:"key_rate_limit_cooldown" env:"IMAGE_KEY_RATE_LIMIT_COOLDOWN" reload:"hot"` // 429后暂停密钥的时间（秒），上游返回Retry-After时以其为准
+	KeyAuthCooldown      int                 `json:"key_auth_cooldown" env:"IMAGE_KEY_AUTH_COOLDOWN" reload:"hot"`             // 401/403后暂停密钥的时间（秒）
+}
+
+// UpstreamKeyConfig 上游API Key池中的密钥
+type UpstreamKeyConfig struct {
+	ID  string `json:"id"`
+	Key string `json:"key"`
+}
+
+// defaultUpstreamKeyID api_key在密钥池中的ID
+const defaultUpstreamKeyID = "default"
+
+// UpstreamKeys 返回完整的上游API Key池：api_key（如果配置）在前，其后是api_keys
+func (c ImageConfig) UpstreamKeys() []UpstreamKeyConfig {
+	var keys []UpstreamKeyConfig
+	if c.APIKey != "" {
+		keys = append(keys, UpstreamKeyConfig{ID: defaultUpstreamKeyID, Key: c.APIKey})
+	}
+	return append(keys, c.APIKeys...)
+}
+
+// ModelsConfig 模型注册表配置，请求的模型、尺寸和图片数量按注册表中的能力验证
+type ModelsConfig struct {
+	File      string                      `json:"file" env:"MODELS_FILE" reload:"hot"`                  // 模型注册表文件（JSON）
+	StickyKey string                      `json:"sticky_key" env:"MODEL_ALIAS_STICKY_KEY" reload:"hot"` // 别名默认的粘性键：请求metadata中该键的值相同时分配到同一个模型
+	Models    map[string]ModelConfig      `json:"models" reload:"hot"`
+	Aliases   map[string]ModelAliasConfig `json:"aliases" reload:"hot"` // 模型别名，如default、fast、hq
+
+	FailoverOn []string                  `json:"failover_on" env:"MODEL_FAILOVER_ON" reload:"hot"` // 别名默认触发故障转移的错误类型
+	Providers  map[string]ProviderConfig `json:"providers"`                                        // 备用上游服务，模型的provider可以引用其名称；新增或删除上游服务需要重启
+}
+
+// ProviderConfig 备用上游服务：与方舟API兼容的另一个端点（如其他地域或账号）
+// 超时、熔断和API Key分配策略沿用image中的配置
+type ProviderConfig struct {
+	BaseURL string              `json:"base_url"`
+	APIKeys []UpstreamKeyConfig `json:"api_keys" secret:"true" reload:"hot"` // 也可以通过PROVIDER_<NAME>_API_KEYS提供
+}
+
+// ModelConfig 单个模型的能力与价格
+type ModelConfig struct {
+	Provider           string           `json:"provider"`             // 上游服务：ark或models.providers中的备用上游服务名称
+	Sizes              []string         `json:"sizes"`                // 支持的尺寸，如1K、2K、2048x2048
+	AspectRatios       []string         `json:"aspect_ratios"`        // WIDTHxHEIGHT形式的尺寸允许的宽高比，如16:9
+	MaxReferenceImages int              `json:"max_reference_images"` // 最多参考图片数量，0表示不支持参考图片
+	Sequential         bool             `json:"sequential"`           // 是否支持序列图片
+	MaxImages          int              `json:"max_images"`           // 单次请求最多生成的图片数量
+	Pricing            ModelPriceConfig `json:"pricing"`              // 价格，usage.pricing中为同一模型配置的价格优先
+}
+
+// ModelAliasConfig 模型别名，按权重把请求分配到具体模型
+type ModelAliasConfig struct {
+	Targets    []AliasTargetConfig `json:"targets"`
+	StickyKey  string              `json:"sticky_key"`  // 为空时使用models.sticky_key
+	Fallbacks  []FallbackConfig    `json:"fallbacks"`   // 分配到的模型失败时依次尝试的备用模型
+	FailoverOn []string            `json:"failover_on"` // 触发故障转移的错误类型，为空时使用models.failover_on
+}
+
+// FallbackConfig 故障转移的备用模型
+type FallbackConfig struct {
+	Model    string `json:"model"`
+	Provider string `json:"provider"` // 为空时使用模型自身的上游服务
+}
+
+// AliasTargetConfig 别名指向的具体模型及其权重
+type AliasTargetConfig struct {
+	Model  string `json:"model"`
+	Weight int    `json:"weight"`
+}
+
+// Registry 根据配置创建模型注册表
+func (c ModelsConfig) Registry() *models.Registry {
+	list := make([]models.Model, 0, len(c.Models))
+	for name, model := range c.Models {
+		list = append(list, models.Model{
+			Name:               name,
+			Provider:           model.Provider,
+			Sizes:              model.Sizes,
+			AspectRatios:       model.AspectRatios,
+			MaxReferenceImages: model.MaxReferenceImages,
+			Sequential:         model.Sequential,
+			MaxImages:          model.MaxImages,
+		})
+	}
+
+	aliases := make([]models.Alias, 0, len(c.Aliases))
+	for name, alias := range c.Aliases {
+		stickyKey := alias.StickyKey
+		if stickyKey == "" {
+			stickyKey = c.StickyKey
+		}
+		failoverOn := alias.FailoverOn
+		if len(failoverOn) == 0 {
+			failoverOn = c.FailoverOn
+		}
+		targets := make([]models.AliasTarget, len(alias.Targets))
+		for i, target := range alias.Targets {
+			targets[i] = models.AliasTarget(target)
+		}
+		fallbacks := make([]models.Fallback, len(alias.Fallbacks))
+		for i, fallback := range alias.Fallbacks {
+			fallbacks[i] = models.Fallback(fallback)
+		}
+		aliases = append(aliases, models.Alias{
+			Name:       name,
+			Targets:    targets,
+			StickyKey:  stickyKey,
+			Fallbacks:  fallbacks,
+			FailoverOn: failoverOn,
+		})
+	}
+	return models.NewRegistry(list, aliases)
 }
 
 // LogConfig 日志配置
 type LogConfig struct {
-	Level  string `json:"level"`
-	Format string `json:"format"` // json, text
+	Level      string `json:"level" env:"LOG_LEVEL" reload:"hot"`
+	Format     string `json:"format" env:"LOG_FORMAT"` // json, text
+	Output     string `json:"output" env:"LOG_OUTPUT"` // stdout, stderr, file
+	File       string `json:"file" env:"LOG_FILE"`
+	MaxSize    int    `json:"max_size" env:"LOG_MAX_SIZE"`       // 单个日志文件的最大大小（MB）
+	MaxAge     int    `json:"max_age" env:"LOG_MAX_AGE"`         // 轮转文件的保留天数，0表示不按时间清理
+	MaxBackups int    `json:"max_backups" env:"LOG_MAX_BACKUPS"` // 轮转文件的最大保留数量，0表示不限制
+	Compress   bool   `json:"compress" env:"LOG_COMPRESS"`
+
+	Redaction RedactionConfig `json:"redaction"`
+}
+
+// RedactionConfig 日志脱敏配置
+type RedactionConfig struct {
+	Enabled      bool               `json:"enabled" env:"LOG_REDACT"`
+	File         string             `json:"file" env:"LOG_REDACT_FILE"`                   // 脱敏规则文件（JSON）
+	Prompts      string             `json:"prompts" env:"LOG_REDACT_PROMPTS"`             // hash, truncate, off
+	PromptLength int                `json:"prompt_length" env:"LOG_REDACT_PROMPT_LENGTH"` // 截断时保留的字符数
+	PromptKeys   []string           `json:"prompt_keys"`                                  // 额外视为提示词的字段
+	SecretKeys   []string           `json:"secret_keys"`                                  // 额外视为密钥的字段
+	MaskURLQuery bool               `json:"mask_url_query" env:"LOG_REDACT_URL_QUERY"`
+	Patterns     []RedactionPattern `json:"patterns"`
+}
+
+// RedactionPattern 自定义脱敏规则
+type RedactionPattern struct {
+	Pattern     string `json:"pattern"`
+	Replacement string `json:"replacement"` // 为空时替换为[REDACTED]
+}
+
+// TracingConfig 链路追踪配置
+type TracingConfig struct {
+	Enabled       bool    `json:"enabled" env:"TRACING_ENABLED"`
+	Endpoint      string  `json:"endpoint" env:"TRACING_OTLP_ENDPOINT"` // OTLP gRPC地址（host:port）
+	Insecure      bool    `json:"insecure" env:"TRACING_OTLP_INSECURE"`
+	SampleRatio   float64 `json:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+	ExportTimeout int     `json:"export_timeout" env:"TRACING_EXPORT_TIMEOUT"` // 秒
+}
+
+// HealthConfig 就绪检查配置
+type HealthConfig struct {
+	ProbeInterval int    `json:"probe_interval" env:"HEALTH_PROBE_INTERVAL"`   // 上游探测结果的缓存时间（秒）
+	ProbeTimeout  int    `json:"probe_timeout" env:"HEALTH_PROBE_TIMEOUT"`     // 上游探测超时（秒）
+	ProbePath     string `json:"probe_path" env:"HEALTH_PROBE_PATH"`           // 上游探测路径
+	MaxQueueDepth int    `json:"max_queue_depth" env:"HEALTH_MAX_QUEUE_DEPTH"` // 等待中的异步任务超过该值时视为饱和，0表示不检查
+}
+
+// CacheConfig 生成结果缓存配置
+type CacheConfig struct {
+	Enabled    bool `json:"enabled" env:"RESULT_CACHE_ENABLED"`
+	TTL        int  `json:"ttl" env:"RESULT_CACHE_TTL"`                 // 缓存结果的有效期（秒）
+	MaxEntries int  `json:"max_entries" env:"RESULT_CACHE_MAX_ENTRIES"` // 最多缓存的结果数，超出时淘汰最久未使用的结果
+}
+
+// StorageConfig 图片持久化配置
+type StorageConfig struct {
+	Backend         string          `json:"backend" env:"STORAGE_BACKEND"`                   // none, local, s3
+	PublicURL       string          `json:"public_url" env:"STORAGE_PUBLIC_URL"`             // 稳定URL的前缀，为空时使用存储自身的地址
+	MaxImageSize    int             `json:"max_image_size" env:"STORAGE_MAX_IMAGE_SIZE"`     // 单张图片的最大大小（MB）
+	DownloadTimeout int             `json:"download_timeout" env:"STORAGE_DOWNLOAD_TIMEOUT"` // 下载上游图片的超时（秒）
+	LocalDir        string          `json:"local_dir" env:"STORAGE_LOCAL_DIR"`               // 本地存储目录
+	S3              S3StorageConfig `json:"s3"`
+	File            string          `json:"file" env:"STORAGE_FILE"` // 存储配置文件（JSON）
+
+	SigningKeys  []SigningKeyConfig `json:"signing_keys" secret:"true" reload:"hot"`                  // 图片地址签名密钥，第一个用于签名，其余只用于验证
+	SignedURLTTL int                `json:"signed_url_ttl" env:"STORAGE_SIGNED_URL_TTL" reload:"hot"` // 签名地址的有效期（秒）
+	ServeBaseURL string             `json:"serve_base_url" env:"STORAGE_SERVE_BASE_URL" reload:"hot"` // 签名地址的前缀（本服务HTTP端口的外部地址），为空时为相对地址
+	ResizeWidths []int              `json:"resize_widths" env:"STORAGE_RESIZE_WIDTHS"`                // 允许缩放到的宽度
+
+	Retention RetentionConfig `json:"retention"`
+}
+
+// RetentionConfig 图片保留策略与后台GC配置
+type RetentionConfig struct {
+	Enabled   bool                    `json:"enabled" env:"STORAGE_RETENTION_ENABLED"`       // 是否启用后台GC
+	Interval  int                     `json:"interval" env:"STORAGE_RETENTION_INTERVAL"`     // 后台GC的执行间隔（秒）
+	Grace     int                     `json:"grace" env:"STORAGE_RETENTION_GRACE"`           // 新写入的图片在该时间内不会被回收（秒）
+	DryRun    bool                    `json:"dry_run" env:"STORAGE_RETENTION_DRY_RUN"`       // 后台GC只记录将被回收的图片，不实际删除
+	AuditFile string                  `json:"audit_file" env:"STORAGE_RETENTION_AUDIT_FILE"` // 审计日志文件（JSONL），为空时不写审计记录
+	KeepDays  int                     `json:"keep_days" env:"STORAGE_RETENTION_DAYS"`        // 默认策略的保留天数，0表示永久保留
+	TaskTTL   int                     `json:"task_ttl" env:"STORAGE_RETENTION_TASK_TTL"`     // 异步任务的图片在写入后多长时间内视为被存活任务引用（秒）
+	Policies  []RetentionPolicyConfig `json:"policies"`                                      // 按顺序匹配，第一个匹配的策略生效
+	Archive   ArchiveConfig           `json:"archive"`
+}
+
+// RetentionPolicyConfig 保留策略
+type RetentionPolicyConfig struct {
+	Name          string            `json:"name"`
+	Tenant        string            `json:"tenant"`          // 为空时匹配所有租户
+	Tags          map[string]string `json:"tags"`            // 请求metadata，全部相等才匹配
+	KeepDays      int               `json:"keep_days"`       // 0表示不按时间过期
+	LiveTasksOnly bool              `json:"live_tasks_only"` // 异步任务的图片超过task_ttl后过期，不属于任务的图片只按keep_days过期
+	Action        string            `json:"action"`          // delete, archive
+}
+
+// ArchiveConfig 归档（冷存储）配置
+type ArchiveConfig struct {
+	Backend  string          `json:"backend" env:"STORAGE_ARCHIVE_BACKEND"` // none, local, s3
+	LocalDir string          `json:"local_dir" env:"STORAGE_ARCHIVE_LOCAL_DIR"`
+	S3       S3StorageConfig `json:"s3" env:"-"`
+}
+
+// SigningKeyConfig 图片地址签名密钥
+type SigningKeyConfig struct {
+	ID     string `json:"id"`
+	Secret string `json:"secret"`
+}
+
+// S3StorageConfig S3兼容存储配置（AWS S3、MinIO等）
+type S3StorageConfig struct {
+	Endpoint  string `json:"endpoint" env:"STORAGE_S3_ENDPOINT"`
+	Region    string `json:"region" env:"STORAGE_S3_REGION"`
+	Bucket    string `json:"bucket" env:"STORAGE_S3_BUCKET"`
+	AccessKey string `json:"access_key" env:"STORAGE_S3_ACCESS_KEY" secret:"true"`
+	SecretKey string `json:"secret_key" env:"STORAGE_S3_SECRET_KEY" secret:"true"`
+	PathStyle bool   `json:"path_style" env:"STORAGE_S3_PATH_STYLE"` // MinIO需要使用路径风格的地址
+}
+
+// SecretsConfig 密钥来源配置
+// 带secret标签的配置项（如IMAGE_API_KEY）依次从环境变量、NAME_FILE指向的文件和加密密钥文件读取
+type SecretsConfig struct {
+	File            string `json:"file" env:"SECRETS_FILE"`                         // AES-256-GCM加密的本地密钥文件，为空时不使用
+	Key             string `json:"key" env:"SECRETS_KEY" secret:"true"`             // 加密密钥（base64编码的32字节），建议通过SECRETS_KEY_FILE提供
+	RefreshInterval int    `json:"refresh_interval" env:"SECRETS_REFRESH_INTERVAL"` // 定期重新读取密钥的间隔（秒），0表示不刷新
+}
+
+// AuthConfig 认证配置
+type AuthConfig struct {
+	Enabled     bool      `json:"enabled" env:"AUTH_ENABLED"`
+	APIKeysFile string    `json:"api_keys_file" env:"AUTH_API_KEYS_FILE" reload:"hot"` // API密钥文件路径（JSON，密钥以哈希形式保存）
+	JWT         JWTConfig `json:"jwt"`
+}
+
+// JWTConfig JWT（OIDC）认证配置
+type JWTConfig struct {
+	Enabled       bool     `json:"enabled" env:"AUTH_JWT_ENABLED"`
+	JWKSFile      string   `json:"jwks_file" env:"AUTH_JWT_JWKS_FILE"`
+	JWKSURL       string   `json:"jwks_url" env:"AUTH_JWT_JWKS_URL"`
+	JWKSCacheTTL  int      `json:"jwks_cache_ttl" env:"AUTH_JWT_JWKS_CACHE_TTL"` // 秒
+	Issuer        string   `json:"issuer" env:"AUTH_JWT_ISSUER"`
+	Audiences     []string `json:"audiences" env:"AUTH_JWT_AUDIENCES"`
+	TenantClaim   string   `json:"tenant_claim" env:"AUTH_JWT_TENANT_CLAIM"`
+	ScopeClaim    string   `json:"scope_claim" env:"AUTH_JWT_SCOPE_CLAIM"`
+	ClientIDClaim string   `json:"client_id_claim" env:"AUTH_JWT_CLIENT_ID_CLAIM"`
+	ClockSkew     int      `json:"clock_skew" env:"AUTH_JWT_CLOCK_SKEW"` // 秒
+	TierClaim     string   `json:"tier_claim" env:"AUTH_JWT_TIER_CLAIM"`
+}
+
+// RateLimitConfig 限流配置
+type RateLimitConfig struct {
+	Enabled     bool                        `json:"enabled" env:"RATE_LIMIT_ENABLED"`
+	KeyBy       string                      `json:"key_by" env:"RATE_LIMIT_KEY_BY" reload:"hot"` // tenant, client
+	DefaultTier string                      `json:"default_tier" env:"RATE_LIMIT_DEFAULT_TIER" reload:"hot"`
+	File        string                      `json:"file" env:"RATE_LIMIT_FILE" reload:"hot"` // 分级与模型限流配置文件（JSON）
+	Tiers       map[string]TierLimitConfig  `json:"tiers" reload:"hot"`
+	Models      map[string]ModelLimitConfig `json:"models" reload:"hot"`
+}
+
+// TierLimitConfig 单个分级的限流配置，0表示不限制
+type TierLimitConfig struct {
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	Burst             int     `json:"burst"`
+	MaxConcurrent     int     `json:"max_concurrent"`
+}
+
+// ModelLimitConfig 单个模型的全局限流配置，0表示不限制
+type ModelLimitConfig struct {
+	RequestsPerSecond float64 `json:"requests_per_second"`
+	Burst             int     `json:"burst"`
+	MaxConcurrent     int     `json:"max_concurrent"`
+}
+
+// UsageConfig 用量计量配置
+type UsageConfig struct {
+	LedgerFile string        `json:"ledger_file" env:"USAGE_LEDGER_FILE"` // 用量台账文件（JSONL），为空时只保存在内存中
+	Quota      QuotaConfig   `json:"quota" reload:"hot"`
+	Pricing    PricingConfig `json:"pricing" reload:"hot"`
+	Budget     BudgetConfig  `json:"budget" reload:"hot"`
+}
+
+// QuotaConfig 配额配置
+type QuotaConfig struct {
+	Enabled bool                        `json:"enabled" env:"QUOTA_ENABLED"`
+	File    string                      `json:"file" env:"QUOTA_FILE"` // 分级与租户配额配置文件（JSON）
+	Tiers   map[string]QuotaLimitConfig `json:"tiers"`
+	Tenants map[string]QuotaLimitConfig `json:"tenants"` // 租户级覆盖
+}
+
+// QuotaLimitConfig 配额上限，0表示不限制
+type QuotaLimitConfig struct {
+	DailyImages   int64 `json:"daily_images"`
+	MonthlyImages int64 `json:"monthly_images"`
+	DailyTokens   int64 `json:"daily_tokens"`
+	MonthlyTokens int64 `json:"monthly_tokens"`
+}
+
+// PricingConfig 价格配置
+type PricingConfig struct {
+	Currency string                      `json:"currency" env:"PRICING_CURRENCY"`
+	File     string                      `json:"file" env:"PRICING_FILE"` // 模型价格表文件（JSON）
+	Models   map[string]ModelPriceConfig `json:"models"`
+}
+
+// ModelPriceConfig 单个模型的价格
+type ModelPriceConfig struct {
+	PerImage float64            `json:"per_image"`
+	Sizes    map[string]float64 `json:"sizes"` // 按尺寸覆盖的单图价格
+}
+
+// BudgetConfig 预算配置
+type BudgetConfig struct {
+	Enabled        bool                         `json:"enabled" env:"BUDGET_ENABLED"`
+	Action         string                       `json:"action" env:"BUDGET_ACTION"` // reject, downgrade
+	DowngradeSizes []string                     `json:"downgrade_sizes" env:"BUDGET_DOWNGRADE_SIZES"`
+	File           string                       `json:"file" env:"BUDGET_FILE"` // 分级与租户预算配置文件（JSON）
+	Tiers          map[string]BudgetLimitConfig `json:"tiers"`
+	Tenants        map[string]BudgetLimitConfig `json:"tenants"` // 租户级覆盖
+}
+
+// BudgetLimitConfig 费用上限，0表示不限制
+type BudgetLimitConfig struct {
+	DailySpend   float64 `json:"daily_spend"`
+	MonthlySpend float64 `json:"monthly_spend"`
 }
 
 // Load 加载配置
-func Load() (*Config, error) {
-	// 加载 .env 文件（如果存在）
-	loadEnvFile(".env")
+// 优先级从低到高：默认值 < 配置文件 < .env < 环境变量 < 命令行参数
+// 配置文件通过--config参数或SIA_CONFIG环境变量指定（YAML或JSON），--set可以覆盖任意配置项；
+// 各*_FILE指定的JSON文件在环境变量之后合并到对应的配置段。出错时返回全部错误而不是第一个
+func Load(args []string) (*Config, error) {
+	flags := flag.NewFlagSet("sia", flag.ContinueOnError)
+	configFile := flags.String("config", "", "配置文件（YAML或JSON），默认使用SIA_CONFIG")
+	var overrides setFlags
+	flags.Var(&overrides, "set", "覆盖配置项，格式为section.key=value（如server.grpc_port=8081），可以重复")
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	// .env中的变量只在环境变量未设置时生效
+	errs := loadEnvFile(".env")
+
+	config := defaultConfig()
+
+	if *configFile == "" {
+		*configFile = os.Getenv("SIA_CONFIG")
+	}
+	var document map[string]interface{}
+	if *configFile != "" {
+		var err error
+		config.file = *configFile
+		if document, err = loadConfigFile(*configFile, config); err != nil {
+			errs = append(errs, fmt.Errorf("config file %s: %w", *configFile, err))
+		}
+	}
+
+	// *_FILE文件属于文件层：在配置文件之后合并，之后的.env、环境变量和--set可以覆盖其中的配置项
+	sideFiles := []struct {
+		name   string
+		path   string // --set使用的配置项路径
+		file   *string
+		target interface{}
+	}{
+		{"LOG_REDACT_FILE", "log.redaction.file", &config.Log.Redaction.File, &config.Log.Redaction},
+		{"MODELS_FILE", "models.file", &config.Models.File, &config.Models},
+		{"STORAGE_FILE", "storage.file", &config.Storage.File, &config.Storage},
+		{"RATE_LIMIT_FILE", "rate_limit.file", &config.RateLimit.File, &config.RateLimit},
+		{"QUOTA_FILE", "usage.quota.file", &config.Usage.Quota.File, &config.Usage.Quota},
+		{"PRICING_FILE", "usage.pricing.file", &config.Usage.Pricing.File, &config.Usage.Pricing},
+		{"BUDGET_FILE", "usage.budget.file", &config.Usage.Budget.File, &config.Usage.Budget},
+	}
+	for _, side := range sideFiles {
+		// 文件路径本身按完整的优先级确定
+		file := *side.file
+		if value := os.Getenv(side.name); value != "" {
+			file = value
+		}
+		for _, override := range overrides {
+			if path, value, _ := strings.Cut(override, "="); path == side.path {
+				file = value
+			}
+		}
+		if file == "" {
+			continue
+		}
+		if err := loadJSONFile(file, side.target); err != nil {
+			errs = append(errs, fmt.Errorf("failed to load %s: %w", side.name, err))
+		}
+	}
+
+	applyEnv(reflect.ValueOf(config).Elem(), &errs)
+	config.applyEnvOverrides(document, &errs)
+
+	// 密钥在--set之前解析，覆盖配置文件和环境变量中的值，命令行显式设置的值优先；
+	// 密钥来源（secrets.*）本身先按完整的优先级确定
+	config.resolveSecretsKey(&errs)
+	isSecretsPath := func(path string) bool { return strings.HasPrefix(path, "secrets.") }
+	config.applyOverrides(overrides, isSecretsPath, &errs)
+	config.resolveSecrets(&errs)
+	config.resolveProviderKeys(&errs)
+	config.applyOverrides(overrides, func(path string) bool { return !isSecretsPath(path) }, &errs)
 
-	config := &Config{
+	// 验证必需的配置
+	errs = append(errs, config.validate()...)
+	if len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+
+	return config, nil
+}
+
+// defaultConfig 返回默认配置
+func defaultConfig() *Config {
+	return &Config{
 		App: AppConfig{
-			Name:        getEnvString("APP_NAME", "sia-image-service"),
-			Version:     getEnvString("APP_VERSION", "1.0.0"),
-			Environment: getEnvString("APP_ENVIRONMENT", "development"),
+			Name:        "sia-image-service",
+			Version:     "1.0.0",
+			Environment: "development",
+			WatchConfig: true,
 		},
 		Server: ServerConfig{
-			GRPCPort: getEnvInt("GRPC_PORT", 8080),
-			HTTPPort: getEnvInt("HTTP_PORT", 9090),
+			GRPCPort:       8080,
+			HTTPPort:       9090,
+			GatewayEnabled: true,
 		},
 		Image: ImageConfig{
-			APIKey:      getEnvString("IMAGE_API_KEY", ""),
-			BaseURL:     getEnvString("IMAGE_BASE_URL", "https://ark.cn-beijing.volces.com"),
-			Model:       getEnvString("IMAGE_MODEL", "doubao-seedream-4-0-250828"),
-			DefaultSize: getEnvString("IMAGE_DEFAULT_SIZE", "2K"),
-			Timeout:     getEnvInt("IMAGE_TIMEOUT", 300),
-			MaxRetries:  getEnvInt("IMAGE_MAX_RETRIES", 3),
+			BaseURL:     "https://ark.cn-beijing.volces.com",
+			Model:       "doubao-seedream-4-0-250828",
+			DefaultSize: "2K",
+			Timeout:     300,
+			MaxRetries:  3,
+
+			BreakerFailures: 5,
+			BreakerCooldown: 30,
+
+			Coalesce: true,
+
+			KeySelection:         "round_robin",
+			KeyRateLimitCooldown: 30,
+			KeyAuthCooldown:      600,
+		},
+		Models: ModelsConfig{
+			StickyKey:  "user",
+			FailoverOn: []string{models.FailoverServerError, models.FailoverCircuitOpen, models.FailoverUnreachable},
+			Models: map[string]ModelConfig{
+				"doubao-seedream-4-0-250828": {
+					Provider:           models.ProviderArk,
+					Sizes:              []string{"1K", "2K", "4K"},
+					AspectRatios:       []string{"1:1", "4:3", "3:4", "16:9", "9:16", "3:2", "2:3", "21:9"},
+					MaxReferenceImages: 10,
+					Sequential:         true,
+					MaxImages:          10,
+				},
+			},
 		},
 		Log: LogConfig{
-			Level:  getEnvString("LOG_LEVEL", "info"),
-			Format: getEnvString("LOG_FORMAT", "json"),
+			Level:      "info",
+			Format:     "json",
+			Output:     "stdout",
+			File:       "logs/sia.log",
+			MaxSize:    100,
+			MaxAge:     7,
+			MaxBackups: 10,
+			Compress:   false,
+			Redaction: RedactionConfig{
+				Prompts:      "hash",
+				PromptLength: 32,
+				MaskURLQuery: true,
+			},
+		},
+		Tracing: TracingConfig{
+			Enabled:       false,
+			Endpoint:      "localhost:4317",
+			Insecure:      true,
+			SampleRatio:   1,
+			ExportTimeout: 10,
+		},
+		Health: HealthConfig{
+			ProbeInterval: 30,
+			ProbeTimeout:  5,
+			ProbePath:     "/api/v3/models",
+			MaxQueueDepth: 100,
+		},
+		Storage: StorageConfig{
+			Backend:         "none",
+			MaxImageSize:    32,
+			DownloadTimeout: 60,
+			LocalDir:        "data/images",
+			S3: S3StorageConfig{
+				Region:    "us-east-1",
+				PathStyle: true,
+			},
+			SignedURLTTL: 3600,
+			ResizeWidths: []int{256, 512, 1024},
+			Retention: RetentionConfig{
+				Enabled:  false,
+				Interval: 3600,
+				Grace:    3600,
+				DryRun:   false,
+				KeepDays: 0,
+				TaskTTL:  86400,
+				Archive: ArchiveConfig{
+					Backend:  "none",
+					LocalDir: "data/archive",
+					S3:       S3StorageConfig{Region: "us-east-1"},
+				},
+			},
+		},
+		Cache: CacheConfig{
+			Enabled:    false,
+			TTL:        86400,
+			MaxEntries: 10000,
+		},
+		Auth: AuthConfig{
+			Enabled: false,
+			JWT: JWTConfig{
+				Enabled:       false,
+				JWKSCacheTTL:  300,
+				TenantClaim:   "tenant",
+				ScopeClaim:    "scope",
+				ClientIDClaim: "azp",
+				ClockSkew:     60,
+				TierClaim:     "tier",
+			},
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:     false,
+			KeyBy:       "tenant",
+			DefaultTier: "default",
+		},
+		Usage: UsageConfig{
+			Quota: QuotaConfig{
+				Enabled: false,
+			},
+			Pricing: PricingConfig{
+				Currency: "CNY",
+			},
+			Budget: BudgetConfig{
+				Enabled:        false,
+				Action:         "reject",
+				DowngradeSizes: []string{"4K", "2K", "1K"},
+			},
+		},
+		Secrets: SecretsConfig{
+			RefreshInterval: 60,
 		},
 	}
+}
 
-	// 验证必需的配置
-	if err := config.validate(); err != nil {
-		return nil, fmt.Errorf("config validation failed: %w", err)
+// applyEnvOverrides 处理无法用env标签表达的环境变量
+func (c *Config) applyEnvOverrides(document map[string]interface{}, errs *[]error) {
+	// 日志脱敏默认只在非开发环境启用
+	if os.Getenv("LOG_REDACT") == "" && !hasKey(document, "log", "redaction", "enabled") {
+		c.Log.Redaction.Enabled = c.App.Environment != "development"
 	}
 
-	return config, nil
+	// 默认分级的限流、配额和预算以及默认模型的价格可以由环境变量定义，覆盖各自的配置文件中的同名项
+	if c.RateLimit.Tiers == nil {
+		c.RateLimit.Tiers = make(map[string]TierLimitConfig)
+	}
+	tier, ok := c.RateLimit.Tiers[c.RateLimit.DefaultTier]
+	if !ok {
+		tier = TierLimitConfig{RequestsPerSecond: 5, Burst: 10, MaxConcurrent: 4}
+	}
+	envOverride("RATE_LIMIT_RPS", &tier.RequestsPerSecond, errs)
+	envOverride("RATE_LIMIT_BURST", &tier.Burst, errs)
+	envOverride("RATE_LIMIT_MAX_CONCURRENT", &tier.MaxConcurrent, errs)
+	c.RateLimit.Tiers[c.RateLimit.DefaultTier] = tier
+
+	if c.Usage.Quota.Tiers == nil {
+		c.Usage.Quota.Tiers = make(map[string]QuotaLimitConfig)
+	}
+	quota := c.Usage.Quota.Tiers[c.RateLimit.DefaultTier]
+	envOverride("QUOTA_DAILY_IMAGES", &quota.DailyImages, errs)
+	envOverride("QUOTA_MONTHLY_IMAGES", &quota.MonthlyImages, errs)
+	envOverride("QUOTA_DAILY_TOKENS", &quota.DailyTokens, errs)
+	envOverride("QUOTA_MONTHLY_TOKENS", &quota.MonthlyTokens, errs)
+	c.Usage.Quota.Tiers[c.RateLimit.DefaultTier] = quota
+
+	if c.Usage.Pricing.Models == nil {
+		c.Usage.Pricing.Models = make(map[string]ModelPriceConfig)
+	}
+	price := c.Usage.Pricing.Models[c.Image.Model]
+	envOverride("PRICING_PER_IMAGE", &price.PerImage, errs)
+	c.Usage.Pricing.Models[c.Image.Model] = price
+
+	if c.Usage.Budget.Tiers == nil {
+		c.Usage.Budget.Tiers = make(map[string]BudgetLimitConfig)
+	}
+	budget := c.Usage.Budget.Tiers[c.RateLimit.DefaultTier]
+	envOverride("BUDGET_DAILY_SPEND", &budget.DailySpend, errs)
+	envOverride("BUDGET_MONTHLY_SPEND", &budget.MonthlySpend, errs)
+	c.Usage.Budget.Tiers[c.RateLimit.DefaultTier] = budget
 }
 
-// validate 验证配置
-func (c *Config) validate() error {
-	if c.Image.APIKey == "" {
-		return fmt.Errorf("IMAGE_API_KEY is required")
+// validate 验证配置，返回全部错误
+func (c *Config) validate() []error {
+	var errs []error
+
+	if c.Image.APIKey == "" && len(c.Image.APIKeys) == 0 {
+		errs = append(errs, fmt.Errorf("IMAGE_API_KEY or IMAGE_API_KEYS is required"))
+	}
+
+	upstreamKeyIDs := make(map[string]bool)
+	for _, key := range c.Image.UpstreamKeys() {
+		if key.ID == "" || key.Key == "" {
+			errs = append(errs, fmt.Errorf("upstream API key %q: id and key are required", key.ID))
+		}
+		if upstreamKeyIDs[key.ID] {
+			errs = append(errs, fmt.Errorf("duplicate upstream API key %q", key.ID))
+		}
+		upstreamKeyIDs[key.ID] = true
+	}
+
+	validKeySelections := []string{"round_robin", "least_used"}
+	if !contains(validKeySelections, c.Image.KeySelection) {
+		errs = append(errs, fmt.Errorf("invalid IMAGE_KEY_SELECTION: %s, must be one of %v", c.Image.KeySelection, validKeySelections))
+	}
+
+	if c.Image.KeyRateLimitCooldown < 0 || c.Image.KeyAuthCooldown < 0 {
+		errs = append(errs, fmt.Errorf("IMAGE_KEY_RATE_LIMIT_COOLDOWN and IMAGE_KEY_AUTH_COOLDOWN must not be negative"))
+	}
+
+	providers := append([]string(nil), models.Providers...)
+	for name, provider := range c.Models.Providers {
+		if contains(models.Providers, name) {
+			errs = append(errs, fmt.Errorf("provider %q: name is reserved for the primary upstream", name))
+			continue
+		}
+		providers = append(providers, name)
+		if provider.BaseURL == "" || len(provider.APIKeys) == 0 {
+			errs = append(errs, fmt.Errorf("provider %q: base_url and api_keys (or %s) are required", name, ProviderKeysEnv(name)))
+		}
+		keyIDs := make(map[string]bool)
+		for _, key := range provider.APIKeys {
+			if key.ID == "" || key.Key == "" {
+				errs = append(errs, fmt.Errorf("provider %q: API key %q: id and key are required", name, key.ID))
+			}
+			if keyIDs[key.ID] {
+				errs = append(errs, fmt.Errorf("provider %q: duplicate API key %q", name, key.ID))
+			}
+			keyIDs[key.ID] = true
+		}
+	}
+	sort.Strings(providers)
+
+	for name, model := range c.Models.Models {
+		if !contains(providers, model.Provider) {
+			errs = append(errs, fmt.Errorf("model %q: invalid provider %q, must be one of %v", name, model.Provider, providers))
+		}
+		if len(model.Sizes) == 0 && len(model.AspectRatios) == 0 {
+			errs = append(errs, fmt.Errorf("model %q: sizes or aspect_ratios is required", name))
+		}
+		for _, ratio := range model.AspectRatios {
+			if _, _, err := models.ParseAspectRatio(ratio); err != nil {
+				errs = append(errs, fmt.Errorf("model %q: %w", name, err))
+			}
+		}
+		if model.MaxReferenceImages < 0 || model.MaxImages <= 0 {
+			errs = append(errs, fmt.Errorf("model %q: max_reference_images must not be negative and max_images must be positive", name))
+		}
+	}
+
+	for name, alias := range c.Models.Aliases {
+		if _, ok := c.Models.Models[name]; ok {
+			errs = append(errs, fmt.Errorf("model alias %q: name is already used by a model", name))
+		}
+		if len(alias.Targets) == 0 {
+			errs = append(errs, fmt.Errorf("model alias %q: targets is required", name))
+		}
+		for _, target := range alias.Targets {
+			if _, ok := c.Models.Models[target.Model]; !ok {
+				errs = append(errs, fmt.Errorf("model alias %q: target %q is not in the model registry", name, target.Model))
+			}
+			if target.Weight <= 0 {
+				errs = append(errs, fmt.Errorf("model alias %q: weight of %q must be positive", name, target.Model))
+			}
+		}
+		for _, fallback := range alias.Fallbacks {
+			if _, ok := c.Models.Models[fallback.Model]; !ok {
+				errs = append(errs, fmt.Errorf("model alias %q: fallback %q is not in the model registry", name, fallback.Model))
+			}
+			if fallback.Provider != "" && !contains(providers, fallback.Provider) {
+				errs = append(errs, fmt.Errorf("model alias %q: invalid fallback provider %q, must be one of %v", name, fallback.Provider, providers))
+			}
+		}
+		for _, class := range alias.FailoverOn {
+			if !contains(models.FailoverClasses, class) {
+				errs = append(errs, fmt.Errorf("model alias %q: invalid failover_on %q, must be one of %v", name, class, models.FailoverClasses))
+			}
+		}
+	}
+
+	for _, class := range c.Models.FailoverOn {
+		if !contains(models.FailoverClasses, class) {
+			errs = append(errs, fmt.Errorf("invalid MODEL_FAILOVER_ON: %s, must be one of %v", class, models.FailoverClasses))
+		}
+	}
+
+	// 默认模型可以是别名，此时别名的每个目标都必须支持默认尺寸
+	registry := c.Models.Registry()
+	defaultModels := []string{c.Image.Model}
+	if alias, ok := c.Models.Aliases[c.Image.Model]; ok {
+		defaultModels = defaultModels[:0]
+		for _, target := range alias.Targets {
+			defaultModels = append(defaultModels, target.Model)
+		}
+	}
+	for _, name := range defaultModels {
+		model, ok := registry.Get(name)
+		switch {
+		case !ok && name == c.Image.Model:
+			errs = append(errs, fmt.Errorf("IMAGE_MODEL %s is not a model or alias in the model registry", name))
+		case ok && !model.SupportsSize(c.Image.DefaultSize):
+			errs = append(errs, fmt.Errorf("IMAGE_DEFAULT_SIZE %s is not supported by model %s", c.Image.DefaultSize, name))
+		}
 	}
 
 	if c.Server.GRPCPort <= 0 || c.Server.GRPCPort > 65535 {
-		return fmt.Errorf("invalid GRPC_PORT: %d", c.Server.GRPCPort)
+		errs = append(errs, fmt.Errorf("invalid GRPC_PORT: %d", c.Server.GRPCPort))
 	}
 
 	if c.Server.HTTPPort <= 0 || c.Server.HTTPPort > 65535 {
-		return fmt.Errorf("invalid HTTP_PORT: %d", c.Server.HTTPPort)
+		errs = append(errs, fmt.Errorf("invalid HTTP_PORT: %d", c.Server.HTTPPort))
 	}
 
 	validLogLevels := []string{"debug", "info", "warn", "error"}
 	if !contains(validLogLevels, c.Log.Level) {
-		return fmt.Errorf("invalid LOG_LEVEL: %s, must be one of %v", c.Log.Level, validLogLevels)
+		errs = append(errs, fmt.Errorf("invalid LOG_LEVEL: %s, must be one of %v", c.Log.Level, validLogLevels))
 	}
 
 	validLogFormats := []string{"json", "text"}
 	if !contains(validLogFormats, c.Log.Format) {
-		return fmt.Errorf("invalid LOG_FORMAT: %s, must be one of %v", c.Log.Format, validLogFormats)
+		errs = append(errs, fmt.Errorf("invalid LOG_FORMAT: %s, must be one of %v", c.Log.Format, validLogFormats))
 	}
 
-	return nil
-}
+	validLogOutputs := []string{"stdout", "stderr", "file"}
+	if !contains(validLogOutputs, c.Log.Output) {
+		errs = append(errs, fmt.Errorf("invalid LOG_OUTPUT: %s, must be one of %v", c.Log.Output, validLogOutputs))
+	}
 
-// loadEnvFile 加载环境变量文件
-func loadEnvFile(filename string) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return // 文件不存在时忽略
+	if c.Log.Output == "file" && c.Log.File == "" {
+		errs = append(errs, fmt.Errorf("LOG_FILE is required when LOG_OUTPUT is file"))
 	}
-	defer file.Close()
 
-	// 简单的 .env 文件解析
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		return
+	if c.Log.MaxSize < 0 || c.Log.MaxAge < 0 || c.Log.MaxBackups < 0 {
+		errs = append(errs, fmt.Errorf("LOG_MAX_SIZE, LOG_MAX_AGE and LOG_MAX_BACKUPS must not be negative"))
 	}
 
-	lines := strings.Split(string(content), "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
+	validPromptModes := []string{"hash", "truncate", "off"}
+	if !contains(validPromptModes, c.Log.Redaction.Prompts) {
+		errs = append(errs, fmt.Errorf("invalid LOG_REDACT_PROMPTS: %s, must be one of %v", c.Log.Redaction.Prompts, validPromptModes))
+	}
+
+	if c.Log.Redaction.PromptLength < 0 {
+		errs = append(errs, fmt.Errorf("LOG_REDACT_PROMPT_LENGTH must not be negative"))
+	}
+
+	for _, pattern := range c.Log.Redaction.Patterns {
+		if _, err := regexp.Compile(pattern.Pattern); err != nil {
+			errs = append(errs, fmt.Errorf("invalid redaction pattern %q: %w", pattern.Pattern, err))
+		}
+	}
+
+	if c.Image.BreakerFailures < 0 || c.Image.BreakerCooldown <= 0 {
+		errs = append(errs, fmt.Errorf("IMAGE_BREAKER_FAILURES must not be negative and IMAGE_BREAKER_COOLDOWN must be positive"))
+	}
+
+	if c.Health.ProbeInterval <= 0 || c.Health.ProbeTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("HEALTH_PROBE_INTERVAL and HEALTH_PROBE_TIMEOUT must be positive"))
+	}
+
+	if c.Health.MaxQueueDepth < 0 {
+		errs = append(errs, fmt.Errorf("HEALTH_MAX_QUEUE_DEPTH must not be negative"))
+	}
+
+	validBackends := []string{"none", "local", "s3"}
+	if !contains(validBackends, c.Storage.Backend) {
+		errs = append(errs, fmt.Errorf("invalid STORAGE_BACKEND: %s, must be one of %v", c.Storage.Backend, validBackends))
+	}
+
+	if c.Storage.MaxImageSize <= 0 || c.Storage.DownloadTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("STORAGE_MAX_IMAGE_SIZE and STORAGE_DOWNLOAD_TIMEOUT must be positive"))
+	}
+
+	if c.Storage.Backend == "local" && c.Storage.LocalDir == "" {
+		errs = append(errs, fmt.Errorf("STORAGE_LOCAL_DIR is required when STORAGE_BACKEND is local"))
+	}
+
+	if c.Storage.Backend == "s3" {
+		if c.Storage.S3.Endpoint == "" || c.Storage.S3.Bucket == "" {
+			errs = append(errs, fmt.Errorf("STORAGE_S3_ENDPOINT and STORAGE_S3_BUCKET are required when STORAGE_BACKEND is s3"))
 		}
+		if c.Storage.S3.AccessKey == "" || c.Storage.S3.SecretKey == "" {
+			errs = append(errs, fmt.Errorf("STORAGE_S3_ACCESS_KEY and STORAGE_S3_SECRET_KEY are required when STORAGE_BACKEND is s3"))
+		}
+	}
+
+	if c.Storage.SignedURLTTL <= 0 {
+		errs = append(errs, fmt.Errorf("STORAGE_SIGNED_URL_TTL must be positive"))
+	}
 
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-			// 只有当环境变量不存在时才设置
-			if os.Getenv(key) == "" {
-				os.Setenv(key, value)
+	signingKeyIDs := make(map[string]bool)
+	for _, key := range c.Storage.SigningKeys {
+		if key.ID == "" || len(key.Secret) < 16 {
+			errs = append(errs, fmt.Errorf("storage signing key %q: id is required and secret must be at least 16 characters", key.ID))
+		}
+		if signingKeyIDs[key.ID] {
+			errs = append(errs, fmt.Errorf("duplicate storage signing key %q", key.ID))
+		}
+		signingKeyIDs[key.ID] = true
+	}
+
+	for _, width := range c.Storage.ResizeWidths {
+		if width <= 0 {
+			errs = append(errs, fmt.Errorf("invalid STORAGE_RESIZE_WIDTHS: %d, widths must be positive", width))
+		}
+	}
+
+	if c.Storage.Retention.Interval <= 0 || c.Storage.Retention.Grace < 0 || c.Storage.Retention.KeepDays < 0 {
+		errs = append(errs, fmt.Errorf("STORAGE_RETENTION_INTERVAL must be positive, STORAGE_RETENTION_GRACE and STORAGE_RETENTION_DAYS must not be negative"))
+	}
+	if c.Storage.Retention.TaskTTL <= 0 {
+		errs = append(errs, fmt.Errorf("STORAGE_RETENTION_TASK_TTL must be positive"))
+	}
+
+	for i, policy := range c.Storage.Retention.Policies {
+		if policy.Name == "" {
+			errs = append(errs, fmt.Errorf("retention policy #%d: name is required", i+1))
+		}
+		if policy.KeepDays < 0 {
+			errs = append(errs, fmt.Errorf("retention policy %q: keep_days must not be negative", policy.Name))
+		}
+		if policy.Action != "" && policy.Action != "delete" && policy.Action != "archive" {
+			errs = append(errs, fmt.Errorf("retention policy %q: invalid action %s, must be one of [delete archive]", policy.Name, policy.Action))
+		}
+		if policy.Action == "archive" && c.Storage.Retention.Archive.Backend == "none" {
+			errs = append(errs, fmt.Errorf("retention policy %q: action archive requires STORAGE_ARCHIVE_BACKEND", policy.Name))
+		}
+	}
+
+	if !contains(validBackends, c.Storage.Retention.Archive.Backend) {
+		errs = append(errs, fmt.Errorf("invalid STORAGE_ARCHIVE_BACKEND: %s, must be one of %v", c.Storage.Retention.Archive.Backend, validBackends))
+	}
+
+	if c.Storage.Retention.Archive.Backend == "s3" && (c.Storage.Retention.Archive.S3.Endpoint == "" || c.Storage.Retention.Archive.S3.Bucket == "") {
+		errs = append(errs, fmt.Errorf("retention.archive.s3.endpoint and bucket are required when STORAGE_ARCHIVE_BACKEND is s3"))
+	}
+
+	if c.Cache.Enabled {
+		// 缓存只保存持久化后的图片，上游URL会过期
+		if c.Storage.Backend == "none" {
+			errs = append(errs, fmt.Errorf("RESULT_CACHE_ENABLED requires STORAGE_BACKEND"))
+		}
+		if c.Cache.TTL <= 0 || c.Cache.MaxEntries <= 0 {
+			errs = append(errs, fmt.Errorf("RESULT_CACHE_TTL and RESULT_CACHE_MAX_ENTRIES must be positive"))
+		}
+	}
+
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, fmt.Errorf("invalid TRACING_SAMPLE_RATIO: %v, must be between 0 and 1", c.Tracing.SampleRatio))
+	}
+
+	if c.Tracing.Enabled && c.Tracing.Endpoint == "" {
+		errs = append(errs, fmt.Errorf("TRACING_OTLP_ENDPOINT is required when TRACING_ENABLED is true"))
+	}
+
+	if c.Auth.Enabled && c.Auth.APIKeysFile == "" && !c.Auth.JWT.Enabled {
+		errs = append(errs, fmt.Errorf("AUTH_API_KEYS_FILE or AUTH_JWT_ENABLED is required when AUTH_ENABLED is true"))
+	}
+
+	if c.Auth.JWT.Enabled {
+		if (c.Auth.JWT.JWKSFile == "") == (c.Auth.JWT.JWKSURL == "") {
+			errs = append(errs, fmt.Errorf("exactly one of AUTH_JWT_JWKS_FILE or AUTH_JWT_JWKS_URL is required"))
+		}
+		if c.Auth.JWT.Issuer == "" {
+			errs = append(errs, fmt.Errorf("AUTH_JWT_ISSUER is required when AUTH_JWT_ENABLED is true"))
+		}
+		if len(c.Auth.JWT.Audiences) == 0 {
+			errs = append(errs, fmt.Errorf("AUTH_JWT_AUDIENCES is required when AUTH_JWT_ENABLED is true"))
+		}
+	}
+
+	if c.RateLimit.KeyBy != "tenant" && c.RateLimit.KeyBy != "client" {
+		errs = append(errs, fmt.Errorf("invalid RATE_LIMIT_KEY_BY: %s, must be one of [tenant client]", c.RateLimit.KeyBy))
+	}
+
+	if _, ok := c.RateLimit.Tiers[c.RateLimit.DefaultTier]; !ok {
+		errs = append(errs, fmt.Errorf("rate limit tier %q (RATE_LIMIT_DEFAULT_TIER) is not defined", c.RateLimit.DefaultTier))
+	}
+
+	for name, tier := range c.RateLimit.Tiers {
+		if tier.RequestsPerSecond < 0 || tier.Burst < 0 || tier.MaxConcurrent < 0 {
+			errs = append(errs, fmt.Errorf("rate limit tier %q: limits must not be negative", name))
+		}
+	}
+
+	for name, model := range c.RateLimit.Models {
+		if model.RequestsPerSecond < 0 || model.Burst < 0 || model.MaxConcurrent < 0 {
+			errs = append(errs, fmt.Errorf("rate limit model %q: limits must not be negative", name))
+		}
+	}
+
+	for name, quota := range c.Usage.Quota.Tiers {
+		if quota.DailyImages < 0 || quota.MonthlyImages < 0 || quota.DailyTokens < 0 || quota.MonthlyTokens < 0 {
+			errs = append(errs, fmt.Errorf("quota tier %q: limits must not be negative", name))
+		}
+	}
+
+	for name, quota := range c.Usage.Quota.Tenants {
+		if quota.DailyImages < 0 || quota.MonthlyImages < 0 || quota.DailyTokens < 0 || quota.MonthlyTokens < 0 {
+			errs = append(errs, fmt.Errorf("quota tenant %q: limits must not be negative", name))
+		}
+	}
+
+	for name, price := range c.Usage.Pricing.Models {
+		if price.PerImage < 0 {
+			errs = append(errs, fmt.Errorf("pricing model %q: per_image must not be negative", name))
+		}
+		for size, perImage := range price.Sizes {
+			if perImage < 0 {
+				errs = append(errs, fmt.Errorf("pricing model %q: price for size %q must not be negative", name, size))
 			}
 		}
 	}
-}
 
-// getEnvString 获取字符串环境变量
-func getEnvString(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+	if c.Usage.Budget.Action != "reject" && c.Usage.Budget.Action != "downgrade" {
+		errs = append(errs, fmt.Errorf("invalid BUDGET_ACTION: %s, must be one of [reject downgrade]", c.Usage.Budget.Action))
+	}
+
+	for name, budget := range c.Usage.Budget.Tiers {
+		if budget.DailySpend < 0 || budget.MonthlySpend < 0 {
+			errs = append(errs, fmt.Errorf("budget tier %q: limits must not be negative", name))
+		}
 	}
-	return defaultValue
-}
 
-// getEnvInt 获取整数环境变量
-func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intValue, err := strconv.Atoi(value); err == nil {
-			return intValue
+	for name, budget := range c.Usage.Budget.Tenants {
+		if budget.DailySpend < 0 || budget.MonthlySpend < 0 {
+			errs = append(errs, fmt.Errorf("budget tenant %q: limits must not be negative", name))
 		}
 	}
-	return defaultValue
+
+	if c.Secrets.File != "" && c.Secrets.Key == "" {
+		errs = append(errs, fmt.Errorf("SECRETS_KEY is required when SECRETS_FILE is set"))
+	}
+
+	if c.Secrets.RefreshInterval < 0 {
+		errs = append(errs, fmt.Errorf("SECRETS_REFRESH_INTERVAL must not be negative"))
+	}
+
+	return errs
+}
+
+// loadJSONFile 读取JSON文件并合并到目标结构
+func loadJSONFile(filename string, v interface{}) error {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, v)
 }
 
 // contains 检查切片是否包含指定元素
@@ -0,0 +1,198 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFile 在临时目录中写入文件并返回路径
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// setRequiredEnv 设置通过验证所需的最少配置
+func setRequiredEnv(t *testing.T) {
+	t.Setenv("IMAGE_API_KEY", "test-key")
+	t.Setenv("AUTH_ENABLED", "false")
+}
+
+func TestLoadLayerPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	quotaFile := writeFile(t, dir, "quotas.json", `{
+		"tiers": {
+			"default": {"daily_images": 100, "monthly_images": 1000},
+			"pro": {"daily_images": 200}
+		}
+	}`)
+	rateLimitFile := writeFile(t, dir, "rate_limits.json", `{
+		"tiers": {"default": {"requests_per_second": 7, "burst": 14, "max_concurrent": 3}}
+	}`)
+	configFile := writeFile(t, dir, "sia.yaml", `
+log:
+  level: warn
+  format: text
+usage:
+  quota:
+    enabled: true
+    file: `+quotaFile+`
+`)
+
+	setRequiredEnv(t)
+	t.Setenv("RATE_LIMIT_FILE", rateLimitFile)
+	t.Setenv("QUOTA_DAILY_IMAGES", "50")
+	t.Setenv("LOG_LEVEL", "error")
+
+	config, err := Load([]string{
+		"--config", configFile,
+		"--set", "usage.quota.tiers.pro.daily_images=300",
+		"--set", "log.level=debug",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 默认值 < 配置文件
+	if config.Log.Format != "text" {
+		t.Errorf("log.format = %q, want text from the config file", config.Log.Format)
+	}
+	// *_FILE文件 < 环境变量
+	quota := config.Usage.Quota.Tiers["default"]
+	if quota.DailyImages != 50 || quota.MonthlyImages != 1000 {
+		t.Errorf("default quota = %+v, want daily 50 from env and monthly 1000 from QUOTA_FILE", quota)
+	}
+	// *_FILE文件 < --set
+	if got := config.Usage.Quota.Tiers["pro"].DailyImages; got != 300 {
+		t.Errorf("pro daily_images = %d, want 300 from --set", got)
+	}
+	// 路径来自环境变量的*_FILE文件，其中的配置项不被内置默认值覆盖
+	if tier := config.RateLimit.Tiers["default"]; tier != (TierLimitConfig{RequestsPerSecond: 7, Burst: 14, MaxConcurrent: 3}) {
+		t.Errorf("default rate limit = %+v, want the values from RATE_LIMIT_FILE", tier)
+	}
+	// 配置文件 < 环境变量 < --set
+	if config.Log.Level != "debug" {
+		t.Errorf("log.level = %q, want debug from --set", config.Log.Level)
+	}
+}
+
+func TestLoadSideFilePathFromSet(t *testing.T) {
+	dir := t.TempDir()
+	envFile := writeFile(t, dir, "env.json", `{"tiers": {"default": {"daily_images": 1}}}`)
+	setFile := writeFile(t, dir, "set.json", `{"tiers": {"default": {"daily_images": 2}}}`)
+
+	setRequiredEnv(t)
+	t.Setenv("QUOTA_FILE", envFile)
+
+	config, err := Load([]string{"--set", "usage.quota.file=" + setFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Usage.Quota.File != setFile {
+		t.Errorf("usage.quota.file = %q, want %q", config.Usage.Quota.File, setFile)
+	}
+	if got := config.Usage.Quota.Tiers["default"].DailyImages; got != 2 {
+		t.Errorf("daily_images = %d, want 2 from the file given by --set", got)
+	}
+}
+
+func TestLoadSecretPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	configFile := writeFile(t, dir, "sia.yaml", "image:\n  api_key: from-config-file\n")
+	t.Setenv("AUTH_ENABLED", "false")
+	t.Setenv("IMAGE_API_KEY_FILE", writeFile(t, dir, "api_key", "from-secret-file\n"))
+	t.Setenv("STORAGE_SIGNING_KEYS", "env:0123456789abcdef")
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		// 配置文件 < 密钥来源
+		{name: "secret source", args: []string{"--config", configFile}, want: "from-secret-file"},
+		// 密钥来源 < --set
+		{name: "set", args: []string{"--config", configFile, "--set", "image.api_key=from-set"}, want: "from-set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := Load(append(tt.args, "--set", `storage.signing_keys=[{"id":"set","secret":"0123456789abcdef"}]`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if config.Image.APIKey != tt.want {
+				t.Errorf("image.api_key = %q, want %q", config.Image.APIKey, tt.want)
+			}
+			if len(config.Storage.SigningKeys) != 1 || config.Storage.SigningKeys[0].ID != "set" {
+				t.Errorf("storage.signing_keys = %+v, want the key from --set", config.Storage.SigningKeys)
+			}
+		})
+	}
+}
+
+func TestLoadDefaultsAccessControlsOff(t *testing.T) {
+	t.Setenv("IMAGE_API_KEY", "test-key")
+	for _, name := range []string{"AUTH_ENABLED", "AUTH_API_KEYS_FILE", "AUTH_JWT_ENABLED", "RATE_LIMIT_ENABLED", "QUOTA_ENABLED", "BUDGET_ENABLED"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+
+	config, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load() without access control settings: %v", err)
+	}
+	if config.Auth.Enabled {
+		t.Fatal("auth is enabled by default")
+	}
+	if config.RateLimit.Enabled {
+		t.Fatal("rate limiting is enabled by default")
+	}
+	if config.Usage.Quota.Enabled {
+		t.Fatal("quota is enabled by default")
+	}
+	if config.Usage.Budget.Enabled {
+		t.Fatal("budget is enabled by default")
+	}
+}
+
+func TestDiffProviderKeysHot(t *testing.T) {
+	old := defaultConfig()
+	old.Models.Providers = map[string]ProviderConfig{
+		"backup": {BaseURL: "https://backup.example.com", APIKeys: []UpstreamKeyConfig{{ID: "b1", Key: "old"}}},
+	}
+
+	tests := []struct {
+		name        string
+		modify      func(providers map[string]ProviderConfig)
+		wantPath    string
+		wantRestart bool
+	}{
+		{name: "rotated keys", wantPath: "models.providers.backup.api_keys", modify: func(providers map[string]ProviderConfig) {
+			providers["backup"] = ProviderConfig{BaseURL: "https://backup.example.com", APIKeys: []UpstreamKeyConfig{{ID: "b2", Key: "new"}}}
+		}},
+		{name: "base url", wantPath: "models.providers.backup.base_url", wantRestart: true, modify: func(providers map[string]ProviderConfig) {
+			providers["backup"] = ProviderConfig{BaseURL: "https://other.example.com", APIKeys: old.Models.Providers["backup"].APIKeys}
+		}},
+		{name: "new provider", wantPath: "models.providers.other", wantRestart: true, modify: func(providers map[string]ProviderConfig) {
+			providers["other"] = ProviderConfig{BaseURL: "https://other.example.com"}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			updated := *old
+			updated.Models.Providers = map[string]ProviderConfig{"backup": old.Models.Providers["backup"]}
+			tt.modify(updated.Models.Providers)
+
+			changes := Diff(old, &updated)
+			if len(changes) != 1 || changes[0].Path != tt.wantPath || changes[0].Restart != tt.wantRestart {
+				t.Fatalf("Diff() = %+v, want %s with restart=%v", changes, tt.wantPath, tt.wantRestart)
+			}
+			if tt.wantPath == "models.providers.backup.api_keys" && (changes[0].Old != "<redacted>" || changes[0].New != "<redacted>") {
+				t.Errorf("provider keys are not redacted: %+v", changes[0])
+			}
+		})
+	}
+}
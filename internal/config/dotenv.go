@@ -0,0 +1,179 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// dotenvValues 由.env设置的环境变量，重新加载时可以更新或删除
+var (
+	dotenvMutex  sync.Mutex
+	dotenvValues = make(map[string]string)
+)
+
+// loadEnvFile 加载.env文件，只设置尚未设置或由之前加载的.env设置的环境变量；文件不存在时忽略
+func loadEnvFile(filename string) []error {
+	content, err := os.ReadFile(filename)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return []error{fmt.Errorf("failed to read %s: %w", filename, err)}
+	}
+
+	values, errs := parseEnvFile(string(content))
+	for i := range errs {
+		errs[i] = fmt.Errorf("%s:%w", filename, errs[i])
+	}
+
+	dotenvMutex.Lock()
+	defer dotenvMutex.Unlock()
+
+	loaded := make(map[string]string, len(values))
+	for _, value := range values {
+		loaded[value.key] = value.value
+		current := os.Getenv(value.key)
+		if previous, ok := dotenvValues[value.key]; current == "" || (ok && current == previous) {
+			os.Setenv(value.key, value.value)
+			dotenvValues[value.key] = value.value
+		}
+	}
+
+	// 从.env中删除的变量恢复为未设置
+	for key, previous := range dotenvValues {
+		if _, ok := loaded[key]; !ok {
+			if os.Getenv(key) == previous {
+				os.Unsetenv(key)
+			}
+			delete(dotenvValues, key)
+		}
+	}
+	return errs
+}
+
+// envValue .env文件中的变量
+type envValue struct {
+	key   string
+	value string
+}
+
+// parseEnvFile 解析.env文件内容
+// 支持export前缀、单引号（按原样保留）、双引号（支持\n \t \" \\转义，可以跨行）和未加引号的值后的行内注释（# 前需有空白）
+func parseEnvFile(content string) ([]envValue, []error) {
+	var values []envValue
+	var errs []error
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		lineNumber := i + 1
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if rest, ok := strings.CutPrefix(line, "export"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			line = strings.TrimSpace(rest)
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !validEnvKey(key) {
+			errs = append(errs, fmt.Errorf("%d: expected KEY=VALUE", lineNumber))
+			continue
+		}
+		raw = strings.TrimLeft(raw, " \t")
+
+		var value string
+		switch {
+		case strings.HasPrefix(raw, "'"):
+			end := strings.Index(raw[1:], "'")
+			if end < 0 {
+				errs = append(errs, fmt.Errorf("%d: unterminated single-quoted value for %s", lineNumber, key))
+				continue
+			}
+			value, raw = raw[1:end+1], raw[end+2:]
+
+		case strings.HasPrefix(raw, `"`):
+			// 双引号中的值可以跨行，读取到匹配的引号为止
+			var parsed strings.Builder
+			var closed bool
+			raw = raw[1:]
+			for {
+				var rest string
+				rest, closed = unquoteEnvValue(raw, &parsed)
+				if closed || i+1 >= len(lines) {
+					raw = rest
+					break
+				}
+				parsed.WriteByte('\n')
+				i++
+				raw = lines[i]
+			}
+			if !closed {
+				errs = append(errs, fmt.Errorf("%d: unterminated double-quoted value for %s", lineNumber, key))
+				continue
+			}
+			value = parsed.String()
+
+		default:
+			value, raw = raw, ""
+			for j := 0; j < len(value); j++ {
+				if value[j] == '#' && (j == 0 || value[j-1] == ' ' || value[j-1] == '\t') {
+					value = value[:j]
+					break
+				}
+			}
+			value = strings.TrimSpace(value)
+		}
+
+		// 引号后只允许注释
+		if rest := strings.TrimSpace(raw); rest != "" && !strings.HasPrefix(rest, "#") {
+			errs = append(errs, fmt.Errorf("%d: unexpected characters after quoted value for %s", lineNumber, key))
+			continue
+		}
+
+		values = append(values, envValue{key: key, value: value})
+	}
+	return values, errs
+}
+
+// unquoteEnvValue 解析双引号值的一行，返回结束引号之后的内容以及是否遇到了结束引号
+func unquoteEnvValue(line string, parsed *strings.Builder) (string, bool) {
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '"':
+			return line[i+1:], true
+		case c == '\\' && i+1 < len(line):
+			i++
+			switch line[i] {
+			case 'n':
+				parsed.WriteByte('\n')
+			case 't':
+				parsed.WriteByte('\t')
+			case 'r':
+				parsed.WriteByte('\r')
+			case '"', '\\', '$':
+				parsed.WriteByte(line[i])
+			default:
+				parsed.WriteByte('\\')
+				parsed.WriteByte(line[i])
+			}
+		default:
+			parsed.WriteByte(c)
+		}
+	}
+	return "", false
+}
+
+// validEnvKey 检查环境变量名是否只包含字母、数字和下划线且不以数字开头
+func validEnvKey(key string) bool {
+	if key == "" || (key[0] >= '0' && key[0] <= '9') {
+		return false
+	}
+	for _, c := range key {
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,267 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationError 配置错误，包含加载和验证过程中发现的全部错误
+type ValidationError struct {
+	Errors []error
+}
+
+// Error 实现error接口
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return "config validation failed: " + strings.Join(messages, "; ")
+}
+
+// Unwrap 支持errors.Is和errors.As
+func (e *ValidationError) Unwrap() []error {
+	return e.Errors
+}
+
+// setFlags 可重复的--set参数
+type setFlags []string
+
+// String 实现flag.Value
+func (f *setFlags) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set 实现flag.Value
+func (f *setFlags) Set(value string) error {
+	if path, _, ok := strings.Cut(value, "="); !ok || path == "" {
+		return fmt.Errorf("expected path=value, got %q", value)
+	}
+	*f = append(*f, value)
+	return nil
+}
+
+// loadConfigFile 读取YAML或JSON配置文件并合并到配置，返回文件内容用于判断设置了哪些配置项
+// 未知的配置项视为错误，避免拼写错误被静默忽略
+func loadConfigFile(filename string, config *Config) (map[string]interface{}, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var document map[string]interface{}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(content, &document); err != nil {
+			return nil, err
+		}
+		// 转换为JSON后解码，与*_FILE文件共用json标签
+		if content, err = json.Marshal(document); err != nil {
+			return nil, err
+		}
+	case ".json":
+		if err := json.Unmarshal(content, &document); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q, must be .yaml, .yml or .json", filepath.Ext(filename))
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(config); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// applyEnv 按env标签把环境变量设置到配置，未设置或为空的环境变量不覆盖已有的值
+func applyEnv(v reflect.Value, errs *[]error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("env")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			if field.Type.Kind() == reflect.Struct {
+				applyEnv(v.Field(i), errs)
+			}
+			continue
+		}
+
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		if err := setValue(v.Field(i), value); err != nil {
+			*errs = append(*errs, fmt.Errorf("invalid %s: %w", name, err))
+		}
+	}
+}
+
+// envOverride 环境变量已设置时解析并覆盖目标值
+func envOverride[T any](name string, target *T, errs *[]error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return
+	}
+	if err := setValue(reflect.ValueOf(target).Elem(), value); err != nil {
+		*errs = append(*errs, fmt.Errorf("invalid %s: %w", name, err))
+	}
+}
+
+// setValue 解析字符串并设置到v：列表使用逗号分隔，其他结构使用JSON
+func setValue(v reflect.Value, raw string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(value)
+	case reflect.Int, reflect.Int64:
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+		v.SetInt(value)
+	case reflect.Float64:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(value)
+	case reflect.Slice:
+		kind := v.Type().Elem().Kind()
+		if kind != reflect.String && kind != reflect.Int {
+			return json.Unmarshal([]byte(raw), v.Addr().Interface())
+		}
+		items := reflect.MakeSlice(v.Type(), 0, 0)
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			element := reflect.New(v.Type().Elem()).Elem()
+			if err := setValue(element, item); err != nil {
+				return err
+			}
+			items = reflect.Append(items, element)
+		}
+		v.Set(items)
+	default:
+		return json.Unmarshal([]byte(raw), v.Addr().Interface())
+	}
+	return nil
+}
+
+// applyOverrides 按顺序应用路径满足match的--set参数
+func (c *Config) applyOverrides(overrides setFlags, match func(path string) bool, errs *[]error) {
+	for _, override := range overrides {
+		path, value, _ := strings.Cut(override, "=")
+		if !match(path) {
+			continue
+		}
+		if err := setPath(reflect.ValueOf(c).Elem(), strings.Split(path, "."), value); err != nil {
+			*errs = append(*errs, fmt.Errorf("--set %s: %w", path, err))
+		}
+	}
+}
+
+// setPath 按json标签组成的路径（如usage.quota.tiers.default.daily_images）设置配置项
+func setPath(v reflect.Value, path []string, raw string) error {
+	if len(path) == 0 || path[0] == "" {
+		return setValue(v, raw)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			if name == path[0] {
+				return setPath(v.Field(i), path[1:], raw)
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		// map元素不可寻址，复制后修改再写回
+		key := reflect.ValueOf(path[0]).Convert(v.Type().Key())
+		element := reflect.New(v.Type().Elem()).Elem()
+		if existing := v.MapIndex(key); existing.IsValid() {
+			element.Set(existing)
+		}
+		if err := setPath(element, path[1:], raw); err != nil {
+			return err
+		}
+		v.SetMapIndex(key, element)
+		return nil
+	}
+	return errors.New("unknown config field " + strconv.Quote(path[0]))
+}
+
+// hasKey 检查配置文件是否设置了指定路径的配置项
+func hasKey(document map[string]interface{}, path ...string) bool {
+	for i, key := range path {
+		value, ok := document[key]
+		if !ok {
+			return false
+		}
+		if i == len(path)-1 {
+			return true
+		}
+		if document, ok = value.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return false
+}
+
+// ParseUpstreamKeys 解析上游API Key池（IMAGE_API_KEYS），格式为 id:key,id2:key2
+func ParseUpstreamKeys(value string) []UpstreamKeyConfig {
+	var keys []UpstreamKeyConfig
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		id, key, _ := strings.Cut(item, ":")
+		keys = append(keys, UpstreamKeyConfig{ID: strings.TrimSpace(id), Key: strings.TrimSpace(key)})
+	}
+	return keys
+}
+
+// FormatUpstreamKeys 将上游API Key池格式化为IMAGE_API_KEYS的格式
+func FormatUpstreamKeys(keys []UpstreamKeyConfig) string {
+	items := make([]string, len(keys))
+	for i, key := range keys {
+		items[i] = key.ID + ":" + key.Key
+	}
+	return strings.Join(items, ",")
+}
+
+// parseSigningKeys 解析签名密钥列表，格式为 id:secret,id2:secret2
+func parseSigningKeys(value string) []SigningKeyConfig {
+	var keys []SigningKeyConfig
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		id, secret, _ := strings.Cut(item, ":")
+		keys = append(keys, SigningKeyConfig{ID: strings.TrimSpace(id), Secret: strings.TrimSpace(secret)})
+	}
+	return keys
+}
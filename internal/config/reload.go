@@ -0,0 +1,200 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce 文件变化后等待的时间，合并编辑器保存时产生的多个事件
+const reloadDebounce = 500 * time.Millisecond
+
+// Change 两份配置之间变化的配置项
+type Change struct {
+	Path    string // 按json标签组成的路径，如rate_limit.tiers.pro.burst
+	Old     string // 旧值（JSON），密钥显示为<redacted>，不存在时为<unset>
+	New     string // 新值
+	Restart bool   // 修改需要重启才能生效
+}
+
+// String 实现fmt.Stringer
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Path, c.Old, c.New)
+}
+
+// Diff 比较两份配置，返回按路径排序的变化项
+func Diff(old, updated *Config) []Change {
+	var changes []Change
+	diffValue("", reflect.ValueOf(old).Elem(), reflect.ValueOf(updated).Elem(), false, false, &changes)
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+// diffValue 递归比较结构体和map，其余类型作为整体比较；hot和secret标签由子项继承
+func diffValue(path string, old, updated reflect.Value, hot, secret bool, changes *[]Change) {
+	if old.IsValid() && updated.IsValid() {
+		switch old.Kind() {
+		case reflect.Struct:
+			t := old.Type()
+			for i := 0; i < t.NumField(); i++ {
+				field := t.Field(i)
+				if !field.IsExported() {
+					continue
+				}
+				name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+				diffValue(joinPath(path, name), old.Field(i), updated.Field(i),
+					hot || field.Tag.Get("reload") == "hot", secret || field.Tag.Get("secret") == "true", changes)
+			}
+			return
+		case reflect.Map:
+			keys := make(map[string]reflect.Value)
+			for _, key := range append(old.MapKeys(), updated.MapKeys()...) {
+				keys[fmt.Sprint(key.Interface())] = key
+			}
+			for name, key := range keys {
+				diffValue(joinPath(path, name), old.MapIndex(key), updated.MapIndex(key), hot, secret, changes)
+			}
+			return
+		}
+	}
+
+	if old.IsValid() && updated.IsValid() && reflect.DeepEqual(old.Interface(), updated.Interface()) {
+		return
+	}
+	*changes = append(*changes, Change{
+		Path:    path,
+		Old:     formatValue(old, secret),
+		New:     formatValue(updated, secret),
+		Restart: !hot,
+	})
+}
+
+// joinPath 拼接配置项路径
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// formatValue 格式化配置项的值用于日志
+func formatValue(v reflect.Value, secret bool) string {
+	switch {
+	case !v.IsValid():
+		return "<unset>"
+	case secret && !v.IsZero():
+		return "<redacted>"
+	}
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return fmt.Sprint(v.Interface())
+	}
+	return string(data)
+}
+
+// Files 返回配置来源文件：配置文件、.env、各*_FILE文件、API密钥文件和加密密钥文件
+func (c *Config) Files() []string {
+	files := []string{".env"}
+	for _, file := range []string{
+		c.file,
+		c.Log.Redaction.File,
+		c.Models.File,
+		c.Storage.File,
+		c.RateLimit.File,
+		c.Usage.Quota.File,
+		c.Usage.Pricing.File,
+		c.Usage.Budget.File,
+		c.Auth.APIKeysFile,
+		c.Secrets.File,
+	} {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// Watch 收到SIGHUP或（启用WatchConfig时）配置来源文件变化时按相同的参数重新加载配置，交给apply处理；直到ctx结束
+// apply收到的配置已经通过验证，加载失败时收到错误；apply返回的配置用于确定之后监听的文件
+func Watch(ctx context.Context, args []string, current *Config, apply func(*Config, error) *Config) error {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	var events chan fsnotify.Event
+	var watchErrors chan error
+	var watcher *fsnotify.Watcher
+	if current.App.WatchConfig {
+		var err error
+		if watcher, err = fsnotify.NewWatcher(); err != nil {
+			return fmt.Errorf("failed to create file watcher: %w", err)
+		}
+		defer watcher.Close()
+		events, watchErrors = watcher.Events, watcher.Errors
+	}
+
+	// 监听文件所在的目录：编辑器和Kubernetes ConfigMap通过重命名替换文件，直接监听文件会丢失事件
+	watched := make(map[string]bool)
+	watch := func(cfg *Config) error {
+		if watcher == nil {
+			return nil
+		}
+		for _, file := range cfg.Files() {
+			path, err := filepath.Abs(file)
+			if err != nil {
+				return err
+			}
+			watched[path] = true
+			if dir := filepath.Dir(path); !watched[dir] {
+				if err := watcher.Add(dir); err != nil {
+					return fmt.Errorf("failed to watch %s: %w", dir, err)
+				}
+				watched[dir] = true
+			}
+		}
+		return nil
+	}
+	if err := watch(current); err != nil {
+		return err
+	}
+
+	debounce := time.NewTimer(0)
+	<-debounce.C
+	reload := func() {
+		if cfg := apply(Load(args)); cfg != nil {
+			if err := watch(cfg); err != nil {
+				apply(nil, err)
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-hangup:
+			debounce.Stop()
+			reload()
+		case event := <-events:
+			// ConfigMap更新时替换的是目录中的..data链接
+			name := filepath.Base(event.Name)
+			if watched[event.Name] || strings.HasPrefix(name, "..") {
+				debounce.Reset(reloadDebounce)
+			}
+		case <-debounce.C:
+			reload()
+		case err := <-watchErrors:
+			apply(nil, fmt.Errorf("file watcher: %w", err))
+		}
+	}
+}
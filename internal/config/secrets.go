@@ -0,0 +1,129 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"sia/internal/secrets"
+)
+
+// SecretProviders 返回密钥来源：环境变量、NAME_FILE指向的文件，以及配置了SECRETS_FILE时的加密密钥文件
+func (c *Config) SecretProviders() (secrets.Chain, error) {
+	providers := secrets.Chain{secrets.EnvProvider{}, secrets.FileProvider{}}
+	if c.Secrets.File == "" || c.Secrets.Key == "" {
+		return providers, nil
+	}
+
+	key, err := secrets.ParseKey(c.Secrets.Key)
+	if err != nil {
+		return providers, err
+	}
+	encrypted, err := secrets.NewEncryptedFileProvider(c.Secrets.File, key)
+	if err != nil {
+		return providers, fmt.Errorf("failed to load SECRETS_FILE: %w", err)
+	}
+	return append(providers, encrypted), nil
+}
+
+// resolveSecretsKey 读取加密密钥文件的加密密钥，加密密钥本身只能来自环境变量或文件
+func (c *Config) resolveSecretsKey(errs *[]error) {
+	base := secrets.Chain{secrets.EnvProvider{}, secrets.FileProvider{}}
+	if value, _, ok, err := base.Lookup(context.Background(), "SECRETS_KEY"); err != nil {
+		*errs = append(*errs, err)
+	} else if ok {
+		c.Secrets.Key = value
+	}
+}
+
+// resolveSecrets 从密钥来源读取带secret标签的配置项，找到时覆盖配置文件和环境变量中的值
+func (c *Config) resolveSecrets(errs *[]error) {
+	ctx := context.Background()
+
+	providers, err := c.SecretProviders()
+	if err != nil {
+		*errs = append(*errs, err)
+	}
+
+	lookupSecrets(ctx, reflect.ValueOf(c).Elem(), providers, errs)
+
+	value, _, ok, err := providers.Lookup(ctx, "IMAGE_API_KEYS")
+	if err != nil {
+		*errs = append(*errs, err)
+	} else if ok {
+		c.Image.APIKeys = ParseUpstreamKeys(value)
+	}
+
+	value, _, ok, err = providers.Lookup(ctx, "STORAGE_SIGNING_KEYS")
+	if err != nil {
+		*errs = append(*errs, err)
+	} else if ok {
+		c.Storage.SigningKeys = parseSigningKeys(value)
+	}
+}
+
+// ProviderKeysEnv 返回备用上游服务API Key池的密钥名，如backup-sg对应PROVIDER_BACKUP_SG_API_KEYS
+func ProviderKeysEnv(provider string) string {
+	name := strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, strings.ToUpper(provider))
+	return "PROVIDER_" + name + "_API_KEYS"
+}
+
+// resolveProviderKeys 从密钥来源读取备用上游服务的API Key池，格式同IMAGE_API_KEYS，找到时覆盖配置中的值
+// 在加载*_FILE之后调用，MODELS_FILE中的providers可以不包含密钥
+func (c *Config) resolveProviderKeys(errs *[]error) {
+	if len(c.Models.Providers) == 0 {
+		return
+	}
+
+	// 密钥来源的错误已在resolveSecrets中报告
+	providers, _ := c.SecretProviders()
+	for name, provider := range c.Models.Providers {
+		value, _, ok, err := providers.Lookup(context.Background(), ProviderKeysEnv(name))
+		if err != nil {
+			*errs = append(*errs, err)
+			continue
+		}
+		if ok {
+			provider.APIKeys = ParseUpstreamKeys(value)
+			c.Models.Providers[name] = provider
+		}
+	}
+}
+
+// lookupSecrets 按env标签查找带secret标签的配置项
+func lookupSecrets(ctx context.Context, v reflect.Value, providers secrets.Chain, errs *[]error) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := field.Tag.Get("env")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			if field.Type.Kind() == reflect.Struct {
+				lookupSecrets(ctx, v.Field(i), providers, errs)
+			}
+			continue
+		}
+		if field.Tag.Get("secret") != "true" {
+			continue
+		}
+
+		value, source, ok, err := providers.Lookup(ctx, name)
+		if err != nil {
+			*errs = append(*errs, fmt.Errorf("%s (%s): %w", name, source, err))
+			continue
+		}
+		if ok {
+			if err := setValue(v.Field(i), value); err != nil {
+				*errs = append(*errs, fmt.Errorf("invalid %s: %w", name, err))
+			}
+		}
+	}
+}
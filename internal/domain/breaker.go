@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 熔断器状态
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// ErrCircuitOpen 上游已熔断
+var ErrCircuitOpen = errors.New("upstream circuit breaker is open")
+
+// CircuitBreaker 上游熔断器
+// 连续失败达到阈值后熔断，冷却期结束后放行一个试探请求，成功则恢复
+type CircuitBreaker struct {
+	mutex     sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     string
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+// NewCircuitBreaker 创建熔断器，threshold为0时从不熔断
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     BreakerClosed,
+		now:       time.Now,
+	}
+}
+
+// Allow 判断是否放行请求
+func (b *CircuitBreaker) Allow() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.currentState() {
+	case BreakerClosed:
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return false
+	}
+}
+
+// Success 记录一次成功调用
+func (b *CircuitBreaker) Success() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure 记录一次失败调用
+func (b *CircuitBreaker) Failure() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.failures++
+	if b.probing || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+		b.probing = false
+	}
+}
+
+// Abort 放弃一次调用的结果（如调用方取消），半开状态下允许下一个试探请求
+func (b *CircuitBreaker) Abort() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.probing = false
+}
+
+// State 获取当前状态
+func (b *CircuitBreaker) State() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.currentState()
+}
+
+// currentState 计算当前状态，冷却期结束后转为半开（调用方需持有锁）
+func (b *CircuitBreaker) currentState() string {
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.cooldown {
+		b.state = BreakerHalfOpen
+	}
+	return b.state
+}
+
+var breakerStateDesc = prometheus.NewDesc(
+	"sia_upstream_circuit_state",
+	"Upstream circuit breaker state (1 for the current state).",
+	[]string{"state"}, nil,
+)
+
+// Describe 实现prometheus.Collector
+func (b *CircuitBreaker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- breakerStateDesc
+}
+
+// Collect 实现prometheus.Collector
+func (b *CircuitBreaker) Collect(ch chan<- prometheus.Metric) {
+	current := b.State()
+	for _, state := range []string{BreakerClosed, BreakerOpen, BreakerHalfOpen} {
+		value := 0.0
+		if state == current {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(breakerStateDesc, prometheus.GaugeValue, value, state)
+	}
+}
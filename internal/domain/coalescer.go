@@ -0,0 +1,205 @@
+package domain
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Coalescer 合并同时进行的相同请求：相同键的请求共享一次调用及其结果
+// 调用在独立的上下文中执行，单个调用方取消只会放弃等待，所有调用方都取消后才取消调用
+// 调用方为调用占用的资源（限流并发、配额和预算预留）至少有一份保持到调用结束，结果只由实际收到它的一个调用方结算
+type Coalescer struct {
+	mutex sync.Mutex
+	calls map[string]*inflightCall
+}
+
+// CoalesceHold 调用方为调用占用的资源
+type CoalesceHold struct {
+	// Settle 按调用方的身份结算结果（记录用量和费用），为nil时不结算
+	Settle func(*ImageGenerationResponse)
+	// Release 释放调用方占用的资源，为nil时无需释放
+	Release func()
+}
+
+// settle 结算结果
+func (h *CoalesceHold) settle(response *ImageGenerationResponse) {
+	if h.Settle != nil {
+		h.Settle(response)
+	}
+}
+
+// release 释放资源
+func (h *CoalesceHold) release() {
+	if h.Release != nil {
+		h.Release()
+	}
+}
+
+// inflightCall 进行中的调用
+type inflightCall struct {
+	done     chan struct{}
+	waiters  int
+	settled  bool          // 已有调用方结算了结果
+	held     *CoalesceHold // 放弃等待的调用方留下的资源，调用结束后释放
+	cancel   context.CancelFunc
+	response *ImageGenerationResponse
+	err      error
+}
+
+// NewCoalescer 创建请求合并器
+func NewCoalescer() *Coalescer {
+	return &Coalescer{calls: make(map[string]*inflightCall)}
+}
+
+// Do 执行fn，已有相同键的调用在进行时等待其结果；key为空时直接执行
+// 返回结果的副本，shared表示结果由其他调用方结算（本调用方不计费）
+// fn收到的上下文保留发起方的值（trace、请求ID、调用方身份），但不随发起方取消
+//
+// Do负责hold：第一个收到成功结果的调用方先结算再释放，其余调用方直接释放；
+// 调用方放弃等待时，第一份放弃的资源转交给调用，在调用结束后释放（调用成功但没有调用方收到结果时由其结算）
+func (c *Coalescer) Do(ctx context.Context, key string, hold CoalesceHold, fn func(context.Context) (*ImageGenerationResponse, error)) (response *ImageGenerationResponse, shared bool, err error) {
+	if key == "" {
+		defer hold.release()
+		response, err = fn(ctx)
+		if err == nil {
+			hold.settle(response)
+		}
+		return response, false, err
+	}
+
+	c.mutex.Lock()
+	call, joined := c.calls[key]
+	if joined {
+		call.waiters++
+	} else {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &inflightCall{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		c.calls[key] = call
+		go c.run(callCtx, key, call, fn)
+	}
+	c.mutex.Unlock()
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		if c.leave(key, call, &hold) {
+			return nil, joined, ctx.Err()
+		}
+	}
+
+	defer hold.release()
+	if call.err != nil {
+		return nil, joined, call.err
+	}
+
+	response = cloneResponse(call.response)
+	c.mutex.Lock()
+	owner := !call.settled
+	call.settled = true
+	c.mutex.Unlock()
+	if owner {
+		hold.settle(response)
+	}
+	return response, !owner, nil
+}
+
+// Inflight 返回进行中的调用数
+func (c *Coalescer) Inflight() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.calls)
+}
+
+// run 执行调用并通知所有等待方，之后释放转交给调用的资源
+func (c *Coalescer) run(ctx context.Context, key string, call *inflightCall, fn func(context.Context) (*ImageGenerationResponse, error)) {
+	defer call.cancel()
+	response, err := fn(ctx)
+
+	c.mutex.Lock()
+	call.response, call.err = response, err
+	if c.calls[key] == call {
+		delete(c.calls, key)
+	}
+	// 所有调用方都已放弃等待，结果没有人收到，由转交资源的调用方结算
+	orphaned := call.waiters == 0 && err == nil
+	if orphaned {
+		call.settled = true
+	}
+	held := call.held
+	close(call.done)
+	c.mutex.Unlock()
+
+	if held == nil {
+		return
+	}
+	if orphaned {
+		held.settle(cloneResponse(response))
+	}
+	held.release()
+}
+
+// leave 调用方放弃等待：第一份资源转交给调用，其余直接释放；最后一个等待方离开时取消调用，之后的相同请求重新发起调用
+// 调用已经结束时返回false，调用方应当收下结果
+func (c *Coalescer) leave(key string, call *inflightCall, hold *CoalesceHold) bool {
+	c.mutex.Lock()
+	select {
+	case <-call.done:
+		c.mutex.Unlock()
+		return false
+	default:
+	}
+
+	donated := call.held == nil
+	if donated {
+		call.held = hold
+	}
+	call.waiters--
+	if call.waiters == 0 {
+		call.cancel()
+		if c.calls[key] == call {
+			delete(c.calls, key)
+		}
+	}
+	c.mutex.Unlock()
+
+	if !donated {
+		hold.release()
+	}
+	return true
+}
+
+// cloneResponse 复制响应，调用方可以各自修改图片、用量和费用
+func cloneResponse(response *ImageGenerationResponse) *ImageGenerationResponse {
+	cloned := *response
+	cloned.Data = make([]ImageData, len(response.Data))
+	for i, image := range response.Data {
+		cloned.Data[i] = image
+		if image.Stored != nil {
+			stored := *image.Stored
+			cloned.Data[i].Stored = &stored
+		}
+	}
+	if response.Cost != nil {
+		cost := *response.Cost
+		cloned.Cost = &cost
+	}
+	return &cloned
+}
+
+var inflightCallsDesc = prometheus.NewDesc(
+	"sia_coalescer_inflight_calls",
+	"Upstream generation calls in flight that identical requests can join.",
+	nil, nil,
+)
+
+// Describe 实现prometheus.Collector
+func (c *Coalescer) Describe(ch chan<- *prometheus.Desc) {
+	ch <- inflightCallsDesc
+}
+
+// Collect 实现prometheus.Collector
+func (c *Coalescer) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(inflightCallsDesc, prometheus.GaugeValue, float64(c.Inflight()))
+}
@@ -0,0 +1,351 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testHold 记录结算和释放次数的调用方资源
+type testHold struct {
+	settled  atomic.Int32
+	released atomic.Int32
+	// callDone 调用是否已经结束，early记录是否在调用结束前释放
+	callDone *atomic.Bool
+	early    atomic.Bool
+}
+
+func (h *testHold) hold() CoalesceHold {
+	return CoalesceHold{
+		Settle: func(response *ImageGenerationResponse) {
+			h.settled.Add(1)
+			response.Cost = &Cost{Actual: 1}
+		},
+		Release: func() {
+			if h.callDone != nil && !h.callDone.Load() {
+				h.early.Store(true)
+			}
+			h.released.Add(1)
+		},
+	}
+}
+
+// blockingCall 返回阻塞到release关闭的调用，started在调用开始时关闭
+func blockingCall(calls *atomic.Int32, started, release chan struct{}, done *atomic.Bool) func(context.Context) (*ImageGenerationResponse, error) {
+	return func(ctx context.Context) (*ImageGenerationResponse, error) {
+		calls.Add(1)
+		close(started)
+		select {
+		case <-release:
+		case <-ctx.Done():
+			done.Store(true)
+			return nil, ctx.Err()
+		}
+		done.Store(true)
+		return &ImageGenerationResponse{
+			ID:   "resp",
+			Data: []ImageData{{URL: "https://example.com/0.png", Stored: &StoredImage{Key: "a.png"}}},
+		}, nil
+	}
+}
+
+// waitFor 等待条件成立
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// result 一个调用方的返回值
+type result struct {
+	response *ImageGenerationResponse
+	shared   bool
+	err      error
+}
+
+func TestCoalescerSharesOneCall(t *testing.T) {
+	c := NewCoalescer()
+	var calls atomic.Int32
+	var done atomic.Bool
+	started, release := make(chan struct{}), make(chan struct{})
+	fn := blockingCall(&calls, started, release, &done)
+
+	const callers = 5
+	holds := make([]*testHold, callers)
+	results := make(chan result, callers)
+	for i := range holds {
+		holds[i] = &testHold{callDone: &done}
+		go func(hold *testHold) {
+			response, shared, err := c.Do(context.Background(), "key", hold.hold(), fn)
+			results <- result{response, shared, err}
+		}(holds[i])
+		if i == 0 {
+			<-started
+		}
+	}
+
+	// 等待所有调用方加入后再结束调用
+	waitFor(t, func() bool {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+		call := c.calls["key"]
+		return call != nil && call.waiters == callers
+	})
+	if got := c.Inflight(); got != 1 {
+		t.Fatalf("Inflight() = %d, want 1", got)
+	}
+	close(release)
+
+	var owners int
+	var responses []*ImageGenerationResponse
+	for i := 0; i < callers; i++ {
+		r := <-results
+		if r.err != nil {
+			t.Fatalf("Do() error = %v", r.err)
+		}
+		if !r.shared {
+			owners++
+		}
+		responses = append(responses, r.response)
+	}
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("calls = %d, want 1", got)
+	}
+	if owners != 1 {
+		t.Errorf("owners = %d, want exactly one caller to settle", owners)
+	}
+
+	var settled, released int32
+	for _, hold := range holds {
+		settled += hold.settled.Load()
+		released += hold.released.Load()
+		if hold.early.Load() {
+			t.Error("a caller released its hold before the call finished")
+		}
+	}
+	if settled != 1 || released != callers {
+		t.Errorf("settled = %d, released = %d, want 1 and %d", settled, released, callers)
+	}
+
+	// 每个调用方拿到各自的副本
+	responses[0].Data[0].Stored.Key = "changed.png"
+	responses[0].Data[0].URL = "changed"
+	for _, response := range responses[1:] {
+		if response.Data[0].Stored.Key != "a.png" || response.Data[0].URL != "https://example.com/0.png" {
+			t.Fatalf("responses share state: %+v", response.Data[0])
+		}
+	}
+	if c.Inflight() != 0 {
+		t.Errorf("Inflight() = %d after the call finished", c.Inflight())
+	}
+}
+
+func TestCoalescerCallerCancel(t *testing.T) {
+	tests := []struct {
+		name string
+		// cancelInitiator 发起方取消，joiner继续等待
+		cancelInitiator bool
+		// cancelJoiner 等待方也取消
+		cancelJoiner bool
+	}{
+		{name: "initiator leaves", cancelInitiator: true},
+		{name: "joiner leaves", cancelJoiner: true},
+		{name: "everyone leaves", cancelInitiator: true, cancelJoiner: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCoalescer()
+			var calls atomic.Int32
+			var done atomic.Bool
+			started, release := make(chan struct{}), make(chan struct{})
+			fn := blockingCall(&calls, started, release, &done)
+
+			initiatorCtx, cancelInitiator := context.WithCancel(context.Background())
+			joinerCtx, cancelJoiner := context.WithCancel(context.Background())
+			defer cancelInitiator()
+			defer cancelJoiner()
+
+			initiator, joiner := &testHold{callDone: &done}, &testHold{callDone: &done}
+			initiatorResult, joinerResult := make(chan result, 1), make(chan result, 1)
+			go func() {
+				response, shared, err := c.Do(initiatorCtx, "key", initiator.hold(), fn)
+				initiatorResult <- result{response, shared, err}
+			}()
+			<-started
+			go func() {
+				response, shared, err := c.Do(joinerCtx, "key", joiner.hold(), fn)
+				joinerResult <- result{response, shared, err}
+			}()
+			waitFor(t, func() bool {
+				c.mutex.Lock()
+				defer c.mutex.Unlock()
+				return c.calls["key"] != nil && c.calls["key"].waiters == 2
+			})
+
+			if tt.cancelInitiator {
+				cancelInitiator()
+				if r := <-initiatorResult; !errors.Is(r.err, context.Canceled) {
+					t.Fatalf("initiator error = %v, want context.Canceled", r.err)
+				}
+			}
+			if tt.cancelJoiner {
+				cancelJoiner()
+				if r := <-joinerResult; !errors.Is(r.err, context.Canceled) {
+					t.Fatalf("joiner error = %v, want context.Canceled", r.err)
+				}
+			}
+
+			if tt.cancelInitiator && tt.cancelJoiner {
+				// 最后一个等待方离开后取消调用，资源在调用结束后才释放
+				waitFor(t, func() bool { return done.Load() })
+				waitFor(t, func() bool { return initiator.released.Load() == 1 && joiner.released.Load() == 1 })
+				if initiator.early.Load() && joiner.early.Load() {
+					t.Error("no hold was kept until the call finished")
+				}
+				if initiator.settled.Load()+joiner.settled.Load() != 0 {
+					t.Error("a cancelled call was settled")
+				}
+				return
+			}
+
+			// 还有等待方时调用继续，离开的调用方的资源保留到调用结束
+			if done.Load() {
+				t.Fatal("call cancelled while a caller was still waiting")
+			}
+			close(release)
+
+			var remaining chan result
+			var receiver, leaver *testHold
+			if tt.cancelInitiator {
+				remaining, receiver, leaver = joinerResult, joiner, initiator
+			} else {
+				remaining, receiver, leaver = initiatorResult, initiator, joiner
+			}
+			r := <-remaining
+			if r.err != nil || r.shared {
+				t.Fatalf("remaining caller = %+v, want an unshared result", r)
+			}
+			if r.response.Cost == nil || r.response.Cost.Actual != 1 {
+				t.Errorf("remaining caller was not settled: %+v", r.response.Cost)
+			}
+
+			// 收到结果的调用方结算，离开的调用方不结算
+			waitFor(t, func() bool { return leaver.released.Load() == 1 })
+			if receiver.settled.Load() != 1 || leaver.settled.Load() != 0 {
+				t.Errorf("settled receiver = %d, leaver = %d", receiver.settled.Load(), leaver.settled.Load())
+			}
+			if leaver.early.Load() {
+				t.Error("the leaving caller's hold was released before the call finished")
+			}
+			if got := calls.Load(); got != 1 {
+				t.Errorf("calls = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestCoalescerOrphanedResultIsSettled(t *testing.T) {
+	c := NewCoalescer()
+	var done atomic.Bool
+	started, finished := make(chan struct{}), make(chan struct{})
+	// 调用忽略取消，所有调用方离开后仍然成功
+	fn := func(ctx context.Context) (*ImageGenerationResponse, error) {
+		close(started)
+		<-ctx.Done()
+		done.Store(true)
+		defer close(finished)
+		return &ImageGenerationResponse{ID: "resp"}, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hold := &testHold{callDone: &done}
+	go func() {
+		<-started
+		cancel()
+	}()
+	if _, _, err := c.Do(ctx, "key", hold.hold(), fn); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Do() error = %v, want context.Canceled", err)
+	}
+
+	<-finished
+	waitFor(t, func() bool { return hold.released.Load() == 1 })
+	if hold.settled.Load() != 1 {
+		t.Errorf("settled = %d, want the orphaned result to be settled once", hold.settled.Load())
+	}
+}
+
+func TestCoalescerErrorsAndEmptyKey(t *testing.T) {
+	c := NewCoalescer()
+	failure := errors.New("upstream failed")
+
+	tests := []struct {
+		name        string
+		key         string
+		err         error
+		wantSettled int32
+	}{
+		{name: "uncoalesced success", key: "", wantSettled: 1},
+		{name: "uncoalesced failure", key: "", err: failure},
+		{name: "coalesced failure", key: "key", err: failure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hold := &testHold{}
+			_, shared, err := c.Do(context.Background(), tt.key, hold.hold(), func(context.Context) (*ImageGenerationResponse, error) {
+				if tt.err != nil {
+					return nil, tt.err
+				}
+				return &ImageGenerationResponse{ID: "resp"}, nil
+			})
+			if !errors.Is(err, tt.err) || shared {
+				t.Fatalf("Do() = shared %v, error %v", shared, err)
+			}
+			if hold.settled.Load() != tt.wantSettled || hold.released.Load() != 1 {
+				t.Errorf("settled = %d, released = %d", hold.settled.Load(), hold.released.Load())
+			}
+		})
+	}
+}
+
+func TestCoalescerRetriesAfterLastWaiterLeaves(t *testing.T) {
+	c := NewCoalescer()
+	var calls atomic.Int32
+	var wg sync.WaitGroup
+
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.Do(ctx, "key", CoalesceHold{}, func(callCtx context.Context) (*ImageGenerationResponse, error) {
+			calls.Add(1)
+			close(started)
+			<-callCtx.Done()
+			return nil, callCtx.Err()
+		})
+	}()
+	<-started
+	cancel()
+	wg.Wait()
+
+	// 被取消的调用不再被合并，之后的相同请求重新发起调用
+	response, shared, err := c.Do(context.Background(), "key", CoalesceHold{}, func(context.Context) (*ImageGenerationResponse, error) {
+		calls.Add(1)
+		return &ImageGenerationResponse{ID: "second"}, nil
+	})
+	if err != nil || shared || response.ID != "second" {
+		t.Fatalf("Do() after cancel = %+v, %v, %v", response, shared, err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("calls = %d, want 2", got)
+	}
+}
@@ -4,60 +4,163 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"sia/internal/metrics"
+	"sia/internal/tracing"
 )
 
 // ImageClient 图片生成API客户端
 type ImageClient struct {
-	config     *ImageClientConfig
+	mutex      sync.Mutex // 串行化配置更新
+	config     atomic.Pointer[ImageClientConfig]
+	keys       *KeyPool
 	httpClient *http.Client
+	breaker    *CircuitBreaker
+}
+
+// StatusError 上游返回的非200响应
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+// Error 实现error
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("API request failed with status %d: %s", e.StatusCode, e.Message)
 }
 
 // ImageClientConfig 图片客户端配置
 type ImageClientConfig struct {
-	APIKey      string
+	Keys        []UpstreamKey // 上游API Key池
 	BaseURL     string
 	Model       string
 	DefaultSize string
 	Timeout     int
 	MaxRetries  int
+
+	BreakerFailures int
+	BreakerCooldown int
+
+	KeySelection         string // round_robin, least_used
+	KeyRateLimitCooldown int    // 429后暂停密钥的时间（秒）
+	KeyAuthCooldown      int    // 401/403后暂停密钥的时间（秒）
+}
+
+// keyPoolConfig 转换API Key池配置
+func (c *ImageClientConfig) keyPoolConfig() KeyPoolConfig {
+	return KeyPoolConfig{
+		Keys:              c.Keys,
+		Selection:         c.KeySelection,
+		RateLimitCooldown: time.Duration(c.KeyRateLimitCooldown) * time.Second,
+		AuthCooldown:      time.Duration(c.KeyAuthCooldown) * time.Second,
+	}
 }
 
 // NewImageClient 创建新的图片生成客户端
 func NewImageClient(config *ImageClientConfig) *ImageClient {
-	return &ImageClient{
-		config: config,
+	client := &ImageClient{
 		httpClient: &http.Client{
 			Timeout: time.Duration(config.Timeout) * time.Second,
 		},
+		keys:    NewKeyPool(config.keyPoolConfig()),
+		breaker: NewCircuitBreaker(config.BreakerFailures, time.Duration(config.BreakerCooldown)*time.Second),
 	}
+	client.config.Store(config)
+	return client
 }
 
-// GenerateImage 生成图片
-func (c *ImageClient) GenerateImage(ctx context.Context, req *ImageGenerationRequest) (*ImageGenerationResponse, error) {
-	// 设置默认值
-	if req.Model == "" {
-		req.Model = c.config.Model
-	}
-	if req.ResponseFormat == "" {
-		req.ResponseFormat = "url"
+// SetConfig 替换客户端配置（如轮换API Key、修改默认模型），之后发起的请求使用新配置
+// 超时和熔断参数只在创建时生效
+func (c *ImageClient) SetConfig(config *ImageClientConfig) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.config.Store(config)
+	c.keys.SetConfig(config.keyPoolConfig())
+}
+
+// SetKeys 轮换API Key池，下一个请求开始使用新密钥
+func (c *ImageClient) SetKeys(keys []UpstreamKey) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	updated := *c.config.Load()
+	updated.Keys = keys
+	c.config.Store(&updated)
+	c.keys.SetConfig(updated.keyPoolConfig())
+}
+
+// Breaker 获取上游熔断器
+func (c *ImageClient) Breaker() *CircuitBreaker {
+	return c.breaker
+}
+
+// Keys 获取上游API Key池
+func (c *ImageClient) Keys() *KeyPool {
+	return c.keys
+}
+
+// Probe 轻量探测上游是否可达以及API Key是否有效
+// 只要求上游有响应，401/403及5xx视为失败
+func (c *ImageClient) Probe(ctx context.Context, path string) error {
+	config := c.config.Load()
+	lease, err := c.keys.Acquire()
+	if err != nil {
+		return err
 	}
-	if req.Size == "" {
-		req.Size = c.config.DefaultSize
+	defer lease.Release()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, config.BaseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create probe request: %w", err)
 	}
-	if req.SequentialImageGeneration == "" {
-		req.SequentialImageGeneration = "auto"
+	httpReq.Header.Set("Authorization", "Bearer "+lease.Key())
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("upstream unreachable: %w", err)
 	}
-	if req.SequentialImageGenerationOptions == nil {
-		req.SequentialImageGenerationOptions = &SequentialImageGenerationOptions{
-			MaxImages: 3,
-		}
+	defer resp.Body.Close()
+	lease.Report(resp.StatusCode, parseRetryAfter(resp.Header.Get("Retry-After")))
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("upstream rejected API key %s with status %d", lease.ID(), resp.StatusCode)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return fmt.Errorf("upstream returned status %d", resp.StatusCode)
 	}
+	return nil
+}
+
+// GenerateImage 生成图片
+func (c *ImageClient) GenerateImage(ctx context.Context, req *ImageGenerationRequest) (*ImageGenerationResponse, error) {
+	return c.GenerateImageStream(ctx, req, nil)
+}
+
+// GenerateImageStream 生成图片，上游每返回一张图片就调用一次onImage（可以为nil）
+func (c *ImageClient) GenerateImageStream(ctx context.Context, req *ImageGenerationRequest, onImage func(ImageData)) (*ImageGenerationResponse, error) {
+	config := c.config.Load()
+	c.applyDefaults(req)
 
 	// 序列化请求
 	reqBody, err := json.Marshal(req)
@@ -65,43 +168,209 @@ func (c *ImageClient) GenerateImage(ctx context.Context, req *ImageGenerationReq
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// 创建HTTP请求
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.config.BaseURL+"/api/v3/images/generations", bytes.NewBuffer(reqBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+	// 上游调用span，覆盖POST请求与SSE流的读取
+	url := config.BaseURL + "/api/v3/images/generations"
+	ctx, span := tracing.Start(ctx, "upstream.generate",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodPost,
+			semconv.URLFull(url),
+			attribute.String("image.model", req.Model),
+			attribute.String("image.size", req.Size),
+		),
+	)
+	defer span.End()
 
-	// 设置请求头
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	// 熔断时直接失败，不再请求上游
+	if !c.breaker.Allow() {
+		observeUpstream(req.Model, "circuit_open", time.Now(), false)
+		failSpan(span, ErrCircuitOpen)
+		return nil, ErrCircuitOpen
+	}
 
 	// 发送请求
-	resp, err := c.httpClient.Do(httpReq)
+	start := time.Now()
+	resp, lease, err := c.send(ctx, span, url, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		statusLabel := "error"
+		switch {
+		case errors.Is(err, ErrNoUpstreamKey):
+			// 没有可用的密钥不代表上游故障
+			statusLabel = "no_key"
+			c.breaker.Abort()
+		case ctx.Err() != nil:
+			c.breaker.Abort()
+		default:
+			c.breaker.Failure()
+		}
+		observeUpstream(req.Model, statusLabel, start, false)
+		failSpan(span, err)
+		return nil, err
 	}
+	defer lease.Release()
 	defer resp.Body.Close()
+	statusLabel := strconv.Itoa(resp.StatusCode)
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	c.recordBreaker(resp.StatusCode)
 
 	// 检查HTTP状态码
 	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(respBody))
+		observeUpstream(req.Model, statusLabel, start, false)
+		err := &StatusError{StatusCode: resp.StatusCode, Message: upstreamErrorMessage(resp.Body)}
+		failSpan(span, err)
+		return nil, err
 	}
 
 	// 解析SSE流式响应
-	imageResp, err := c.parseSSEResponse(resp.Body)
+	imageResp, err := c.parseSSEResponse(ctx, req.Model, resp.Body, onImage)
+	observeUpstream(req.Model, statusLabel, start, err == nil)
 	if err != nil {
+		failSpan(span, err)
 		return nil, fmt.Errorf("failed to parse SSE response: %w", err)
 	}
+	span.SetAttributes(attribute.Int("image.count", len(imageResp.Data)))
+
+	metrics.ImagesGenerated.WithLabelValues(req.Model).Add(float64(len(imageResp.Data)))
 
 	return imageResp, nil
 }
 
+// send 发送请求，返回响应和所用的密钥
+// 密钥被限流或拒绝时暂停该密钥并换用下一个可用的密钥重试，每次请求最多尝试池中密钥数量次
+func (c *ImageClient) send(ctx context.Context, span trace.Span, url string, body []byte) (*http.Response, *KeyLease, error) {
+	for attempt := 1; ; attempt++ {
+		lease, err := c.keys.Acquire()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// 创建HTTP请求，设置请求头并注入trace上下文
+		httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			lease.Release()
+			return nil, nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Authorization", "Bearer "+lease.Key())
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(httpReq.Header))
+
+		span.SetAttributes(attribute.String("upstream.key", lease.ID()), attribute.Int("upstream.attempts", attempt))
+		resp, err := c.httpClient.Do(httpReq)
+		if err != nil {
+			if ctx.Err() == nil {
+				lease.Report(0, 0)
+			}
+			lease.Release()
+			return nil, nil, fmt.Errorf("failed to send request: %w", err)
+		}
+		lease.Report(resp.StatusCode, parseRetryAfter(resp.Header.Get("Retry-After")))
+
+		if !keyRejected(resp.StatusCode) || attempt >= c.keys.Len() || c.keys.Available() == 0 {
+			return resp, lease, nil
+		}
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+		resp.Body.Close()
+		lease.Release()
+	}
+}
+
+// keyRejected 判断上游是否因为密钥被限流或无效而拒绝了请求
+func keyRejected(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden
+}
+
+// parseRetryAfter 解析Retry-After头（秒数或HTTP日期），无效时返回0
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+// applyDefaults 设置请求的默认值
+func (c *ImageClient) applyDefaults(req *ImageGenerationRequest) {
+	config := c.config.Load()
+	if req.Model == "" {
+		req.Model = config.Model
+	}
+	if req.ResponseFormat == "" {
+		req.ResponseFormat = "url"
+	}
+	if req.Size == "" {
+		req.Size = config.DefaultSize
+	}
+	if req.SequentialImageGeneration == "" {
+		req.SequentialImageGeneration = "auto"
+	}
+	if req.SequentialImageGenerationOptions == nil {
+		req.SequentialImageGenerationOptions = &SequentialImageGenerationOptions{
+			MaxImages: 3,
+		}
+	}
+}
+
+// RequestKey 返回请求的规范哈希：按上游实际收到的请求（补全默认值）计算，与流式传输方式无关
+// scope用于隔离不同调用方（如租户），相同scope下生成参数相同的请求得到相同的键
+func (c *ImageClient) RequestKey(scope string, req *ImageGenerationRequest) string {
+	canonical := *req
+	if req.SequentialImageGenerationOptions != nil {
+		options := *req.SequentialImageGenerationOptions
+		canonical.SequentialImageGenerationOptions = &options
+	}
+	c.applyDefaults(&canonical)
+	canonical.Stream = false
+
+	// 结构体字段顺序固定，json.Marshal的结果是确定的
+	data, _ := json.Marshal(&canonical)
+	hash := sha256.New()
+	hash.Write([]byte(scope))
+	hash.Write([]byte{0})
+	hash.Write(data)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// recordBreaker 按上游状态码更新熔断器，只有鉴权失败和服务端错误计为失败
+func (c *ImageClient) recordBreaker(statusCode int) {
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden || statusCode >= http.StatusInternalServerError:
+		c.breaker.Failure()
+	default:
+		c.breaker.Success()
+	}
+}
+
+// failSpan 将span标记为失败
+func failSpan(span trace.Span, err error) {
+	span.RecordError(err)
+	span.SetStatus(otelcodes.Error, err.Error())
+}
+
+// observeUpstream 记录一次上游调用的请求数、耗时和失败数
+func observeUpstream(model, status string, start time.Time, ok bool) {
+	metrics.UpstreamRequests.WithLabelValues(model, status).Inc()
+	metrics.UpstreamDuration.WithLabelValues(model, status).Observe(time.Since(start).Seconds())
+	if !ok {
+		metrics.UpstreamErrors.WithLabelValues(model, status).Inc()
+	}
+}
+
+// maxSSELineSize SSE单行的最大大小：b64_json格式的事件在一行中包含整张图片的base64编码
+const maxSSELineSize = 64 << 20
+
 // parseSSEResponse 解析SSE流式响应
-func (c *ImageClient) parseSSEResponse(body io.ReadCloser) (*ImageGenerationResponse, error) {
+// 每个事件对应一个span，起点为上一个事件的结束时间，便于观察事件之间的等待
+func (c *ImageClient) parseSSEResponse(ctx context.Context, model string, body io.ReadCloser, onImage func(ImageData)) (*ImageGenerationResponse, error) {
 	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxSSELineSize)
 	var imageResp ImageGenerationResponse
 	var images []ImageData
+	lastEvent := time.Now()
 
 	for scanner.Scan() {
 		line := strings.TrimSpace(scanner.Text())
@@ -115,29 +384,43 @@ func (c *ImageClient) parseSSEResponse(body io.ReadCloser) (*ImageGenerationResp
 		if strings.HasPrefix(line, "data: ") {
 			jsonData := strings.TrimPrefix(line, "data: ")
 
+			_, eventSpan := tracing.Start(ctx, "upstream.sse_event", trace.WithTimestamp(lastEvent))
+			lastEvent = time.Now()
+
 			// 解析JSON数据
 			var eventData map[string]interface{}
 			if err := json.Unmarshal([]byte(jsonData), &eventData); err != nil {
+				metrics.SSEParseErrors.WithLabelValues(model).Inc()
+				failSpan(eventSpan, err)
+				eventSpan.End()
 				continue // 跳过无法解析的行
 			}
 
 			// 处理不同类型的事件
 			eventType, ok := eventData["type"].(string)
+			eventSpan.SetAttributes(attribute.String("sse.event_type", eventType))
+			eventSpan.End()
 			if !ok {
 				continue
 			}
 
 			switch eventType {
 			case "image_generation.partial_succeeded":
-				// 提取图片信息
-				if url, ok := eventData["url"].(string); ok {
+				// 提取图片信息（response_format为b64_json时上游返回b64_json而不是url）
+				url, hasURL := eventData["url"].(string)
+				b64, hasB64 := eventData["b64_json"].(string)
+				if hasURL || hasB64 {
 					imageData := ImageData{
-						URL: url,
+						URL:     url,
+						B64JSON: b64,
 					}
 					if revisedPrompt, ok := eventData["revised_prompt"].(string); ok {
 						imageData.RevisedPrompt = revisedPrompt
 					}
 					images = append(images, imageData)
+					if onImage != nil {
+						onImage(imageData)
+					}
 				}
 
 				// 设置基本响应信息
@@ -159,10 +442,10 @@ func (c *ImageClient) parseSSEResponse(body io.ReadCloser) (*ImageGenerationResp
 				if usageData, ok := eventData["usage"].(map[string]interface{}); ok {
 					var usage Usage
 					if generatedImages, ok := usageData["generated_images"].(float64); ok {
-						usage.PromptTokens = int(generatedImages)
+						usage.GeneratedImages = int(generatedImages)
 					}
 					if outputTokens, ok := usageData["output_tokens"].(float64); ok {
-						usage.CompletionTokens = int(outputTokens)
+						usage.OutputTokens = int(outputTokens)
 					}
 					if totalTokens, ok := usageData["total_tokens"].(float64); ok {
 						usage.TotalTokens = int(totalTokens)
@@ -187,3 +470,27 @@ func (c *ImageClient) parseSSEResponse(body io.ReadCloser) (*ImageGenerationResp
 
 	return &imageResp, nil
 }
+
+// maxErrorBodySize 错误信息中保留的上游响应体最大字节数
+const maxErrorBodySize = 512
+
+// upstreamErrorMessage 提取上游错误信息
+// 优先使用{"error":{"code","message"}}中的字段，否则返回截断后的响应体，避免把完整响应体（可能回显提示词）带入错误和日志
+func upstreamErrorMessage(body io.Reader) string {
+	data, _ := io.ReadAll(io.LimitReader(body, 64*1024))
+
+	var errResp struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(data, &errResp) == nil && (errResp.Error.Code != "" || errResp.Error.Message != "") {
+		data = []byte(strings.TrimSpace(errResp.Error.Code + " " + errResp.Error.Message))
+	}
+
+	if len(data) > maxErrorBodySize {
+		return strings.ToValidUTF8(string(data[:maxErrorBodySize]), "") + "...(truncated)"
+	}
+	return string(data)
+}
@@ -0,0 +1,309 @@
+package domain
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 上游API Key选择策略
+const (
+	KeySelectionRoundRobin = "round_robin"
+	KeySelectionLeastUsed  = "least_used"
+)
+
+// 上游API Key状态
+const (
+	KeyActive  = "active"
+	KeyBenched = "benched"
+)
+
+// 上游API Key请求结果
+const (
+	keyResultSuccess     = "success"
+	keyResultRateLimited = "rate_limited"
+	keyResultRejected    = "rejected"
+	keyResultError       = "error"
+)
+
+// ErrNoUpstreamKey 所有上游API Key都被暂停
+var ErrNoUpstreamKey = errors.New("all upstream API keys are benched")
+
+// UpstreamKey 上游API Key，ID用于日志、指标和管理端点，不暴露密钥本身
+type UpstreamKey struct {
+	ID  string
+	Key string
+}
+
+// KeyPoolConfig API Key池配置
+type KeyPoolConfig struct {
+	Keys              []UpstreamKey
+	Selection         string        // round_robin, least_used
+	RateLimitCooldown time.Duration // 429后暂停的时间，上游返回Retry-After时以其为准，0表示不暂停
+	AuthCooldown      time.Duration // 401/403后暂停的时间，0表示不暂停
+}
+
+// KeyPool 上游API Key池
+// 按策略把请求分散到多个密钥上；密钥被限流（429）或被拒绝（401/403）后暂停一段时间，期间不再分配
+type KeyPool struct {
+	mutex  sync.Mutex
+	config KeyPoolConfig
+	keys   []*pooledKey
+	next   int
+	now    func() time.Time
+}
+
+// pooledKey 密钥及其使用统计
+type pooledKey struct {
+	UpstreamKey
+	inflight     int
+	results      map[string]uint64
+	lastUsed     time.Time
+	benchedUntil time.Time
+	benchReason  string
+}
+
+// KeyStatus 密钥的使用情况和健康状态
+type KeyStatus struct {
+	ID           string            `json:"id"`
+	State        string            `json:"state"`
+	BenchedUntil *time.Time        `json:"benched_until,omitempty"`
+	BenchReason  string            `json:"bench_reason,omitempty"`
+	Inflight     int               `json:"inflight"`
+	Requests     map[string]uint64 `json:"requests"`
+	LastUsed     *time.Time        `json:"last_used,omitempty"`
+}
+
+// NewKeyPool 创建API Key池
+func NewKeyPool(config KeyPoolConfig) *KeyPool {
+	p := &KeyPool{now: time.Now}
+	p.SetConfig(config)
+	return p
+}
+
+// SetConfig 替换密钥和策略；ID与密钥都不变的密钥保留统计和暂停状态，密钥被轮换时解除暂停
+func (p *KeyPool) SetConfig(config KeyPoolConfig) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	previous := make(map[string]*pooledKey, len(p.keys))
+	for _, key := range p.keys {
+		previous[key.ID] = key
+	}
+
+	keys := make([]*pooledKey, len(config.Keys))
+	for i, upstreamKey := range config.Keys {
+		key, ok := previous[upstreamKey.ID]
+		switch {
+		case !ok:
+			key = &pooledKey{UpstreamKey: upstreamKey, results: make(map[string]uint64)}
+		case key.Key != upstreamKey.Key:
+			key.Key = upstreamKey.Key
+			key.benchedUntil, key.benchReason = time.Time{}, ""
+		}
+		keys[i] = key
+	}
+
+	p.config, p.keys = config, keys
+	if p.next >= len(keys) {
+		p.next = 0
+	}
+}
+
+// Len 密钥数量
+func (p *KeyPool) Len() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return len(p.keys)
+}
+
+// Available 当前可用（未暂停）的密钥数量
+func (p *KeyPool) Available() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	available := 0
+	for _, key := range p.keys {
+		if !key.benched(now) {
+			available++
+		}
+	}
+	return available
+}
+
+// Acquire 按策略分配一个可用的密钥，使用完毕后需要调用Release
+func (p *KeyPool) Acquire() (*KeyLease, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	selected := -1
+	for offset := range p.keys {
+		i := (p.next + offset) % len(p.keys)
+		key := p.keys[i]
+		if key.benched(now) {
+			continue
+		}
+		if p.config.Selection != KeySelectionLeastUsed {
+			selected = i
+			break
+		}
+		if selected < 0 || key.inflight < p.keys[selected].inflight ||
+			(key.inflight == p.keys[selected].inflight && key.lastUsed.Before(p.keys[selected].lastUsed)) {
+			selected = i
+		}
+	}
+	if selected < 0 {
+		return nil, ErrNoUpstreamKey
+	}
+
+	key := p.keys[selected]
+	key.inflight++
+	key.lastUsed = now
+	p.next = (selected + 1) % len(p.keys)
+	// 密钥值在持有锁时复制，之后SetConfig轮换密钥不影响已分配的请求
+	return &KeyLease{pool: p, key: key, id: key.ID, value: key.Key}, nil
+}
+
+// Status 返回全部密钥的使用情况，按配置顺序排列
+func (p *KeyPool) Status() []KeyStatus {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.now()
+	statuses := make([]KeyStatus, len(p.keys))
+	for i, key := range p.keys {
+		status := KeyStatus{
+			ID:       key.ID,
+			State:    KeyActive,
+			Inflight: key.inflight,
+			Requests: make(map[string]uint64, len(key.results)),
+		}
+		for result, count := range key.results {
+			status.Requests[result] = count
+		}
+		if key.benched(now) {
+			until := key.benchedUntil
+			status.State, status.BenchedUntil, status.BenchReason = KeyBenched, &until, key.benchReason
+		}
+		if !key.lastUsed.IsZero() {
+			lastUsed := key.lastUsed
+			status.LastUsed = &lastUsed
+		}
+		statuses[i] = status
+	}
+	return statuses
+}
+
+// benched 判断密钥是否处于暂停期（调用方需持有锁）
+func (k *pooledKey) benched(now time.Time) bool {
+	return now.Before(k.benchedUntil)
+}
+
+// KeyLease 一次分配的密钥
+type KeyLease struct {
+	pool     *KeyPool
+	key      *pooledKey
+	id       string
+	value    string // 分配时的密钥值
+	released bool
+}
+
+// ID 密钥ID
+func (l *KeyLease) ID() string {
+	return l.id
+}
+
+// Key 分配时的密钥，分配之后的轮换不影响本次请求
+func (l *KeyLease) Key() string {
+	return l.value
+}
+
+// Report 记录上游对该密钥的响应，statusCode为0表示请求未得到响应
+// 429按Retry-After（未返回时按配置）暂停密钥，401/403按配置暂停密钥；分配之后密钥已被轮换时不暂停新密钥
+func (l *KeyLease) Report(statusCode int, retryAfter time.Duration) {
+	p := l.pool
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var result string
+	var cooldown time.Duration
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		result, cooldown = keyResultRateLimited, p.config.RateLimitCooldown
+		if retryAfter > 0 {
+			cooldown = retryAfter
+		}
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		result, cooldown = keyResultRejected, p.config.AuthCooldown
+	case statusCode == 0 || statusCode >= http.StatusInternalServerError:
+		result = keyResultError
+	default:
+		result = keyResultSuccess
+	}
+
+	l.key.results[result]++
+	if cooldown > 0 && l.key.Key == l.value {
+		until := p.now().Add(cooldown)
+		if until.After(l.key.benchedUntil) {
+			l.key.benchedUntil, l.key.benchReason = until, result
+		}
+	}
+}
+
+// Release 归还密钥，重复调用无效
+func (l *KeyLease) Release() {
+	p := l.pool
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if l.released {
+		return
+	}
+	l.released = true
+	l.key.inflight--
+}
+
+var (
+	keyRequestsDesc = prometheus.NewDesc(
+		"sia_upstream_key_requests_total",
+		"Upstream requests per API key by result (success, rate_limited, rejected, error).",
+		[]string{"key", "result"}, nil,
+	)
+	keyInflightDesc = prometheus.NewDesc(
+		"sia_upstream_key_inflight",
+		"Upstream requests currently using the API key.",
+		[]string{"key"}, nil,
+	)
+	keyBenchedDesc = prometheus.NewDesc(
+		"sia_upstream_key_benched",
+		"Whether the API key is benched after a 429 or 401/403 response (1 for benched).",
+		[]string{"key"}, nil,
+	)
+)
+
+// Describe 实现prometheus.Collector
+func (p *KeyPool) Describe(ch chan<- *prometheus.Desc) {
+	ch <- keyRequestsDesc
+	ch <- keyInflightDesc
+	ch <- keyBenchedDesc
+}
+
+// Collect 实现prometheus.Collector
+func (p *KeyPool) Collect(ch chan<- prometheus.Metric) {
+	for _, status := range p.Status() {
+		for result, count := range status.Requests {
+			ch <- prometheus.MustNewConstMetric(keyRequestsDesc, prometheus.CounterValue, float64(count), status.ID, result)
+		}
+		ch <- prometheus.MustNewConstMetric(keyInflightDesc, prometheus.GaugeValue, float64(status.Inflight), status.ID)
+		benched := 0.0
+		if status.State == KeyBenched {
+			benched = 1
+		}
+		ch <- prometheus.MustNewConstMetric(keyBenchedDesc, prometheus.GaugeValue, benched, status.ID)
+	}
+}
@@ -0,0 +1,247 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestKeyPool 创建使用固定时间的密钥池，返回推进时间的函数
+func newTestKeyPool(config KeyPoolConfig) (*KeyPool, func(time.Duration)) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	pool := NewKeyPool(config)
+	pool.now = func() time.Time { return now }
+	return pool, func(d time.Duration) { now = now.Add(d) }
+}
+
+// testKeys 生成ID为k1..kn、密钥为secret-k1..secret-kn的密钥
+func testKeys(n int) []UpstreamKey {
+	keys := make([]UpstreamKey, n)
+	for i := range keys {
+		id := fmt.Sprintf("k%d", i+1)
+		keys[i] = UpstreamKey{ID: id, Key: "secret-" + id}
+	}
+	return keys
+}
+
+// acquireID 分配一个密钥并返回其ID
+func acquireID(t *testing.T, pool *KeyPool) (string, *KeyLease) {
+	t.Helper()
+	lease, err := pool.Acquire()
+	if err != nil {
+		t.Fatalf("Acquire() error = %v", err)
+	}
+	return lease.ID(), lease
+}
+
+func TestKeyPoolRoundRobin(t *testing.T) {
+	pool, _ := newTestKeyPool(KeyPoolConfig{Keys: testKeys(3)})
+
+	var got []string
+	for i := 0; i < 6; i++ {
+		id, lease := acquireID(t, pool)
+		lease.Release()
+		got = append(got, id)
+	}
+	if fmt.Sprint(got) != "[k1 k2 k3 k1 k2 k3]" {
+		t.Errorf("round robin order = %v", got)
+	}
+}
+
+func TestKeyPoolLeastUsed(t *testing.T) {
+	pool, advance := newTestKeyPool(KeyPoolConfig{Keys: testKeys(3), Selection: KeySelectionLeastUsed})
+
+	// k1、k2各有一个进行中的请求，k3空闲
+	_, first := acquireID(t, pool)
+	advance(time.Second)
+	_, second := acquireID(t, pool)
+	advance(time.Second)
+	if id, lease := acquireID(t, pool); id != "k3" {
+		t.Fatalf("least used = %s, want k3", id)
+	} else {
+		defer lease.Release()
+	}
+
+	// 进行中的请求数相同时选择最久未使用的密钥
+	first.Release()
+	second.Release()
+	advance(time.Second)
+	if id, lease := acquireID(t, pool); id != "k1" {
+		t.Errorf("least recently used = %s, want k1", id)
+	} else {
+		lease.Release()
+	}
+
+	if status := pool.Status(); status[2].Inflight != 1 || status[0].Inflight != 0 {
+		t.Errorf("inflight = %d/%d/%d", status[0].Inflight, status[1].Inflight, status[2].Inflight)
+	}
+}
+
+func TestKeyPoolBenching(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		retryAfter  time.Duration
+		wantBenched time.Duration // 0表示不暂停
+		wantReason  string
+	}{
+		{name: "success", statusCode: http.StatusOK},
+		{name: "server error", statusCode: http.StatusBadGateway},
+		{name: "no response", statusCode: 0},
+		{name: "rate limited", statusCode: http.StatusTooManyRequests, wantBenched: time.Minute, wantReason: keyResultRateLimited},
+		{name: "rate limited with retry-after", statusCode: http.StatusTooManyRequests, retryAfter: 5 * time.Second, wantBenched: 5 * time.Second, wantReason: keyResultRateLimited},
+		{name: "unauthorized", statusCode: http.StatusUnauthorized, wantBenched: time.Hour, wantReason: keyResultRejected},
+		{name: "forbidden", statusCode: http.StatusForbidden, wantBenched: time.Hour, wantReason: keyResultRejected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool, advance := newTestKeyPool(KeyPoolConfig{Keys: testKeys(2), RateLimitCooldown: time.Minute, AuthCooldown: time.Hour})
+
+			_, lease := acquireID(t, pool)
+			lease.Report(tt.statusCode, tt.retryAfter)
+			lease.Release()
+
+			status := pool.Status()[0]
+			if tt.wantBenched == 0 {
+				if status.State != KeyActive || pool.Available() != 2 {
+					t.Fatalf("key benched after %d: %+v", tt.statusCode, status)
+				}
+				return
+			}
+			if status.State != KeyBenched || status.BenchReason != tt.wantReason || pool.Available() != 1 {
+				t.Fatalf("status = %+v, want benched for %s", status, tt.wantReason)
+			}
+
+			// 暂停期间不再分配，到期后恢复
+			for i := 0; i < 3; i++ {
+				if id, lease := acquireID(t, pool); id != "k2" {
+					t.Fatalf("benched key %s was acquired", id)
+				} else {
+					lease.Release()
+				}
+			}
+			advance(tt.wantBenched)
+			if pool.Available() != 2 || pool.Status()[0].State != KeyActive {
+				t.Errorf("key still benched after %v", tt.wantBenched)
+			}
+		})
+	}
+}
+
+func TestKeyPoolAllBenched(t *testing.T) {
+	pool, _ := newTestKeyPool(KeyPoolConfig{Keys: testKeys(2), AuthCooldown: time.Hour})
+	for i := 0; i < 2; i++ {
+		_, lease := acquireID(t, pool)
+		lease.Report(http.StatusUnauthorized, 0)
+		lease.Release()
+	}
+
+	if _, err := pool.Acquire(); !errors.Is(err, ErrNoUpstreamKey) {
+		t.Fatalf("Acquire() error = %v, want ErrNoUpstreamKey", err)
+	}
+}
+
+func TestKeyPoolRotation(t *testing.T) {
+	pool, _ := newTestKeyPool(KeyPoolConfig{Keys: testKeys(2), AuthCooldown: time.Hour})
+
+	// k1被拒绝而暂停，k2有统计
+	_, k1 := acquireID(t, pool)
+	k1.Report(http.StatusUnauthorized, 0)
+	k1.Release()
+	_, k2 := acquireID(t, pool)
+	k2.Report(http.StatusOK, 0)
+
+	// 轮换k1的密钥、保留k2、新增k3
+	pool.SetConfig(KeyPoolConfig{
+		Keys:         []UpstreamKey{{ID: "k1", Key: "rotated"}, {ID: "k2", Key: "secret-k2"}, {ID: "k3", Key: "secret-k3"}},
+		AuthCooldown: time.Hour,
+	})
+
+	status := pool.Status()
+	if len(status) != 3 {
+		t.Fatalf("keys = %d, want 3", len(status))
+	}
+	if status[0].State != KeyActive {
+		t.Errorf("rotated key is still benched: %+v", status[0])
+	}
+	if status[1].Requests[keyResultSuccess] != 1 || status[1].Inflight != 1 {
+		t.Errorf("unchanged key lost its statistics: %+v", status[1])
+	}
+
+	// 轮换前分配的请求继续使用原密钥，其结果不暂停新密钥
+	if k2.Key() != "secret-k2" {
+		t.Errorf("lease key = %q", k2.Key())
+	}
+	pool.SetConfig(KeyPoolConfig{Keys: []UpstreamKey{{ID: "k2", Key: "rotated-k2"}}, AuthCooldown: time.Hour})
+	if k2.Key() != "secret-k2" || k2.ID() != "k2" {
+		t.Errorf("lease changed after rotation: %s=%q", k2.ID(), k2.Key())
+	}
+	k2.Report(http.StatusUnauthorized, 0)
+	k2.Release()
+	if status := pool.Status(); len(status) != 1 || status[0].State != KeyActive || status[0].Inflight != 0 {
+		t.Errorf("stale lease affected the rotated key: %+v", status)
+	}
+	if _, lease := acquireID(t, pool); lease.Key() != "rotated-k2" {
+		t.Errorf("new lease key = %q, want the rotated key", lease.Key())
+	}
+}
+
+func TestKeyPoolReleaseOnce(t *testing.T) {
+	pool, _ := newTestKeyPool(KeyPoolConfig{Keys: testKeys(1)})
+	_, lease := acquireID(t, pool)
+	lease.Release()
+	lease.Release()
+	if inflight := pool.Status()[0].Inflight; inflight != 0 {
+		t.Errorf("inflight = %d after releasing twice", inflight)
+	}
+}
+
+// TestKeyPoolConcurrentRotation 在请求进行中轮换密钥，用-race运行
+func TestKeyPoolConcurrentRotation(t *testing.T) {
+	pool := NewKeyPool(KeyPoolConfig{Keys: testKeys(3), Selection: KeySelectionLeastUsed, RateLimitCooldown: time.Millisecond})
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				lease, err := pool.Acquire()
+				if err != nil {
+					continue
+				}
+				if lease.Key() == "" || lease.ID() == "" {
+					t.Error("lease without key")
+				}
+				lease.Report(http.StatusTooManyRequests, 0)
+				lease.Release()
+			}
+		}()
+	}
+
+	for i := 0; i < 200; i++ {
+		keys := testKeys(1 + i%3)
+		for j := range keys {
+			keys[j].Key = fmt.Sprintf("secret-%d-%d", i, j)
+		}
+		pool.SetConfig(KeyPoolConfig{Keys: keys, Selection: KeySelectionLeastUsed, RateLimitCooldown: time.Millisecond})
+		pool.Status()
+	}
+	close(stop)
+	wg.Wait()
+
+	for _, status := range pool.Status() {
+		if status.Inflight != 0 {
+			t.Errorf("key %s inflight = %d after all requests finished", status.ID, status.Inflight)
+		}
+	}
+}
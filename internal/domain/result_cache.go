@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CachedResult 缓存的生成结果
+type CachedResult struct {
+	Response *ImageGenerationResponse
+	CachedAt time.Time
+}
+
+// ResultCache 生成结果缓存，按请求的规范哈希索引
+// 只缓存已持久化的图片引用（上游URL会过期），超出容量时淘汰最久未使用的结果
+type ResultCache struct {
+	mutex      sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List // 最近使用的在前
+	now        func() time.Time
+}
+
+// resultCacheEntry 缓存条目
+type resultCacheEntry struct {
+	key     string
+	result  CachedResult
+	expires time.Time
+}
+
+// NewResultCache 创建生成结果缓存
+func NewResultCache(ttl time.Duration, maxEntries int) *ResultCache {
+	return &ResultCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*list.Element),
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+// Get 获取未过期的缓存结果，返回副本
+func (c *ResultCache) Get(key string) (*CachedResult, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*resultCacheEntry)
+	if !c.now().Before(entry.expires) {
+		c.remove(element)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return &CachedResult{
+		Response: cloneCachedResponse(entry.result.Response),
+		CachedAt: entry.result.CachedAt,
+	}, true
+}
+
+// Put 缓存生成结果；有图片未持久化时不缓存，返回false
+func (c *ResultCache) Put(key string, response *ImageGenerationResponse) bool {
+	if len(response.Data) == 0 {
+		return false
+	}
+	for _, image := range response.Data {
+		if image.Stored == nil {
+			return false
+		}
+	}
+
+	now := c.now()
+	entry := &resultCacheEntry{
+		key:     key,
+		result:  CachedResult{Response: cloneCachedResponse(response), CachedAt: now},
+		expires: now.Add(c.ttl),
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return true
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+	return true
+}
+
+// Delete 删除缓存结果（如引用的图片已被回收）
+func (c *ResultCache) Delete(key string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.remove(element)
+	}
+}
+
+// Len 返回缓存的结果数（包括尚未清理的过期结果）
+func (c *ResultCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.order.Len()
+}
+
+// remove 删除条目，调用方需持有锁
+func (c *ResultCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.entries, element.Value.(*resultCacheEntry).key)
+}
+
+// cloneCachedResponse 复制响应中可以缓存的部分：只保留持久化的图片引用，不保留上游URL、图片内容、用量和费用
+func cloneCachedResponse(response *ImageGenerationResponse) *ImageGenerationResponse {
+	cloned := &ImageGenerationResponse{
+		ID:      response.ID,
+		Object:  response.Object,
+		Created: response.Created,
+		Model:   response.Model,
+		Data:    make([]ImageData, len(response.Data)),
+	}
+	for i, image := range response.Data {
+		stored := *image.Stored
+		cloned.Data[i] = ImageData{
+			RevisedPrompt: image.RevisedPrompt,
+			Stored:        &stored,
+		}
+	}
+	return cloned
+}
+
+var resultCacheEntriesDesc = prometheus.NewDesc(
+	"sia_result_cache_entries",
+	"Generation results currently held in the result cache.",
+	nil, nil,
+)
+
+// Describe 实现prometheus.Collector
+func (c *ResultCache) Describe(ch chan<- *prometheus.Desc) {
+	ch <- resultCacheEntriesDesc
+}
+
+// Collect 实现prometheus.Collector
+func (c *ResultCache) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(resultCacheEntriesDesc, prometheus.GaugeValue, float64(c.Len()))
+}
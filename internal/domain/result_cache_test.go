@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+// storedResponse 返回引用已持久化图片的响应
+func storedResponse(keys ...string) *ImageGenerationResponse {
+	response := &ImageGenerationResponse{ID: "resp", Model: "model", Usage: Usage{GeneratedImages: len(keys)}}
+	for _, key := range keys {
+		response.Data = append(response.Data, ImageData{
+			URL:     "https://upstream.example.com/" + key,
+			B64JSON: "QUJD",
+			Stored:  &StoredImage{Key: key, URL: "https://cdn.example.com/" + key},
+		})
+	}
+	return response
+}
+
+// newTestResultCache 创建使用可调时间的缓存
+func newTestResultCache(ttl time.Duration, maxEntries int) (*ResultCache, *time.Time) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewResultCache(ttl, maxEntries)
+	cache.now = func() time.Time { return now }
+	return cache, &now
+}
+
+func TestResultCachePut(t *testing.T) {
+	tests := []struct {
+		name     string
+		response *ImageGenerationResponse
+		want     bool
+	}{
+		{name: "stored", response: storedResponse("a.png", "b.png"), want: true},
+		{name: "no images", response: &ImageGenerationResponse{}, want: false},
+		{name: "partly stored", response: &ImageGenerationResponse{Data: []ImageData{
+			{Stored: &StoredImage{Key: "a.png"}},
+			{URL: "https://upstream.example.com/b.png"},
+		}}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, _ := newTestResultCache(time.Hour, 10)
+			if got := cache.Put("key", tt.response); got != tt.want {
+				t.Fatalf("Put() = %v, want %v", got, tt.want)
+			}
+			if _, ok := cache.Get("key"); ok != tt.want {
+				t.Fatalf("Get() found = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestResultCacheTTL(t *testing.T) {
+	cache, now := newTestResultCache(time.Minute, 10)
+	cache.Put("key", storedResponse("a.png"))
+	cachedAt := *now
+
+	*now = now.Add(59 * time.Second)
+	result, ok := cache.Get("key")
+	if !ok {
+		t.Fatal("entry expired before its TTL")
+	}
+	if !result.CachedAt.Equal(cachedAt) {
+		t.Errorf("CachedAt = %v, want %v", result.CachedAt, cachedAt)
+	}
+
+	// 过期的条目在读取时清理
+	*now = now.Add(time.Second)
+	if _, ok := cache.Get("key"); ok {
+		t.Fatal("entry returned after its TTL")
+	}
+	if got := cache.Len(); got != 0 {
+		t.Errorf("Len() = %d after reading an expired entry, want 0", got)
+	}
+
+	// 重新写入刷新过期时间
+	cache.Put("key", storedResponse("a.png"))
+	*now = now.Add(30 * time.Second)
+	cache.Put("key", storedResponse("b.png"))
+	*now = now.Add(45 * time.Second)
+	result, ok = cache.Get("key")
+	if !ok || result.Response.Data[0].Stored.Key != "b.png" {
+		t.Fatalf("Get() after overwrite = %+v, %v", result, ok)
+	}
+}
+
+func TestResultCacheLRU(t *testing.T) {
+	cache, _ := newTestResultCache(time.Hour, 2)
+	cache.Put("a", storedResponse("a.png"))
+	cache.Put("b", storedResponse("b.png"))
+
+	// 读取a后b成为最久未使用的条目
+	if _, ok := cache.Get("a"); !ok {
+		t.Fatal("a missing")
+	}
+	cache.Put("c", storedResponse("c.png"))
+
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{key: "a", want: true},
+		{key: "b", want: false},
+		{key: "c", want: true},
+	}
+	for _, tt := range tests {
+		if _, ok := cache.Get(tt.key); ok != tt.want {
+			t.Errorf("Get(%q) found = %v, want %v", tt.key, ok, tt.want)
+		}
+	}
+	if got := cache.Len(); got != 2 {
+		t.Errorf("Len() = %d, want 2", got)
+	}
+
+	cache.Delete("a")
+	if _, ok := cache.Get("a"); ok {
+		t.Error("a returned after Delete")
+	}
+}
+
+func TestResultCacheClones(t *testing.T) {
+	cache, _ := newTestResultCache(time.Hour, 10)
+	response := storedResponse("a.png")
+	cache.Put("key", response)
+
+	// 只缓存持久化的图片引用，不保留上游URL、图片内容和用量
+	result, _ := cache.Get("key")
+	image := result.Response.Data[0]
+	if image.URL != "" || image.B64JSON != "" || result.Response.Usage.GeneratedImages != 0 {
+		t.Fatalf("cached response kept transient fields: %+v", result.Response)
+	}
+
+	// 修改写入的响应和取出的结果都不影响缓存
+	response.Data[0].Stored.Key = "changed-before.png"
+	result.Response.Data[0].Stored.Key = "changed-after.png"
+	result.Response.Data[0].URL = "https://cdn.example.com/a.png"
+
+	again, _ := cache.Get("key")
+	if again.Response.Data[0].Stored.Key != "a.png" || again.Response.Data[0].URL != "" {
+		t.Fatalf("cache shared state with callers: %+v", again.Response.Data[0])
+	}
+}
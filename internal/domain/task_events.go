@@ -0,0 +1,167 @@
+package domain
+
+import "time"
+
+// 任务事件类型
+const (
+	TaskEventStatus    = "status"    // 状态变化
+	TaskEventImage     = "image"     // 生成了一张图片
+	TaskEventCompleted = "completed" // 任务完成
+	TaskEventFailed    = "failed"    // 任务失败
+)
+
+// subscriptionBuffer 订阅通道的缓冲大小，订阅者跟不上时会被断开，需要凭最后的事件序号重新订阅
+const subscriptionBuffer = 32
+
+// maxTaskEvents 每个任务保留的历史事件数，更早的事件在重新订阅时不再补发（完成事件包含完整结果）
+const maxTaskEvents = 64
+
+// TaskEvent 任务事件
+type TaskEvent struct {
+	Seq        int        // 任务内从1开始递增的序号
+	Type       string     // 事件类型
+	TaskID     string     // 任务ID
+	Status     TaskStatus // 事件发生时的任务状态
+	Image      *ImageData // 图片事件的图片
+	ImageIndex int        // 图片事件的图片序号（从0开始）
+	Time       time.Time  // 事件时间
+}
+
+// Terminal 是否为任务的最后一个事件
+func (e TaskEvent) Terminal() bool {
+	return e.Type == TaskEventCompleted || e.Type == TaskEventFailed
+}
+
+// taskEventLog 任务的事件历史，最多保留最近maxTaskEvents个事件的环形缓冲
+// 历史中的图片事件不保留base64数据，补发时只包含URL，完整的图片在任务结果中
+type taskEventLog struct {
+	events []TaskEvent // 按序号对maxTaskEvents取模存放
+	seq    int         // 最后一个事件的序号
+	images int         // 图片事件数
+}
+
+// append 记录事件，分配序号和图片序号，返回带序号的事件
+func (l *taskEventLog) append(event TaskEvent) TaskEvent {
+	l.seq++
+	event.Seq = l.seq
+	if event.Image != nil {
+		event.ImageIndex = l.images
+		l.images++
+	}
+
+	stored := event
+	if event.Image != nil && event.Image.B64JSON != "" {
+		image := *event.Image
+		image.B64JSON = ""
+		stored.Image = &image
+	}
+	if len(l.events) < maxTaskEvents {
+		l.events = append(l.events, stored)
+	} else {
+		l.events[(event.Seq-1)%maxTaskEvents] = stored
+	}
+	return event
+}
+
+// after 按序号顺序返回保留的历史中序号大于seq的事件
+func (l *taskEventLog) after(seq int) []TaskEvent {
+	if l == nil {
+		return nil
+	}
+	first := max(seq+1, l.seq-len(l.events)+1)
+	var events []TaskEvent
+	for n := first; n <= l.seq; n++ {
+		events = append(events, l.events[(n-1)%maxTaskEvents])
+	}
+	return events
+}
+
+// TaskSubscription 任务事件订阅
+type TaskSubscription struct {
+	// Events 订阅之后发生的事件，任务结束、订阅者跟不上或取消订阅后关闭
+	Events <-chan TaskEvent
+
+	events chan TaskEvent
+	taskID string
+	tm     *TaskManager
+}
+
+// Close 取消订阅
+func (s *TaskSubscription) Close() {
+	s.tm.mutex.Lock()
+	defer s.tm.mutex.Unlock()
+	s.tm.unsubscribe(s)
+}
+
+// Subscribe 订阅任务事件
+// 返回保留的历史中序号大于afterSeq的事件以及后续事件的订阅；任务已结束时订阅的通道直接关闭
+func (tm *TaskManager) Subscribe(taskID string, afterSeq int) ([]TaskEvent, *TaskSubscription, bool) {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	task, exists := tm.tasks[taskID]
+	if !exists {
+		return nil, nil, false
+	}
+
+	history := tm.events[taskID].after(afterSeq)
+
+	events := make(chan TaskEvent, subscriptionBuffer)
+	sub := &TaskSubscription{Events: events, events: events, taskID: taskID, tm: tm}
+	if task.Status == TaskStatusCompleted || task.Status == TaskStatusFailed {
+		close(events)
+		return history, sub, true
+	}
+
+	if tm.subscribers[taskID] == nil {
+		tm.subscribers[taskID] = make(map[*TaskSubscription]struct{})
+	}
+	tm.subscribers[taskID][sub] = struct{}{}
+
+	return history, sub, true
+}
+
+// publish 记录任务事件并通知订阅者（调用方需持有写锁）
+func (tm *TaskManager) publish(task *Task, eventType string, image *ImageData) {
+	log, ok := tm.events[task.ID]
+	if !ok {
+		log = &taskEventLog{}
+		tm.events[task.ID] = log
+	}
+	event := log.append(TaskEvent{
+		Type:   eventType,
+		TaskID: task.ID,
+		Status: task.Status,
+		Image:  image,
+		Time:   task.UpdatedAt,
+	})
+
+	for sub := range tm.subscribers[task.ID] {
+		select {
+		case sub.events <- event:
+		default:
+			// 订阅者跟不上，断开后由其重新订阅
+			tm.unsubscribe(sub)
+			continue
+		}
+		if event.Terminal() {
+			tm.unsubscribe(sub)
+		}
+	}
+}
+
+// unsubscribe 移除订阅并关闭通道（调用方需持有写锁）
+func (tm *TaskManager) unsubscribe(sub *TaskSubscription) {
+	subs, ok := tm.subscribers[sub.taskID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	close(sub.events)
+	if len(subs) == 0 {
+		delete(tm.subscribers, sub.taskID)
+	}
+}
@@ -0,0 +1,74 @@
+package domain
+
+import "testing"
+
+// collect 读取订阅通道中已有的事件
+func collect(sub *TaskSubscription) []TaskEvent {
+	var events []TaskEvent
+	for {
+		select {
+		case event, ok := <-sub.Events:
+			if !ok {
+				return events
+			}
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestTaskEventHistoryDropsPayloads(t *testing.T) {
+	tm := NewTaskManager()
+	task := tm.CreateTask("cat", "tenant", "")
+
+	_, sub, _ := tm.Subscribe(task.ID, 0)
+	defer sub.Close()
+	tm.AddTaskImage(task.ID, ImageData{URL: "http://example.com/0.png", B64JSON: "QUJD"})
+
+	// 订阅者实时收到完整的图片，补发的历史事件不包含base64数据
+	live := collect(sub)
+	if len(live) != 1 || live[0].Image.B64JSON != "QUJD" {
+		t.Fatalf("live events = %+v, want the image with its payload", live)
+	}
+	history, replay, _ := tm.Subscribe(task.ID, 1)
+	defer replay.Close()
+	if len(history) != 1 || history[0].Seq != 2 {
+		t.Fatalf("history = %+v, want the image event", history)
+	}
+	if image := history[0].Image; image.B64JSON != "" || image.URL != "http://example.com/0.png" {
+		t.Errorf("replayed image = %+v, want URL only", image)
+	}
+}
+
+func TestTaskEventHistoryIsCapped(t *testing.T) {
+	tm := NewTaskManager()
+	task := tm.CreateTask("cat", "tenant", "")
+	for i := 0; i < maxTaskEvents+10; i++ {
+		tm.AddTaskImage(task.ID, ImageData{URL: "http://example.com/image.png"})
+	}
+	tm.UpdateTaskResult(task.ID, &ImageGenerationResponse{})
+
+	last := maxTaskEvents + 12
+	history, _, _ := tm.Subscribe(task.ID, 0)
+	if len(history) != maxTaskEvents {
+		t.Fatalf("history length = %d, want %d", len(history), maxTaskEvents)
+	}
+	for i, event := range history {
+		if want := last - maxTaskEvents + 1 + i; event.Seq != want {
+			t.Fatalf("history[%d].Seq = %d, want %d", i, event.Seq, want)
+		}
+	}
+	if image := history[len(history)-2]; image.ImageIndex != maxTaskEvents+9 {
+		t.Errorf("last ImageIndex = %d, want %d", image.ImageIndex, maxTaskEvents+9)
+	}
+	if !history[len(history)-1].Terminal() {
+		t.Error("last event is not terminal")
+	}
+
+	// 从保留的历史中间续传
+	history, _, _ = tm.Subscribe(task.ID, last-3)
+	if len(history) != 3 || history[0].Seq != last-2 {
+		t.Errorf("history after %d = %d events starting at %d", last-3, len(history), history[0].Seq)
+	}
+}
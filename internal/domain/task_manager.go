@@ -4,23 +4,30 @@ import (
 	"fmt"
 	"sync"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // TaskManager 任务管理器
+// 每次状态变化都会记录为任务事件并通知订阅者
 type TaskManager struct {
-	tasks map[string]*Task
-	mutex sync.RWMutex
+	tasks       map[string]*Task
+	events      map[string]*taskEventLog
+	subscribers map[string]map[*TaskSubscription]struct{}
+	mutex       sync.RWMutex
 }
 
 // NewTaskManager 创建新的任务管理器
 func NewTaskManager() *TaskManager {
 	return &TaskManager{
-		tasks: make(map[string]*Task),
+		tasks:       make(map[string]*Task),
+		events:      make(map[string]*taskEventLog),
+		subscribers: make(map[string]map[*TaskSubscription]struct{}),
 	}
 }
 
-// CreateTask 创建任务
-func (tm *TaskManager) CreateTask(prompt string) *Task {
+// CreateTask 创建任务，返回任务的快照
+func (tm *TaskManager) CreateTask(prompt, tenant, clientID string) *Task {
 	tm.mutex.Lock()
 	defer tm.mutex.Unlock()
 
@@ -28,21 +35,33 @@ func (tm *TaskManager) CreateTask(prompt string) *Task {
 		ID:        generateTaskID(),
 		Status:    TaskStatusPending,
 		Prompt:    prompt,
+		Tenant:    tenant,
+		ClientID:  clientID,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
 	tm.tasks[task.ID] = task
-	return task
+	tm.publish(task, TaskEventStatus, nil)
+	return task.snapshot()
 }
 
-// GetTask 获取任务
+// GetTask 获取任务的快照，之后的状态变化不会反映到返回值上
 func (tm *TaskManager) GetTask(taskID string) (*Task, bool) {
 	tm.mutex.RLock()
 	defer tm.mutex.RUnlock()
 
 	task, exists := tm.tasks[taskID]
-	return task, exists
+	if !exists {
+		return nil, false
+	}
+	return task.snapshot(), true
+}
+
+// snapshot 复制任务（调用方需持有锁）；结果写入后不再修改，可以共享
+func (t *Task) snapshot() *Task {
+	copied := *t
+	return &copied
 }
 
 // UpdateTaskStatus 更新任务状态
@@ -53,6 +72,18 @@ func (tm *TaskManager) UpdateTaskStatus(taskID string, status TaskStatus) {
 	if task, exists := tm.tasks[taskID]; exists {
 		task.Status = status
 		task.UpdatedAt = time.Now()
+		tm.publish(task, TaskEventStatus, nil)
+	}
+}
+
+// AddTaskImage 记录任务已生成的一张图片（上游流式返回的部分结果）
+func (tm *TaskManager) AddTaskImage(taskID string, image ImageData) {
+	tm.mutex.Lock()
+	defer tm.mutex.Unlock()
+
+	if task, exists := tm.tasks[taskID]; exists {
+		task.UpdatedAt = time.Now()
+		tm.publish(task, TaskEventImage, &image)
 	}
 }
 
@@ -65,6 +96,7 @@ func (tm *TaskManager) UpdateTaskResult(taskID string, result *ImageGenerationRe
 		task.Status = TaskStatusCompleted
 		task.Result = result
 		task.UpdatedAt = time.Now()
+		tm.publish(task, TaskEventCompleted, nil)
 	}
 }
 
@@ -77,6 +109,7 @@ func (tm *TaskManager) UpdateTaskError(taskID string, errorMsg string) {
 		task.Status = TaskStatusFailed
 		task.Error = errorMsg
 		task.UpdatedAt = time.Now()
+		tm.publish(task, TaskEventFailed, nil)
 	}
 }
 
@@ -84,3 +117,60 @@ func (tm *TaskManager) UpdateTaskError(taskID string, errorMsg string) {
 func generateTaskID() string {
 	return fmt.Sprintf("task_%d", time.Now().UnixNano())
 }
+
+// String 返回任务状态名称
+func (s TaskStatus) String() string {
+	switch s {
+	case TaskStatusPending:
+		return "pending"
+	case TaskStatusProcessing:
+		return "processing"
+	case TaskStatusCompleted:
+		return "completed"
+	case TaskStatusFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+var (
+	tasksDesc = prometheus.NewDesc(
+		"sia_tasks",
+		"Async tasks currently tracked, by status.",
+		[]string{"status"}, nil,
+	)
+	queueDepthDesc = prometheus.NewDesc(
+		"sia_task_queue_depth",
+		"Async tasks waiting to be processed.",
+		nil, nil,
+	)
+)
+
+// Describe 实现prometheus.Collector
+func (tm *TaskManager) Describe(ch chan<- *prometheus.Desc) {
+	ch <- tasksDesc
+	ch <- queueDepthDesc
+}
+
+// Counts 统计各状态的任务数
+func (tm *TaskManager) Counts() map[TaskStatus]int {
+	tm.mutex.RLock()
+	defer tm.mutex.RUnlock()
+
+	counts := make(map[TaskStatus]int)
+	for _, task := range tm.tasks {
+		counts[task.Status]++
+	}
+	return counts
+}
+
+// Collect 实现prometheus.Collector，导出各状态的任务数
+func (tm *TaskManager) Collect(ch chan<- prometheus.Metric) {
+	counts := tm.Counts()
+
+	for _, status := range []TaskStatus{TaskStatusPending, TaskStatusProcessing, TaskStatusCompleted, TaskStatusFailed} {
+		ch <- prometheus.MustNewConstMetric(tasksDesc, prometheus.GaugeValue, float64(counts[status]), status.String())
+	}
+	ch <- prometheus.MustNewConstMetric(queueDepthDesc, prometheus.GaugeValue, float64(counts[TaskStatusPending]))
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestGetTaskReturnsSnapshot(t *testing.T) {
+	tm := NewTaskManager()
+	created := tm.CreateTask("cat", "tenant", "client")
+
+	task, ok := tm.GetTask(created.ID)
+	if !ok {
+		t.Fatal("task not found")
+	}
+
+	// 快照不随之后的更新变化，修改快照也不影响管理器中的任务
+	tm.UpdateTaskStatus(created.ID, TaskStatusProcessing)
+	if task.Status != TaskStatusPending || created.Status != TaskStatusPending {
+		t.Fatalf("snapshot changed after update: %v", task.Status)
+	}
+	task.Status = TaskStatusFailed
+
+	again, _ := tm.GetTask(created.ID)
+	if again.Status != TaskStatusProcessing {
+		t.Fatalf("Status = %v, want processing", again.Status)
+	}
+
+	if _, ok := tm.GetTask("missing"); ok {
+		t.Fatal("GetTask() found a missing task")
+	}
+}
+
+func TestGetTaskConcurrentWithUpdates(t *testing.T) {
+	tm := NewTaskManager()
+	task := tm.CreateTask("cat", "tenant", "")
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			tm.UpdateTaskStatus(task.ID, TaskStatusProcessing)
+			tm.AddTaskImage(task.ID, ImageData{URL: "http://example.com/0.png"})
+		}
+		tm.UpdateTaskResult(task.ID, &ImageGenerationResponse{Model: "m"})
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			if snapshot, ok := tm.GetTask(task.ID); ok {
+				_ = snapshot.Status
+				_ = snapshot.Result
+				_ = snapshot.UpdatedAt
+			}
+		}
+	}()
+	wg.Wait()
+
+	final, _ := tm.GetTask(task.ID)
+	if final.Status != TaskStatusCompleted || final.Result == nil {
+		t.Fatalf("final task = %+v", final)
+	}
+}
@@ -28,20 +28,47 @@ type ImageGenerationResponse struct {
 	Model   string      `json:"model"`
 	Data    []ImageData `json:"data"`
 	Usage   Usage       `json:"usage"`
+	Cost    *Cost       `json:"cost,omitempty"`
+	Alias   string      `json:"alias,omitempty"` // 请求使用的模型别名（只用于异步任务的结果）
+
+	Provider      string `json:"provider,omitempty"`       // 实际使用的上游服务
+	ResolvedModel string `json:"resolved_model,omitempty"` // 实际使用的模型（注册表中的名称，Model为上游返回的名称）
+	FallbackUsed  bool   `json:"fallback_used,omitempty"`  // 是否故障转移到了备用模型
 }
 
 // ImageData 图片数据
 type ImageData struct {
-	URL           string `json:"url"`
-	B64JSON       string `json:"b64_json,omitempty"`
-	RevisedPrompt string `json:"revised_prompt,omitempty"`
+	URL           string       `json:"url"`
+	B64JSON       string       `json:"b64_json,omitempty"`
+	RevisedPrompt string       `json:"revised_prompt,omitempty"`
+	Stored        *StoredImage `json:"stored,omitempty"`
+}
+
+// StoredImage 持久化到对象存储的图片（由服务下载后保存，上游不返回）
+type StoredImage struct {
+	URL         string `json:"url,omitempty"`
+	Key         string `json:"key"`
+	SHA256      string `json:"sha256"`
+	Size        int64  `json:"size"`
+	Width       int    `json:"width,omitempty"`
+	Height      int    `json:"height,omitempty"`
+	ContentType string `json:"content_type"`
 }
 
-// Usage 使用情况
+// Usage 使用情况（与上游completed事件中的usage字段一一对应）
 type Usage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	GeneratedImages int `json:"generated_images"`
+	OutputTokens    int `json:"output_tokens"`
+	TotalTokens     int `json:"total_tokens"`
+}
+
+// Cost 费用（由服务按价格表计算，上游不返回）
+type Cost struct {
+	Currency      string  `json:"currency"`
+	Estimated     float64 `json:"estimated"`
+	Actual        float64 `json:"actual"`
+	Downgraded    bool    `json:"downgraded,omitempty"`
+	DowngradeNote string  `json:"downgrade_note,omitempty"`
 }
 
 // TaskStatus 任务状态
@@ -59,6 +86,8 @@ type Task struct {
 	ID        string                   `json:"id"`
 	Status    TaskStatus               `json:"status"`
 	Prompt    string                   `json:"prompt"`
+	Tenant    string                   `json:"tenant,omitempty"`
+	ClientID  string                   `json:"client_id,omitempty"`
 	Result    *ImageGenerationResponse `json:"result,omitempty"`
 	Error     string                   `json:"error,omitempty"`
 	CreatedAt time.Time                `json:"created_at"`
@@ -0,0 +1,124 @@
+package health
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 检查结果状态
+const (
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+)
+
+// Check 依赖检查项
+type Check struct {
+	Name string
+	// TTL 结果缓存时间，0表示每次都执行（适合只读内存状态的检查）
+	TTL time.Duration
+	// Timeout 单次检查超时，0表示不限制
+	Timeout time.Duration
+	Func    func(ctx context.Context) error
+}
+
+// Result 单项检查结果
+type Result struct {
+	Name      string        `json:"name"`
+	Status    string        `json:"status"`
+	Message   string        `json:"message,omitempty"`
+	Latency   time.Duration `json:"-"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// Report 就绪检查报告
+type Report struct {
+	Ready  bool     `json:"ready"`
+	Checks []Result `json:"checks"`
+}
+
+// entry 检查项及其缓存结果
+type entry struct {
+	check  Check
+	mutex  sync.Mutex // 串行执行同一检查项，避免并发探测上游
+	result *Result
+}
+
+// Checker 就绪检查器，所有检查项通过时才视为就绪
+type Checker struct {
+	mutex   sync.RWMutex
+	entries []*entry
+	now     func() time.Time
+}
+
+// NewChecker 创建就绪检查器
+func NewChecker() *Checker {
+	return &Checker{now: time.Now}
+}
+
+// Register 注册检查项
+func (c *Checker) Register(check Check) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.entries = append(c.entries, &entry{check: check})
+}
+
+// Check 执行所有检查项，缓存未过期的检查项直接返回上次结果
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mutex.RLock()
+	entries := append([]*entry(nil), c.entries...)
+	c.mutex.RUnlock()
+
+	results := make([]Result, len(entries))
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func(i int, e *entry) {
+			defer wg.Done()
+			results[i] = c.run(ctx, e)
+		}(i, e)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	report := Report{Ready: true, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusOK {
+			report.Ready = false
+		}
+	}
+	return report
+}
+
+// run 执行单个检查项
+func (c *Checker) run(ctx context.Context, e *entry) Result {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	now := c.now()
+	if e.result != nil && e.check.TTL > 0 && now.Sub(e.result.CheckedAt) < e.check.TTL {
+		return *e.result
+	}
+
+	checkCtx := ctx
+	if e.check.Timeout > 0 {
+		var cancel context.CancelFunc
+		checkCtx, cancel = context.WithTimeout(ctx, e.check.Timeout)
+		defer cancel()
+	}
+
+	result := Result{Name: e.check.Name, Status: StatusOK, CheckedAt: now}
+	if err := e.check.Func(checkCtx); err != nil {
+		result.Status = StatusFailing
+		result.Message = err.Error()
+	}
+	result.Latency = c.now().Sub(now)
+
+	// 调用方取消导致的失败不缓存
+	if ctx.Err() == nil {
+		e.result = &result
+	}
+	return result
+}
@@ -0,0 +1,154 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry 服务指标注册表
+var Registry = prometheus.NewRegistry()
+
+var (
+	// RPCRequests gRPC请求数，按方法和状态码
+	RPCRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sia_grpc_requests_total",
+		Help: "gRPC requests handled, by method and status code.",
+	}, []string{"method", "code"})
+
+	// RPCDuration gRPC请求耗时，按方法和状态码
+	RPCDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "sia_grpc_request_duration_seconds",
+		Help:    "gRPC request latency, by method and status code.",
+		Buckets: []float64{0.005, 0.025, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+	}, []string{"method", "code"})
+
+	// UpstreamRequests 上游API请求数，按模型和HTTP状态（传输失败时为error）
+	UpstreamRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sia_upstream_requests_total",
+		Help: "Requests sent to the image generation API, by model and HTTP status.",
+	}, []string{"model", "status"})
+
+	// UpstreamDuration 上游API请求耗时（包含读取SSE流），按模型和HTTP状态
+	UpstreamDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "sia_upstream_request_duration_seconds",
+		Help:    "Image generation API latency including the SSE stream, by model and HTTP status.",
+		Buckets: []float64{0.5, 1, 2.5, 5, 10, 20, 30, 60, 120, 300},
+	}, []string{"model", "status"})
+
+	// UpstreamErrors 上游API失败数，按模型和HTTP状态
+	UpstreamErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sia_upstream_errors_total",
+		Help: "Failed image generation API calls, by model and HTTP status.",
+	}, []string{"model", "status"})
+
+	// ImagesGenerated 生成的图片数，按模型
+	ImagesGenerated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sia_images_generated_total",
+		Help: "Images returned by the image generation API, by model.",
+	}, []string{"model"})
+
+	// SSEParseErrors 无法解析的SSE事件数，按模型
+	SSEParseErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sia_upstream_sse_parse_errors_total",
+		Help: "SSE events from the image generation API that could not be parsed, by model.",
+	}, []string{"model"})
+
+	// ImagesPersisted 图片持久化次数，按存储后端和结果
+	ImagesPersisted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sia_images_persisted_total",
+		Help: "Generated images downloaded and written to the blob store, by backend and result.",
+	}, []string{"backend", "result"})
+
+	// PersistedBytes 写入存储的图片字节数，按存储后端
+	PersistedBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sia_images_persisted_bytes_total",
+		Help: "Bytes of generated images written to the blob store, by backend.",
+	}, []string{"backend"})
+
+	// ResultCacheLookups 结果缓存的查询数，按结果（hit、miss、stale、bypass、refresh）
+	ResultCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sia_result_cache_lookups_total",
+		Help: "Result cache lookups for generation requests, by result (hit, miss, stale, bypass, refresh).",
+	}, []string{"result"})
+
+	// CoalescedRequests 合并到进行中的相同请求的请求数，按模型
+	CoalescedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sia_coalesced_requests_total",
+		Help: "Generation requests that joined an identical in-flight upstream call instead of making their own, by model.",
+	}, []string{"model"})
+
+	// StorageGCObjects 存储GC处理的对象数，按处理方式和结果
+	StorageGCObjects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sia_storage_gc_objects_total",
+		Help: "Stored images removed by retention GC, by action (delete, archive) and result.",
+	}, []string{"action", "result"})
+
+	// StorageGCBytes 存储GC释放的字节数，按处理方式
+	StorageGCBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sia_storage_gc_bytes_total",
+		Help: "Bytes of stored images removed by retention GC, by action.",
+	}, []string{"action"})
+
+	// ModelAliasResolutions 模型别名解析次数，按别名和分配到的具体模型
+	ModelAliasResolutions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sia_model_alias_resolutions_total",
+		Help: "Requests for a model alias, by alias and the concrete model it resolved to.",
+	}, []string{"alias", "model"})
+
+	// ModelFailovers 故障转移次数，按别名、转移到的备用模型和上游服务以及触发的错误类型
+	ModelFailovers = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sia_model_failovers_total",
+		Help: "Requests retried on a fallback model after the previous model failed, by alias, fallback model, provider and error class.",
+	}, []string{"alias", "model", "provider", "reason"})
+
+	// ConfigReloads 配置热加载次数，按结果（applied、unchanged、failed、rejected）
+	ConfigReloads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sia_config_reloads_total",
+		Help: "Configuration reloads triggered by file changes or SIGHUP, by result (applied, unchanged, failed, rejected).",
+	}, []string{"result"})
+
+	// SecretRefreshes 密钥定期刷新次数，按结果（unchanged、rotated、failed）
+	SecretRefreshes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "sia_secret_refreshes_total",
+		Help: "Periodic secret refreshes, by result (unchanged, rotated, failed).",
+	}, []string{"result"})
+
+	// ConfigLastReload 最近一次成功应用配置的时间
+	ConfigLastReload = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "sia_config_last_reload_success_timestamp_seconds",
+		Help: "Unix time of the last configuration reload that was applied.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		RPCRequests,
+		RPCDuration,
+		UpstreamRequests,
+		UpstreamDuration,
+		UpstreamErrors,
+		ImagesGenerated,
+		SSEParseErrors,
+		ImagesPersisted,
+		PersistedBytes,
+		StorageGCObjects,
+		StorageGCBytes,
+		ResultCacheLookups,
+		CoalescedRequests,
+		ModelAliasResolutions,
+		ModelFailovers,
+		ConfigReloads,
+		ConfigLastReload,
+		SecretRefreshes,
+	)
+}
+
+// Handler 返回Prometheus文本格式的指标处理器
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,255 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 上游服务
+const (
+	ProviderArk = "ark" // 火山方舟
+)
+
+// Providers 支持的上游服务
+var Providers = []string{ProviderArk}
+
+// 触发故障转移的错误类型
+const (
+	FailoverServerError = "server_error" // 上游返回5xx
+	FailoverCircuitOpen = "circuit_open" // 上游已熔断
+	FailoverUnreachable = "unreachable"  // 网络错误或上游超时
+	FailoverRateLimited = "rate_limited" // 上游返回429，或所有API Key都被暂停
+	FailoverRejected    = "rejected"     // 上游返回401/403
+)
+
+// FailoverClasses 全部错误类型
+var FailoverClasses = []string{FailoverServerError, FailoverCircuitOpen, FailoverUnreachable, FailoverRateLimited, FailoverRejected}
+
+// Model 模型及其能力
+type Model struct {
+	Name               string
+	Provider           string
+	Sizes              []string // 支持的尺寸，如2K或2048x2048，不区分大小写
+	AspectRatios       []string // WxH形式的尺寸允许的宽高比，如16:9
+	MaxReferenceImages int      // 最多参考图片数量，0表示不支持参考图片
+	Sequential         bool     // 是否支持序列图片
+	MaxImages          int      // 单次请求最多生成的图片数量
+}
+
+// Alias 模型别名，按权重把请求分配到具体模型
+type Alias struct {
+	Name       string
+	Targets    []AliasTarget
+	StickyKey  string     // 请求metadata中用于固定分配结果的键
+	Fallbacks  []Fallback // 分配到的模型失败时依次尝试的备用模型
+	FailoverOn []string   // 触发故障转移的错误类型
+}
+
+// AliasTarget 别名指向的具体模型及其权重
+type AliasTarget struct {
+	Model  string
+	Weight int
+}
+
+// Fallback 故障转移的备用模型，Provider为空时使用模型自身的上游服务
+type Fallback struct {
+	Model    string
+	Provider string
+}
+
+// Request 待验证的请求参数（已补全默认模型和尺寸）
+type Request struct {
+	Model           string
+	Size            string
+	ReferenceImages int
+	Sequential      bool
+	Images          int
+}
+
+// Registry 模型注册表
+type Registry struct {
+	models  map[string]Model
+	names   []string
+	aliases map[string]Alias
+}
+
+// NewRegistry 创建模型注册表
+func NewRegistry(models []Model, aliases []Alias) *Registry {
+	r := &Registry{
+		models:  make(map[string]Model, len(models)),
+		aliases: make(map[string]Alias, len(aliases)),
+	}
+	for _, model := range models {
+		r.models[model.Name] = model
+		r.names = append(r.names, model.Name)
+	}
+	sort.Strings(r.names)
+	for _, alias := range aliases {
+		r.aliases[alias.Name] = alias
+	}
+	return r
+}
+
+// Aliases 返回全部别名，按名称排序
+func (r *Registry) Aliases() []Alias {
+	aliases := make([]Alias, 0, len(r.aliases))
+	for _, alias := range r.aliases {
+		aliases = append(aliases, alias)
+	}
+	sort.Slice(aliases, func(i, j int) bool { return aliases[i].Name < aliases[j].Name })
+	return aliases
+}
+
+// Alias 获取别名
+func (r *Registry) Alias(name string) (Alias, bool) {
+	alias, ok := r.aliases[name]
+	return alias, ok
+}
+
+// Resolve 将别名解析为具体模型，name不是别名时原样返回且alias为空
+// metadata中有别名的粘性键时按其值的哈希分配，权重不变时同一个值总是分配到同一个模型；否则按权重随机分配
+func (r *Registry) Resolve(name string, metadata map[string]string) (model, alias string) {
+	a, ok := r.aliases[name]
+	if !ok || len(a.Targets) == 0 {
+		return name, ""
+	}
+
+	total := 0
+	for _, target := range a.Targets {
+		total += target.Weight
+	}
+	if total <= 0 {
+		return a.Targets[0].Model, a.Name
+	}
+
+	var point int
+	if value := metadata[a.StickyKey]; a.StickyKey != "" && value != "" {
+		// 哈希中包含别名，不同别名的分配相互独立
+		hash := fnv.New64a()
+		hash.Write([]byte(a.Name))
+		hash.Write([]byte{0})
+		hash.Write([]byte(value))
+		point = int(hash.Sum64() % uint64(total))
+	} else {
+		point = rand.IntN(total)
+	}
+
+	for _, target := range a.Targets {
+		if point < target.Weight {
+			return target.Model, a.Name
+		}
+		point -= target.Weight
+	}
+	return a.Targets[len(a.Targets)-1].Model, a.Name
+}
+
+// Get 获取模型
+func (r *Registry) Get(name string) (Model, bool) {
+	model, ok := r.models[name]
+	return model, ok
+}
+
+// List 返回全部模型，按名称排序
+func (r *Registry) List() []Model {
+	models := make([]Model, len(r.names))
+	for i, name := range r.names {
+		models[i] = r.models[name]
+	}
+	return models
+}
+
+// Validate 按模型能力验证请求，返回第一个不满足的条件
+func (r *Registry) Validate(req Request) error {
+	model, ok := r.models[req.Model]
+	if !ok {
+		available := append([]string(nil), r.names...)
+		for _, alias := range r.Aliases() {
+			available = append(available, alias.Name)
+		}
+		return fmt.Errorf("unknown model %q, available models: %s", req.Model, strings.Join(available, ", "))
+	}
+
+	if !model.SupportsSize(req.Size) {
+		message := fmt.Sprintf("model %q does not support size %q, supported sizes: %s", model.Name, req.Size, strings.Join(model.Sizes, ", "))
+		if len(model.AspectRatios) > 0 {
+			message += fmt.Sprintf(", or WIDTHxHEIGHT with aspect ratio %s", strings.Join(model.AspectRatios, ", "))
+		}
+		return errors.New(message)
+	}
+
+	if req.ReferenceImages > model.MaxReferenceImages {
+		if model.MaxReferenceImages == 0 {
+			return fmt.Errorf("model %q does not accept reference images", model.Name)
+		}
+		return fmt.Errorf("model %q accepts at most %d reference images, got %d", model.Name, model.MaxReferenceImages, req.ReferenceImages)
+	}
+
+	if req.Sequential && !model.Sequential {
+		return fmt.Errorf("model %q does not support sequential image generation", model.Name)
+	}
+
+	if req.Images < 1 || req.Images > model.MaxImages {
+		return fmt.Errorf("max_images must be between 1 and %d for model %q", model.MaxImages, model.Name)
+	}
+	return nil
+}
+
+// SupportsSize 判断模型是否支持指定尺寸：尺寸在列表中，或者是宽高比在列表中的WxH
+func (m Model) SupportsSize(size string) bool {
+	for _, supported := range m.Sizes {
+		if strings.EqualFold(supported, size) {
+			return true
+		}
+	}
+
+	width, height, ok := ParseSize(size)
+	if !ok {
+		return false
+	}
+	for _, ratio := range m.AspectRatios {
+		w, h, err := ParseAspectRatio(ratio)
+		if err == nil && width*h == height*w {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSize 解析WIDTHxHEIGHT形式的尺寸
+func ParseSize(size string) (width, height int, ok bool) {
+	w, h, found := strings.Cut(strings.ToLower(size), "x")
+	if !found {
+		return 0, 0, false
+	}
+	width, err := strconv.Atoi(w)
+	if err != nil || width <= 0 {
+		return 0, 0, false
+	}
+	height, err = strconv.Atoi(h)
+	if err != nil || height <= 0 {
+		return 0, 0, false
+	}
+	return width, height, true
+}
+
+// ParseAspectRatio 解析W:H形式的宽高比
+func ParseAspectRatio(ratio string) (width, height int, err error) {
+	w, h, found := strings.Cut(ratio, ":")
+	if !found {
+		return 0, 0, fmt.Errorf("invalid aspect ratio %q, must be W:H", ratio)
+	}
+	width, err = strconv.Atoi(w)
+	if err != nil || width <= 0 {
+		return 0, 0, fmt.Errorf("invalid aspect ratio %q, must be W:H", ratio)
+	}
+	height, err = strconv.Atoi(h)
+	if err != nil || height <= 0 {
+		return 0, 0, fmt.Errorf("invalid aspect ratio %q, must be W:H", ratio)
+	}
+	return width, height, nil
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func newTestRegistry(targets ...AliasTarget) *Registry {
+	return NewRegistry(
+		[]Model{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+		[]Alias{{Name: "image", Targets: targets, StickyKey: "user"}},
+	)
+}
+
+// split 按粘性键的n个不同取值解析别名，返回各模型分配到的比例
+func split(registry *Registry, n int, metadata func(i int) map[string]string) map[string]float64 {
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		model, _ := registry.Resolve("image", metadata(i))
+		counts[model]++
+	}
+	shares := make(map[string]float64, len(counts))
+	for model, count := range counts {
+		shares[model] = float64(count) / float64(n)
+	}
+	return shares
+}
+
+func TestResolveSticky(t *testing.T) {
+	registry := newTestRegistry(AliasTarget{Model: "a", Weight: 50}, AliasTarget{Model: "b", Weight: 50})
+
+	first := make(map[string]string)
+	for i := 0; i < 100; i++ {
+		user := fmt.Sprintf("user-%d", i)
+		model, alias := registry.Resolve("image", map[string]string{"user": user})
+		if alias != "image" {
+			t.Fatalf("alias = %q, want image", alias)
+		}
+		first[user] = model
+	}
+
+	// 权重不变时同一个值总是分配到同一个模型，重建注册表（如热加载）后也不变
+	rebuilt := newTestRegistry(AliasTarget{Model: "a", Weight: 50}, AliasTarget{Model: "b", Weight: 50})
+	for user, want := range first {
+		for _, registry := range []*Registry{registry, rebuilt} {
+			if model, _ := registry.Resolve("image", map[string]string{"user": user}); model != want {
+				t.Fatalf("%s resolved to %s, then %s", user, want, model)
+			}
+		}
+	}
+}
+
+func TestResolveWeights(t *testing.T) {
+	registry := newTestRegistry(
+		AliasTarget{Model: "a", Weight: 80},
+		AliasTarget{Model: "b", Weight: 20},
+		AliasTarget{Model: "c", Weight: 0},
+	)
+
+	sticky := split(registry, 20000, func(i int) map[string]string {
+		return map[string]string{"user": fmt.Sprintf("user-%d", i)}
+	})
+	random := split(registry, 20000, func(int) map[string]string { return nil })
+
+	for name, shares := range map[string]map[string]float64{"sticky": sticky, "random": random} {
+		if math.Abs(shares["a"]-0.8) > 0.02 || math.Abs(shares["b"]-0.2) > 0.02 {
+			t.Errorf("%s split = %v, want about 80/20", name, shares)
+		}
+		if shares["c"] != 0 {
+			t.Errorf("%s split assigned %v to a zero-weight target", name, shares["c"])
+		}
+	}
+}
+
+func TestResolveWithoutAlias(t *testing.T) {
+	registry := newTestRegistry(AliasTarget{Model: "b", Weight: 0}, AliasTarget{Model: "a", Weight: 0})
+
+	if model, alias := registry.Resolve("a", map[string]string{"user": "u"}); model != "a" || alias != "" {
+		t.Errorf("Resolve(a) = %q, %q, want the model unchanged", model, alias)
+	}
+	// 权重全为0时使用第一个模型
+	if model, alias := registry.Resolve("image", nil); model != "b" || alias != "image" {
+		t.Errorf("Resolve(image) = %q, %q, want b", model, alias)
+	}
+}
@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// tokenBucket 令牌桶，调用方负责加锁
+type tokenBucket struct {
+	rate   float64 // 每秒补充的令牌数
+	burst  float64 // 桶容量
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket 创建令牌桶，初始为满桶
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	b := float64(burst)
+	if b < 1 {
+		b = math.Max(1, rate)
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  b,
+		tokens: b,
+		last:   now,
+	}
+}
+
+// carryOver 沿用另一个令牌桶的剩余令牌（不超过本桶容量），限制参数变化时不会重新装满
+func (b *tokenBucket) carryOver(previous *tokenBucket, now time.Time) {
+	b.tokens = math.Min(b.burst, previous.available(now))
+}
+
+// refill 按流逝时间补充令牌
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// take 尝试取出一个令牌；失败时返回需要等待的时间
+func (b *tokenBucket) take(now time.Time) (bool, time.Duration) {
+	b.refill(now)
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := (1 - b.tokens) / b.rate
+	return false, time.Duration(math.Ceil(wait*1000)) * time.Millisecond
+}
+
+// giveBack 归还令牌（后续检查失败时撤销扣减）
+func (b *tokenBucket) giveBack() {
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+// available 当前可用令牌数
+func (b *tokenBucket) available(now time.Time) float64 {
+	b.refill(now)
+	return b.tokens
+}
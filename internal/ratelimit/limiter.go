@@ -0,0 +1,302 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 限流维度
+const (
+	ScopeSubject = "subject" // 按租户或客户端
+	ScopeModel   = "model"   // 按模型（全局）
+)
+
+// 拒绝原因
+const (
+	ReasonRate        = "rate"
+	ReasonConcurrency = "concurrency"
+)
+
+const (
+	// idleTTL 空闲状态的保留时间
+	idleTTL = 10 * time.Minute
+	// sweepInterval 清理空闲状态的间隔
+	sweepInterval = 5 * time.Minute
+	// concurrencyRetryAfter 并发超限时建议的重试间隔
+	concurrencyRetryAfter = time.Second
+)
+
+// Limits 限流参数，0表示不限制
+type Limits struct {
+	RequestsPerSecond float64
+	Burst             int
+	MaxConcurrent     int
+}
+
+// Config 限流器配置
+type Config struct {
+	DefaultTier string
+	Tiers       map[string]Limits
+	Models      map[string]Limits
+}
+
+// Subject 限流主体
+type Subject struct {
+	Key  string // 租户或客户端ID
+	Tier string // 为空时使用默认分级
+}
+
+// LimitError 超出限制错误
+type LimitError struct {
+	Scope      string
+	Key        string
+	Reason     string
+	RetryAfter time.Duration
+}
+
+// Error 实现error接口
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s limit exceeded for %s %q", e.Reason, e.Scope, e.Key)
+}
+
+// state 单个限流键的状态
+type state struct {
+	tier     string
+	limits   Limits
+	bucket   *tokenBucket // 为nil时不限速
+	inflight int
+	lastUsed time.Time
+
+	allowed             uint64
+	rejectedRate        uint64
+	rejectedConcurrency uint64
+}
+
+// Limiter 令牌桶 + 并发数限流器
+type Limiter struct {
+	mutex     sync.Mutex
+	config    Config
+	subjects  map[string]*state
+	models    map[string]*state
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// New 创建限流器
+func New(config Config) *Limiter {
+	return &Limiter{
+		config:   config,
+		subjects: make(map[string]*state),
+		models:   make(map[string]*state),
+		now:      time.Now,
+	}
+}
+
+// Acquire 申请一次请求配额，成功时返回释放函数（必须调用且只会生效一次）
+func (l *Limiter) Acquire(subject Subject, model string) (func(), error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	tier := subject.Tier
+	if _, ok := l.config.Tiers[tier]; !ok {
+		tier = l.config.DefaultTier
+	}
+
+	s := l.subjectState(subject.Key, tier, now)
+	m := l.modelState(model, now)
+
+	// 先检查并发，避免无谓扣减令牌
+	if s.limits.MaxConcurrent > 0 && s.inflight >= s.limits.MaxConcurrent {
+		s.rejectedConcurrency++
+		return nil, &LimitError{Scope: ScopeSubject, Key: subject.Key, Reason: ReasonConcurrency, RetryAfter: concurrencyRetryAfter}
+	}
+	if m != nil && m.limits.MaxConcurrent > 0 && m.inflight >= m.limits.MaxConcurrent {
+		m.rejectedConcurrency++
+		return nil, &LimitError{Scope: ScopeModel, Key: model, Reason: ReasonConcurrency, RetryAfter: concurrencyRetryAfter}
+	}
+
+	if s.bucket != nil {
+		if ok, wait := s.bucket.take(now); !ok {
+			s.rejectedRate++
+			return nil, &LimitError{Scope: ScopeSubject, Key: subject.Key, Reason: ReasonRate, RetryAfter: wait}
+		}
+	}
+	if m != nil && m.bucket != nil {
+		if ok, wait := m.bucket.take(now); !ok {
+			if s.bucket != nil {
+				s.bucket.giveBack()
+			}
+			m.rejectedRate++
+			return nil, &LimitError{Scope: ScopeModel, Key: model, Reason: ReasonRate, RetryAfter: wait}
+		}
+	}
+
+	s.inflight++
+	s.allowed++
+	if m != nil {
+		m.inflight++
+		m.allowed++
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			s.inflight--
+			s.lastUsed = l.now()
+			if m != nil {
+				m.inflight--
+				m.lastUsed = s.lastUsed
+			}
+		})
+	}, nil
+}
+
+// SetConfig 替换限流配置，保留在途请求数与计数器；限制参数变化的主体和模型按新参数重建令牌桶并沿用剩余令牌
+func (l *Limiter) SetConfig(config Config) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.config = config
+	now := l.now()
+
+	for _, s := range l.subjects {
+		tier := s.tier
+		if _, ok := config.Tiers[tier]; !ok {
+			tier = config.DefaultTier
+		}
+		if limits := config.Tiers[tier]; tier != s.tier || limits != s.limits {
+			s.setLimits(tier, limits, now)
+		}
+	}
+
+	for model, m := range l.models {
+		limits, ok := config.Models[model]
+		if !ok {
+			// 在途请求的释放函数仍持有原状态
+			delete(l.models, model)
+			continue
+		}
+		if limits != m.limits {
+			m.setLimits("", limits, now)
+		}
+	}
+}
+
+// subjectState 获取或创建主体状态；分级变化时原地替换限制参数并沿用剩余令牌，交替使用不同分级的凭据不会重新获得突发额度
+func (l *Limiter) subjectState(key, tier string, now time.Time) *state {
+	s, ok := l.subjects[key]
+	if !ok {
+		s = &state{}
+		l.subjects[key] = s
+	}
+	if !ok || s.tier != tier {
+		s.setLimits(tier, l.config.Tiers[tier], now)
+	}
+	s.lastUsed = now
+	return s
+}
+
+// modelState 获取或创建模型状态；模型未配置限制时返回nil
+func (l *Limiter) modelState(model string, now time.Time) *state {
+	limits, ok := l.config.Models[model]
+	if !ok {
+		return nil
+	}
+	m, ok := l.models[model]
+	if !ok {
+		m = &state{}
+		m.setLimits("", limits, now)
+		l.models[model] = m
+	}
+	m.lastUsed = now
+	return m
+}
+
+// setLimits 设置限制参数，保留在途请求数与计数器；原来限速时新令牌桶沿用剩余令牌，否则为满桶
+func (s *state) setLimits(tier string, limits Limits, now time.Time) {
+	previous := s.bucket
+	s.tier = tier
+	s.limits = limits
+	s.bucket = nil
+	if limits.RequestsPerSecond > 0 {
+		s.bucket = newTokenBucket(limits.RequestsPerSecond, limits.Burst, now)
+		if previous != nil {
+			s.bucket.carryOver(previous, now)
+		}
+	}
+}
+
+// sweep 清理长时间空闲的主体状态，调用方需持有锁
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, s := range l.subjects {
+		if s.inflight == 0 && now.Sub(s.lastUsed) > idleTTL {
+			delete(l.subjects, key)
+		}
+	}
+}
+
+var (
+	inflightDesc = prometheus.NewDesc(
+		"sia_ratelimit_inflight_requests",
+		"Requests currently holding a concurrency slot.",
+		[]string{"scope", "key"}, nil,
+	)
+	maxConcurrentDesc = prometheus.NewDesc(
+		"sia_ratelimit_max_concurrent_requests",
+		"Configured concurrency limit (0 means unlimited).",
+		[]string{"scope", "key"}, nil,
+	)
+	tokensDesc = prometheus.NewDesc(
+		"sia_ratelimit_tokens_available",
+		"Tokens currently available in the rate limit bucket.",
+		[]string{"scope", "key"}, nil,
+	)
+	decisionsDesc = prometheus.NewDesc(
+		"sia_ratelimit_decisions_total",
+		"Rate limiter decisions by result.",
+		[]string{"scope", "key", "result"}, nil,
+	)
+)
+
+// Describe 实现prometheus.Collector
+func (l *Limiter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- inflightDesc
+	ch <- maxConcurrentDesc
+	ch <- tokensDesc
+	ch <- decisionsDesc
+}
+
+// Collect 实现prometheus.Collector，导出限流器当前状态
+func (l *Limiter) Collect(ch chan<- prometheus.Metric) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	collect := func(scope string, states map[string]*state) {
+		for key, s := range states {
+			ch <- prometheus.MustNewConstMetric(inflightDesc, prometheus.GaugeValue, float64(s.inflight), scope, key)
+			ch <- prometheus.MustNewConstMetric(maxConcurrentDesc, prometheus.GaugeValue, float64(s.limits.MaxConcurrent), scope, key)
+			if s.bucket != nil {
+				ch <- prometheus.MustNewConstMetric(tokensDesc, prometheus.GaugeValue, s.bucket.available(now), scope, key)
+			}
+			ch <- prometheus.MustNewConstMetric(decisionsDesc, prometheus.CounterValue, float64(s.allowed), scope, key, "allowed")
+			ch <- prometheus.MustNewConstMetric(decisionsDesc, prometheus.CounterValue, float64(s.rejectedRate), scope, key, "rejected_rate")
+			ch <- prometheus.MustNewConstMetric(decisionsDesc, prometheus.CounterValue, float64(s.rejectedConcurrency), scope, key, "rejected_concurrency")
+		}
+	}
+	collect(ScopeSubject, l.subjects)
+	collect(ScopeModel, l.models)
+}
@@ -0,0 +1,235 @@
+package ratelimit
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeClock 可以手动推进的时钟
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestLimiter(config Config) (*Limiter, *fakeClock) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	limiter := New(config)
+	limiter.now = clock.Now
+	return limiter, clock
+}
+
+// limitError 断言错误为指定维度和原因的LimitError
+func limitError(t *testing.T, err error, scope, reason string) *LimitError {
+	t.Helper()
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("error = %v, want *LimitError", err)
+	}
+	if limitErr.Scope != scope || limitErr.Reason != reason {
+		t.Fatalf("error = %s/%s, want %s/%s", limitErr.Scope, limitErr.Reason, scope, reason)
+	}
+	return limitErr
+}
+
+// acquireN 连续申请n次并立即释放，返回成功的次数
+func acquireN(limiter *Limiter, subject Subject, model string, n int) int {
+	allowed := 0
+	for i := 0; i < n; i++ {
+		release, err := limiter.Acquire(subject, model)
+		if err == nil {
+			allowed++
+			release()
+		}
+	}
+	return allowed
+}
+
+func TestLimiterRate(t *testing.T) {
+	limiter, clock := newTestLimiter(Config{
+		DefaultTier: "free",
+		Tiers: map[string]Limits{
+			"free": {RequestsPerSecond: 1, Burst: 3},
+			"pro":  {RequestsPerSecond: 10, Burst: 20},
+		},
+	})
+	free := Subject{Key: "tenant-a"}
+
+	if got := acquireN(limiter, free, "m", 5); got != 3 {
+		t.Fatalf("allowed = %d, want burst of 3", got)
+	}
+
+	_, err := limiter.Acquire(free, "m")
+	if limitErr := limitError(t, err, ScopeSubject, ReasonRate); limitErr.RetryAfter != time.Second {
+		t.Errorf("RetryAfter = %v, want 1s", limitErr.RetryAfter)
+	}
+
+	clock.Advance(2 * time.Second)
+	if got := acquireN(limiter, free, "m", 5); got != 2 {
+		t.Errorf("allowed after 2s = %d, want 2", got)
+	}
+
+	// 每个主体有独立的令牌桶，未知分级使用默认分级
+	if got := acquireN(limiter, Subject{Key: "tenant-b", Tier: "unknown"}, "m", 5); got != 3 {
+		t.Errorf("allowed for another subject = %d, want 3", got)
+	}
+	if got := acquireN(limiter, Subject{Key: "tenant-c", Tier: "pro"}, "m", 25); got != 20 {
+		t.Errorf("allowed for pro tier = %d, want 20", got)
+	}
+}
+
+func TestLimiterConcurrency(t *testing.T) {
+	limiter, _ := newTestLimiter(Config{
+		DefaultTier: "free",
+		Tiers:       map[string]Limits{"free": {MaxConcurrent: 2}},
+		Models:      map[string]Limits{"slow": {MaxConcurrent: 1}},
+	})
+
+	first, err := limiter.Acquire(Subject{Key: "a"}, "fast")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := limiter.Acquire(Subject{Key: "a"}, "fast"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = limiter.Acquire(Subject{Key: "a"}, "fast")
+	limitError(t, err, ScopeSubject, ReasonConcurrency)
+
+	// 释放函数只生效一次
+	first()
+	first()
+	if _, err := limiter.Acquire(Subject{Key: "a"}, "fast"); err != nil {
+		t.Fatalf("Acquire() after release: %v", err)
+	}
+	_, err = limiter.Acquire(Subject{Key: "a"}, "fast")
+	limitError(t, err, ScopeSubject, ReasonConcurrency)
+
+	// 模型并发限制对所有主体生效
+	release, err := limiter.Acquire(Subject{Key: "b"}, "slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = limiter.Acquire(Subject{Key: "c"}, "slow")
+	limitError(t, err, ScopeModel, ReasonConcurrency)
+	release()
+	if _, err := limiter.Acquire(Subject{Key: "c"}, "slow"); err != nil {
+		t.Fatalf("Acquire() after model release: %v", err)
+	}
+}
+
+func TestLimiterModelRejectionRefundsSubjectToken(t *testing.T) {
+	limiter, _ := newTestLimiter(Config{
+		DefaultTier: "free",
+		Tiers:       map[string]Limits{"free": {RequestsPerSecond: 1, Burst: 2}},
+		Models:      map[string]Limits{"scarce": {RequestsPerSecond: 1, Burst: 1}},
+	})
+	subject := Subject{Key: "a"}
+
+	if got := acquireN(limiter, subject, "scarce", 1); got != 1 {
+		t.Fatal("first request rejected")
+	}
+	_, err := limiter.Acquire(subject, "scarce")
+	limitError(t, err, ScopeModel, ReasonRate)
+
+	// 被模型限制拒绝的请求不消耗主体的令牌
+	if got := acquireN(limiter, subject, "other", 2); got != 1 {
+		t.Errorf("allowed = %d, want the remaining subject token", got)
+	}
+}
+
+func TestLimiterTierSwitchKeepsTokens(t *testing.T) {
+	limiter, clock := newTestLimiter(Config{
+		DefaultTier: "free",
+		Tiers: map[string]Limits{
+			"free": {RequestsPerSecond: 1, Burst: 5},
+			"pro":  {RequestsPerSecond: 1, Burst: 10},
+		},
+	})
+
+	if got := acquireN(limiter, Subject{Key: "a", Tier: "free"}, "m", 5); got != 5 {
+		t.Fatalf("allowed = %d, want 5", got)
+	}
+
+	// 交替使用不同分级的凭据不会重新装满令牌桶
+	for i := 0; i < 4; i++ {
+		tier := []string{"pro", "free"}[i%2]
+		if _, err := limiter.Acquire(Subject{Key: "a", Tier: tier}, "m"); err == nil {
+			t.Fatalf("switch %d to %s got a fresh burst", i, tier)
+		}
+	}
+
+	// 切换分级时沿用原令牌桶中的令牌：free已补满5个
+	clock.Advance(time.Minute)
+	if got := acquireN(limiter, Subject{Key: "a", Tier: "pro"}, "m", 20); got != 5 {
+		t.Errorf("allowed after switching to pro = %d, want 5 carried over from free", got)
+	}
+
+	// 之后按新分级的容量补充
+	clock.Advance(time.Minute)
+	if got := acquireN(limiter, Subject{Key: "a", Tier: "pro"}, "m", 20); got != 10 {
+		t.Errorf("allowed on pro after refill = %d, want 10", got)
+	}
+}
+
+func TestLimiterSetConfig(t *testing.T) {
+	limiter, _ := newTestLimiter(Config{
+		DefaultTier: "free",
+		Tiers:       map[string]Limits{"free": {RequestsPerSecond: 1, Burst: 4, MaxConcurrent: 1}},
+		Models:      map[string]Limits{"m": {MaxConcurrent: 1}},
+	})
+	subject := Subject{Key: "a"}
+
+	release, err := limiter.Acquire(subject, "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 提高限制后在途请求仍然计数，剩余令牌不会重新装满
+	limiter.SetConfig(Config{
+		DefaultTier: "free",
+		Tiers:       map[string]Limits{"free": {RequestsPerSecond: 1, Burst: 8, MaxConcurrent: 2}},
+	})
+	second, err := limiter.Acquire(subject, "m")
+	if err != nil {
+		t.Fatalf("Acquire() after raising the limit: %v", err)
+	}
+	_, err = limiter.Acquire(subject, "m")
+	limitError(t, err, ScopeSubject, ReasonConcurrency)
+	release()
+	second()
+
+	if got := acquireN(limiter, subject, "m", 10); got != 2 {
+		t.Errorf("allowed = %d, want the 2 tokens left from the old bucket", got)
+	}
+
+	// 删除的模型限制不再生效
+	if _, ok := limiter.models["m"]; ok {
+		t.Error("model state kept after its limit was removed")
+	}
+}
+
+func TestLimiterSweepsIdleSubjects(t *testing.T) {
+	limiter, clock := newTestLimiter(Config{
+		DefaultTier: "free",
+		Tiers:       map[string]Limits{"free": {RequestsPerSecond: 1, Burst: 1}},
+	})
+
+	acquireN(limiter, Subject{Key: "idle"}, "m", 1)
+	busy, err := limiter.Acquire(Subject{Key: "busy"}, "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy()
+
+	clock.Advance(idleTTL + sweepInterval)
+	acquireN(limiter, Subject{Key: "other"}, "m", 1)
+
+	if _, ok := limiter.subjects["idle"]; ok {
+		t.Error("idle subject not swept")
+	}
+	if _, ok := limiter.subjects["busy"]; !ok {
+		t.Error("subject with in-flight requests swept")
+	}
+}
@@ -0,0 +1,194 @@
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// encryptedFileVersion 加密文件格式版本
+	encryptedFileVersion = 1
+	// keySize AES-256密钥长度
+	keySize = 32
+)
+
+// encryptedDocument 加密文件格式，data为密钥表（JSON对象）的AES-256-GCM密文
+type encryptedDocument struct {
+	Version int    `json:"version"`
+	Cipher  string `json:"cipher"`
+	Nonce   string `json:"nonce"`
+	Data    string `json:"data"`
+}
+
+// GenerateKey 生成base64编码的随机加密密钥
+func GenerateKey() (string, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// ParseKey 解析base64编码的32字节加密密钥
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secrets key: %w", err)
+	}
+	if len(key) != keySize {
+		return nil, fmt.Errorf("invalid secrets key: must be %d bytes, got %d", keySize, len(key))
+	}
+	return key, nil
+}
+
+// ReadEncryptedFile 读取并解密密钥文件
+func ReadEncryptedFile(path string, key []byte) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var document encryptedDocument
+	if err := json.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if document.Version != encryptedFileVersion {
+		return nil, fmt.Errorf("unsupported secrets file version %d", document.Version)
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(document.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid nonce: %w", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(document.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data: %w", err)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce size %d", len(nonce))
+	}
+	plaintext, err := aead.Open(nil, nonce, data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: wrong key or corrupted file", path)
+	}
+
+	var values map[string]string
+	if err := json.Unmarshal(plaintext, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse decrypted secrets: %w", err)
+	}
+	return values, nil
+}
+
+// WriteEncryptedFile 加密并写入密钥文件（权限0600），先写临时文件再重命名，读取方不会看到写了一半的文件
+func WriteEncryptedFile(path string, key []byte, values map[string]string) error {
+	plaintext, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(encryptedDocument{
+		Version: encryptedFileVersion,
+		Cipher:  "AES-256-GCM",
+		Nonce:   base64.StdEncoding.EncodeToString(nonce),
+		Data:    base64.StdEncoding.EncodeToString(aead.Seal(nil, nonce, plaintext, nil)),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), ".secrets-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := temp.Write(append(content, '\n')); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(temp.Name(), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), path)
+}
+
+// newAEAD 创建AES-256-GCM
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptedFileProvider 从加密的本地密钥文件读取密钥，文件修改后重新解密
+type EncryptedFileProvider struct {
+	path string
+	key  []byte
+
+	mutex   sync.Mutex
+	modTime time.Time
+	size    int64
+	values  map[string]string
+}
+
+// NewEncryptedFileProvider 创建加密文件密钥来源，立即读取一次以尽早发现密钥或文件错误
+func NewEncryptedFileProvider(path string, key []byte) (*EncryptedFileProvider, error) {
+	p := &EncryptedFileProvider{path: path, key: key}
+	if _, _, err := p.Lookup(context.Background(), ""); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Name 实现Provider
+func (p *EncryptedFileProvider) Name() string {
+	return "encrypted_file"
+}
+
+// Lookup 实现Provider
+func (p *EncryptedFileProvider) Lookup(_ context.Context, name string) (string, bool, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return "", false, err
+	}
+	if p.values == nil || !info.ModTime().Equal(p.modTime) || info.Size() != p.size {
+		values, err := ReadEncryptedFile(p.path, p.key)
+		if err != nil {
+			return "", false, err
+		}
+		p.values, p.modTime, p.size = values, info.ModTime(), info.Size()
+	}
+
+	value, ok := p.values[name]
+	return value, ok, nil
+}
@@ -0,0 +1,182 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestKey 生成加密密钥
+func newTestKey(t *testing.T) []byte {
+	t.Helper()
+	encoded, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParseKey(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		wantErr bool
+	}{
+		{name: "valid", encoded: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="},
+		{name: "not base64", encoded: "not base64!", wantErr: true},
+		{name: "too short", encoded: "MDEyMzQ1Njc4OWFiY2RlZg==", wantErr: true},
+		{name: "empty", encoded: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := ParseKey(tt.encoded)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && len(key) != keySize {
+				t.Fatalf("key length = %d, want %d", len(key), keySize)
+			}
+		})
+	}
+}
+
+func TestEncryptedFileRoundTrip(t *testing.T) {
+	key := newTestKey(t)
+	path := filepath.Join(t.TempDir(), "secrets.enc.json")
+	values := map[string]string{"IMAGE_API_KEY": "sk-primary", "PROVIDER_BACKUP_API_KEYS": "b1:sk-backup"}
+
+	if err := WriteEncryptedFile(path, key, values); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(content), "sk-primary") {
+		t.Fatal("secrets file contains a plaintext value")
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("file mode = %o, want 600", perm)
+	}
+
+	decrypted, err := ReadEncryptedFile(path, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decrypted) != len(values) || decrypted["IMAGE_API_KEY"] != "sk-primary" || decrypted["PROVIDER_BACKUP_API_KEYS"] != "b1:sk-backup" {
+		t.Errorf("ReadEncryptedFile() = %v, want %v", decrypted, values)
+	}
+}
+
+func TestReadEncryptedFileErrors(t *testing.T) {
+	key := newTestKey(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secrets.enc.json")
+	if err := WriteEncryptedFile(path, key, map[string]string{"IMAGE_API_KEY": "sk-primary"}); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// modified 修改加密文件中的一项后写入新文件
+	modified := func(name string, modify func(document *encryptedDocument)) string {
+		var document encryptedDocument
+		if err := json.Unmarshal(content, &document); err != nil {
+			t.Fatal(err)
+		}
+		modify(&document)
+		data, _ := json.Marshal(document)
+		modifiedPath := filepath.Join(dir, name)
+		if err := os.WriteFile(modifiedPath, data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return modifiedPath
+	}
+
+	tests := []struct {
+		name string
+		path string
+		key  []byte
+	}{
+		{name: "wrong key", path: path, key: newTestKey(t)},
+		{name: "missing file", path: filepath.Join(dir, "missing.json"), key: key},
+		{name: "tampered data", path: modified("tampered.json", func(document *encryptedDocument) {
+			replacement := "A"
+			if strings.HasPrefix(document.Data, "A") {
+				replacement = "B"
+			}
+			document.Data = replacement + document.Data[1:]
+		}), key: key},
+		{name: "unsupported version", path: modified("version.json", func(document *encryptedDocument) { document.Version = 2 }), key: key},
+		{name: "bad nonce", path: modified("nonce.json", func(document *encryptedDocument) { document.Nonce = "AAAA" }), key: key},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if values, err := ReadEncryptedFile(tt.path, tt.key); err == nil {
+				t.Fatalf("ReadEncryptedFile() = %v, want an error", values)
+			}
+		})
+	}
+}
+
+func TestEncryptedFileProviderReloads(t *testing.T) {
+	key := newTestKey(t)
+	path := filepath.Join(t.TempDir(), "secrets.enc.json")
+	if err := WriteEncryptedFile(path, key, map[string]string{"PROVIDER_BACKUP_API_KEYS": "b1:old"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewEncryptedFileProvider(path, newTestKey(t)); err == nil {
+		t.Fatal("NewEncryptedFileProvider() accepted the wrong key")
+	}
+	provider, err := NewEncryptedFileProvider(path, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refresher := NewRefresher(Chain{provider}, map[string]string{"PROVIDER_BACKUP_API_KEYS": "b1:old", "IMAGE_API_KEY": "sk-env"})
+
+	// 未变化时不通知，找不到的密钥保留原值
+	changed, err := refresher.Refresh(context.Background())
+	if err != nil || len(changed) != 0 {
+		t.Fatalf("Refresh() = %v, %v, want no changes", changed, err)
+	}
+
+	// 重写文件后重新解密，轮换的密钥被通知
+	if err := WriteEncryptedFile(path, key, map[string]string{"PROVIDER_BACKUP_API_KEYS": "b2:new"}); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	changed, err = refresher.Refresh(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 1 || changed["PROVIDER_BACKUP_API_KEYS"] != "b2:new" {
+		t.Fatalf("Refresh() = %v, want the rotated provider keys", changed)
+	}
+
+	// 文件损坏时保留原值并返回错误
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if changed, err := refresher.Refresh(context.Background()); err == nil || len(changed) != 0 {
+		t.Fatalf("Refresh() with a corrupted file = %v, %v", changed, err)
+	}
+}
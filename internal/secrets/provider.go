@@ -0,0 +1,72 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Provider 密钥来源，按名称查找密钥；名称与对应的环境变量相同，如IMAGE_API_KEY
+type Provider interface {
+	// Name 来源名称，用于日志
+	Name() string
+	// Lookup 查找密钥，不存在时ok为false
+	Lookup(ctx context.Context, name string) (value string, ok bool, err error)
+}
+
+// EnvProvider 从环境变量读取密钥
+type EnvProvider struct{}
+
+// Name 实现Provider
+func (EnvProvider) Name() string {
+	return "env"
+}
+
+// Lookup 实现Provider
+func (EnvProvider) Lookup(_ context.Context, name string) (string, bool, error) {
+	value := os.Getenv(name)
+	if value != "" && os.Getenv(name+"_FILE") != "" {
+		return "", false, fmt.Errorf("%s and %s_FILE are mutually exclusive", name, name)
+	}
+	return value, value != "", nil
+}
+
+// FileProvider 读取NAME_FILE环境变量指向的文件（Docker/Kubernetes secrets），去掉结尾的换行
+// 每次查找都重新读取文件，挂载的Secret更新后下次刷新即可生效
+type FileProvider struct{}
+
+// Name 实现Provider
+func (FileProvider) Name() string {
+	return "file"
+}
+
+// Lookup 实现Provider
+func (FileProvider) Lookup(_ context.Context, name string) (string, bool, error) {
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return "", false, nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s_FILE: %w", name, err)
+	}
+	return strings.TrimRight(string(content), "\r\n"), true, nil
+}
+
+// Chain 按顺序查找密钥的多个来源，使用第一个找到的值
+type Chain []Provider
+
+// Lookup 查找密钥，返回找到密钥的来源名称
+func (c Chain) Lookup(ctx context.Context, name string) (value, source string, ok bool, err error) {
+	for _, provider := range c {
+		value, ok, err := provider.Lookup(ctx, name)
+		if err != nil {
+			return "", provider.Name(), false, err
+		}
+		if ok {
+			return value, provider.Name(), true, nil
+		}
+	}
+	return "", "", false, nil
+}
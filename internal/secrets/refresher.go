@@ -0,0 +1,77 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"sia/internal/metrics"
+)
+
+// Refresher 定期重新查找密钥，值变化时通知，用于不重启服务轮换密钥
+type Refresher struct {
+	providers Chain
+	values    map[string]string
+}
+
+// NewRefresher 创建刷新器，initial为当前生效的密钥值
+func NewRefresher(providers Chain, initial map[string]string) *Refresher {
+	values := make(map[string]string, len(initial))
+	for name, value := range initial {
+		values[name] = value
+	}
+	return &Refresher{providers: providers, values: values}
+}
+
+// Refresh 重新查找全部密钥，返回值发生变化的密钥；查找失败或找不到的密钥保留原值
+func (r *Refresher) Refresh(ctx context.Context) (map[string]string, error) {
+	changed := make(map[string]string)
+	var errs []error
+	for name, current := range r.values {
+		value, source, ok, err := r.providers.Lookup(ctx, name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s (%s): %w", name, source, err))
+			continue
+		}
+		if ok && value != current {
+			r.values[name] = value
+			changed[name] = value
+		}
+	}
+
+	switch {
+	case len(errs) > 0:
+		metrics.SecretRefreshes.WithLabelValues("failed").Inc()
+	case len(changed) > 0:
+		metrics.SecretRefreshes.WithLabelValues("rotated").Inc()
+	default:
+		metrics.SecretRefreshes.WithLabelValues("unchanged").Inc()
+	}
+	if len(errs) > 0 {
+		return changed, fmt.Errorf("failed to refresh secrets: %w", errors.Join(errs...))
+	}
+	return changed, nil
+}
+
+// Run 按间隔刷新密钥直到ctx结束，有密钥变化时调用onChange，刷新失败时调用onError
+func (r *Refresher) Run(ctx context.Context, interval time.Duration, onChange func(map[string]string), onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed, err := r.Refresh(ctx)
+		if err != nil {
+			onError(err)
+		}
+		if len(changed) > 0 {
+			onChange(changed)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"sia/internal/auth"
+	"sia/internal/service"
+	"sia/pkg/logger"
+)
+
+// requireScope HTTP鉴权中间件，authenticator为nil（未启用认证）时直接放行
+func requireScope(authenticator auth.Authenticator, scope string, logger *logger.Logger, next http.Handler) http.Handler {
+	if authenticator == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := authenticateHTTP(r, authenticator)
+		if err != nil {
+			logger.WarnContext(r.Context(), "HTTP request rejected by auth", "path", r.URL.Path, "error", err)
+			writeJSONError(w, http.StatusUnauthorized, "invalid credentials")
+			return
+		}
+		if !principal.HasScope(scope) {
+			writeJSONError(w, http.StatusForbidden, "missing required scope "+scope)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), principal)))
+	})
+}
+
+// authenticateHTTP 从Authorization头中认证HTTP请求
+func authenticateHTTP(r *http.Request, authenticator auth.Authenticator) (*auth.Principal, error) {
+	credential, err := auth.ParseAuthorization(r.Header.Get("Authorization"))
+	if err != nil {
+		return nil, err
+	}
+	return authenticator.Authenticate(r.Context(), credential)
+}
+
+// logLevelHandler 查询（GET）或调整（PUT）运行时日志级别
+func logLevelHandler(logger *logger.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var body struct {
+				Level string `json:"level"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				writeJSONError(w, http.StatusBadRequest, "invalid request body")
+				return
+			}
+			previous := logger.Level()
+			if err := logger.SetLevel(body.Level); err != nil {
+				writeJSONError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			logger.InfoContext(r.Context(), "Log level changed", "from", previous, "to", logger.Level())
+		default:
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]string{"level": logger.Level()})
+	})
+}
+
+// upstreamKeysHandler 查询上游API Key池中各密钥的使用情况和健康状态（不包含密钥本身）
+func upstreamKeysHandler(imageService *service.ImageService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"keys": imageService.UpstreamKeys()})
+	})
+}
+
+// writeJSON 输出JSON响应
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeJSONError 输出JSON错误响应
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
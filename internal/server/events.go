@@ -0,0 +1,147 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"sia/internal/domain"
+	"sia/internal/service"
+	"sia/pkg/logger"
+)
+
+const (
+	// sseHeartbeatInterval 心跳注释的发送间隔，避免代理因空闲断开连接
+	sseHeartbeatInterval = 15 * time.Second
+	// sseRetry 建议浏览器断线重连的间隔（毫秒）
+	sseRetry = 3000
+)
+
+// sseImage 图片事件的内容
+type sseImage struct {
+	TaskID        string `json:"task_id"`
+	Index         int    `json:"index"`
+	URL           string `json:"url,omitempty"`
+	B64JSON       string `json:"b64_json,omitempty"`
+	RevisedPrompt string `json:"revised_prompt,omitempty"`
+}
+
+// taskEventsHandler 以Server-Sent Events推送任务的状态变化、部分图片和最终结果
+// 事件ID为任务内的事件序号，断线重连时通过Last-Event-ID（或last_event_id查询参数）续传
+func taskEventsHandler(imageService *service.ImageService, logger *logger.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		afterSeq, err := lastEventID(r)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+
+		history, sub, err := imageService.SubscribeTask(r.Context(), r.PathValue("id"), afterSeq)
+		if err != nil {
+			st := status.Convert(err)
+			writeJSONError(w, http.StatusNotFound, st.Message())
+			return
+		}
+		defer sub.Close()
+
+		// 事件流的持续时间不受服务器写超时限制
+		rc := http.NewResponseController(w)
+		rc.SetWriteDeadline(time.Time{})
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintf(w, "retry: %d\n\n", sseRetry)
+
+		for _, event := range history {
+			if err := writeTaskEvent(w, imageService, event); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(sseHeartbeatInterval)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event, ok := <-sub.Events:
+				if !ok {
+					// 任务已结束，或订阅者跟不上被断开（客户端会带Last-Event-ID重连）
+					return
+				}
+				if err := writeTaskEvent(w, imageService, event); err != nil {
+					logger.DebugContext(r.Context(), "Task event stream closed", "task_id", event.TaskID, "error", err)
+					return
+				}
+			case <-heartbeat.C:
+				if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+					return
+				}
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	})
+}
+
+// writeTaskEvent 输出一个SSE事件
+func writeTaskEvent(w http.ResponseWriter, imageService *service.ImageService, event domain.TaskEvent) error {
+	var data []byte
+	var err error
+	if event.Type == domain.TaskEventImage && event.Image != nil {
+		data, err = json.Marshal(sseImage{
+			TaskID:        event.TaskID,
+			Index:         event.ImageIndex,
+			URL:           event.Image.URL,
+			B64JSON:       event.Image.B64JSON,
+			RevisedPrompt: event.Image.RevisedPrompt,
+		})
+	} else {
+		data, err = protojson.MarshalOptions{UseProtoNames: true}.Marshal(imageService.TaskEventPayload(event))
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Seq, event.Type, data)
+	return err
+}
+
+// lastEventID 读取客户端已收到的最后一个事件序号
+func lastEventID(r *http.Request) (int, error) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("last_event_id")
+	}
+	if value == "" {
+		return 0, nil
+	}
+	seq, err := strconv.Atoi(value)
+	if err != nil || seq < 0 {
+		return 0, fmt.Errorf("invalid Last-Event-ID %q", value)
+	}
+	return seq, nil
+}
+
+// tokenFromQuery 浏览器的EventSource无法设置请求头，允许通过access_token查询参数传入凭据
+func tokenFromQuery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token := r.URL.Query().Get("access_token"); token != "" && r.Header.Get("Authorization") == "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
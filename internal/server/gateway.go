@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/textproto"
+	"strconv"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	imagev1 "sia/api/image/v1"
+	"sia/api/openapi"
+	"sia/internal/config"
+	"sia/pkg/logger"
+)
+
+// gatewayForwardedHeaders 原样转发给gRPC服务的HTTP请求头（其余按grpc-gateway默认规则处理）
+var gatewayForwardedHeaders = map[string]bool{
+	"X-Request-Id": true,
+	"Traceparent":  true,
+	"Tracestate":   true,
+	"Baggage":      true,
+}
+
+// NewGateway 创建HTTP网关，包括ImageService的REST/JSON接口和OpenAI兼容接口
+// 网关通过本机gRPC端口转发请求，与gRPC客户端共用认证、限流、指标和错误映射；
+// Authorization头会作为authorization元数据转发
+func NewGateway(ctx context.Context, cfg *config.Config, logger *logger.Logger) (http.Handler, error) {
+	endpoint := fmt.Sprintf("127.0.0.1:%d", cfg.Server.GRPCPort)
+	conn, err := grpc.NewClient(endpoint,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxGRPCMessageSize), grpc.MaxCallSendMsgSize(maxGRPCMessageSize)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial gRPC endpoint %s: %w", endpoint, err)
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	rest := runtime.NewServeMux(
+		runtime.WithIncomingHeaderMatcher(gatewayIncomingHeader),
+		runtime.WithOutgoingHeaderMatcher(gatewayOutgoingHeader),
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true},
+			UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+		}),
+		runtime.WithErrorHandler(gatewayErrorHandler),
+	)
+	if err := imagev1.RegisterImageServiceHandler(ctx, rest, conn); err != nil {
+		return nil, fmt.Errorf("failed to register gateway: %w", err)
+	}
+
+	// OpenAI Images API兼容接口
+	openai := &openAIHandler{client: imagev1.NewImageServiceClient(conn)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/images/generations", openai.generations)
+	mux.HandleFunc("POST /v1/images/edits", openai.edits)
+	mux.Handle("/", rest)
+
+	logger.Info("HTTP gateway configured successfully", "grpc_endpoint", endpoint)
+
+	return mux, nil
+}
+
+// openAPIHandler 输出REST网关的OpenAPI文档
+func openAPIHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(openapi.ImageService)
+	})
+}
+
+// gatewayIncomingHeader 决定哪些HTTP请求头转为gRPC元数据
+func gatewayIncomingHeader(key string) (string, bool) {
+	if gatewayForwardedHeaders[textproto.CanonicalMIMEHeaderKey(key)] {
+		return key, true
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// gatewayOutgoingHeader 决定哪些gRPC响应头返回给HTTP客户端
+func gatewayOutgoingHeader(key string) (string, bool) {
+	switch key {
+	case requestIDHeader:
+		return textproto.CanonicalMIMEHeaderKey(key), true
+	case "content-type":
+		return "", false
+	}
+	return fmt.Sprintf("%s%s", runtime.MetadataHeaderPrefix, key), true
+}
+
+// gatewayErrorHandler 在默认错误映射的基础上，把RetryInfo转为Retry-After响应头
+func gatewayErrorHandler(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	if st, ok := status.FromError(err); ok {
+		setRetryAfter(w, st)
+	}
+	runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, w, r, err)
+}
+
+// setRetryAfter 状态中带有RetryInfo时设置Retry-After响应头（秒，至少为1）
+func setRetryAfter(w http.ResponseWriter, st *status.Status) {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			seconds := math.Ceil(info.GetRetryDelay().AsDuration().Seconds())
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Max(seconds, 1))))
+		}
+	}
+}
@@ -7,15 +7,23 @@ import (
 	"google.golang.org/grpc/keepalive"
 
 	imagev1 "sia/api/image/v1"
+	"sia/internal/auth"
 	"sia/internal/config"
 	"sia/internal/service"
 	"sia/pkg/logger"
 )
 
+// maxGRPCMessageSize gRPC消息的最大大小（默认4MB）：请求需要容纳OpenAI兼容接口上传的图片（最大maxOpenAIUploadSize）
+// 转为base64 data URL之后的大小，响应需要容纳b64_json格式返回的图片；网关到本机gRPC端口的连接使用相同的限制
+const maxGRPCMessageSize = 64 << 20
+
 // NewGRPCServer 创建gRPC服务器
-func NewGRPCServer(cfg *config.Config, logger *logger.Logger, imageService *service.ImageService) *grpc.Server {
+// authenticator为nil时不启用认证
+func NewGRPCServer(cfg *config.Config, logger *logger.Logger, imageService *service.ImageService, authenticator auth.Authenticator) *grpc.Server {
 	// gRPC服务器选项
 	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(maxGRPCMessageSize),
+		grpc.MaxSendMsgSize(maxGRPCMessageSize),
 		grpc.KeepaliveParams(keepalive.ServerParameters{
 			MaxConnectionIdle:     15 * time.Second,
 			MaxConnectionAge:      30 * time.Second,
@@ -29,6 +37,20 @@ func NewGRPCServer(cfg *config.Config, logger *logger.Logger, imageService *serv
 		}),
 	}
 
+	// 拦截器：追踪和指标在最外层，认证失败的请求也会被记录
+	unaryInterceptors := []grpc.UnaryServerInterceptor{tracingUnaryInterceptor(), requestIDUnaryInterceptor(), metricsUnaryInterceptor()}
+	streamInterceptors := []grpc.StreamServerInterceptor{tracingStreamInterceptor(), requestIDStreamInterceptor(), metricsStreamInterceptor()}
+	if authenticator != nil {
+		unaryInterceptors = append(unaryInterceptors, authUnaryInterceptor(authenticator, logger))
+		streamInterceptors = append(streamInterceptors, authStreamInterceptor(authenticator, logger))
+	} else {
+		logger.Warn("gRPC authentication is disabled")
+	}
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	)
+
 	// 创建gRPC服务器
 	server := grpc.NewServer(opts...)
 
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	imagev1 "sia/api/image/v1"
+	sihealth "sia/internal/health"
+	"sia/pkg/logger"
+)
+
+// readyHandler 就绪检查端点，任一检查项失败时返回503
+func readyHandler(checker *sihealth.Checker) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := checker.Check(r.Context())
+
+		status := "ready"
+		code := http.StatusOK
+		if !report.Ready {
+			status = "not_ready"
+			code = http.StatusServiceUnavailable
+		}
+
+		checks := make(map[string]interface{}, len(report.Checks))
+		for _, result := range report.Checks {
+			check := map[string]interface{}{
+				"status":     result.Status,
+				"latency_ms": result.Latency.Milliseconds(),
+				"checked_at": result.CheckedAt.UTC().Format(time.RFC3339),
+			}
+			if result.Message != "" {
+				check["message"] = result.Message
+			}
+			checks[result.Name] = check
+		}
+
+		writeJSON(w, code, map[string]interface{}{
+			"status":    status,
+			"checks":    checks,
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+		})
+	})
+}
+
+// WatchHealth 定期执行就绪检查并同步到gRPC健康检查服务，直到ctx结束
+func WatchHealth(ctx context.Context, healthServer *health.Server, checker *sihealth.Checker, interval time.Duration, logger *logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := grpc_health_v1.HealthCheckResponse_UNKNOWN
+	for {
+		servingStatus := grpc_health_v1.HealthCheckResponse_SERVING
+		report := checker.Check(ctx)
+		if !report.Ready {
+			servingStatus = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+		}
+
+		if servingStatus != last {
+			logger.Info("Serving status changed", "status", servingStatus.String(), "checks", failingChecks(report))
+			last = servingStatus
+		}
+		healthServer.SetServingStatus("", servingStatus)
+		healthServer.SetServingStatus(imagev1.ImageService_ServiceDesc.ServiceName, servingStatus)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// failingChecks 汇总失败的检查项
+func failingChecks(report sihealth.Report) map[string]string {
+	failing := make(map[string]string)
+	for _, result := range report.Checks {
+		if result.Status != sihealth.StatusOK {
+			failing[result.Name] = result.Message
+		}
+	}
+	return failing
+}
@@ -5,12 +5,17 @@ import (
 	"net/http"
 	"time"
 
+	"sia/internal/auth"
 	"sia/internal/config"
+	"sia/internal/metrics"
+	"sia/internal/service"
+	"sia/internal/storage"
 	"sia/pkg/logger"
 )
 
 // NewHTTPServer 创建HTTP服务器
-func NewHTTPServer(cfg *config.Config, logger *logger.Logger) *http.Server {
+// authenticator为nil时管理端点不做认证，gateway为nil时不提供REST接口
+func NewHTTPServer(cfg *config.Config, logger *logger.Logger, authenticator auth.Authenticator, imageService *service.ImageService, gateway http.Handler) *http.Server {
 	mux := http.NewServeMux()
 
 	// 健康检查端点
@@ -28,30 +33,35 @@ func NewHTTPServer(cfg *config.Config, logger *logger.Logger) *http.Server {
 		json.NewEncoder(w).Encode(response)
 	})
 
-	// 就绪检查端点
-	mux.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
-		response := map[string]interface{}{
-			"status":    "ready",
-			"timestamp": time.Now().UTC().Format(time.RFC3339),
-		}
+	// 就绪检查端点（反映上游可达性和内部饱和度）
+	mux.Handle("/ready", readyHandler(imageService.Readiness()))
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(response)
-	})
+	// 指标端点（Prometheus文本格式）
+	mux.Handle("/metrics", metrics.Handler())
 
-	// 指标端点（可以集成Prometheus）
-	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
-		// 这里可以集成Prometheus指标
-		w.Header().Set("Content-Type", "text/plain")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("# Metrics endpoint - integrate with Prometheus\n"))
-	})
+	// 任务事件流（SSE）
+	mux.Handle("GET /v1/tasks/{id}/events", tokenFromQuery(requireScope(authenticator, auth.ScopeTasksRead, logger, taskEventsHandler(imageService, logger))))
+
+	// 存储中的图片（签名地址即凭据，不需要认证）
+	if store := imageService.BlobStore(); store != nil {
+		mux.Handle("GET "+storage.ImagePathPrefix+"{key...}", newImageHandler(store, imageService.URLSigner, cfg.Storage.ResizeWidths, logger))
+	}
+
+	// REST/JSON网关（认证由gRPC侧完成）
+	if gateway != nil {
+		mux.Handle("/v1/", gateway)
+		mux.Handle("/openapi.json", openAPIHandler())
+	}
+
+	// 管理端点（需要admin权限）
+	mux.Handle("/admin/log-level", requireScope(authenticator, auth.ScopeAdmin, logger, logLevelHandler(logger)))
+	mux.Handle("GET /admin/upstream-keys", requireScope(authenticator, auth.ScopeAdmin, logger, upstreamKeysHandler(imageService)))
 
 	server := &http.Server{
-		Handler:      mux,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
+		Handler:     mux,
+		ReadTimeout: 15 * time.Second,
+		// 同步生成接口需要等待上游返回
+		WriteTimeout: time.Duration(cfg.Image.Timeout+15) * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
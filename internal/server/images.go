@@ -0,0 +1,144 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"sia/internal/storage"
+	"sia/pkg/logger"
+)
+
+// imageHandler 提供存储中的图片，请求必须带有效的签名（expires、kid、sig）
+// 支持Range、ETag/If-None-Match，w参数可以缩放到允许的宽度
+type imageHandler struct {
+	store  storage.BlobStore
+	signer func() *storage.URLSigner // 返回当前的签名器，签名密钥可以热加载
+	widths map[int]bool
+	logger *logger.Logger
+	// resizeSlots 限制同时进行的缩放数量，避免大量缩放请求占满CPU
+	resizeSlots chan struct{}
+}
+
+// newImageHandler 创建图片访问处理器
+func newImageHandler(store storage.BlobStore, signer func() *storage.URLSigner, widths []int, logger *logger.Logger) *imageHandler {
+	allowed := make(map[int]bool, len(widths))
+	for _, width := range widths {
+		allowed[width] = true
+	}
+	return &imageHandler{
+		store:       store,
+		signer:      signer,
+		widths:      allowed,
+		logger:      logger,
+		resizeSlots: make(chan struct{}, runtime.NumCPU()),
+	}
+}
+
+// ServeHTTP 实现http.Handler
+func (h *imageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	query := r.URL.Query()
+
+	// 未配置签名密钥时不提供图片
+	signer := h.signer()
+	if signer == nil {
+		writeJSONError(w, http.StatusNotFound, "image not found")
+		return
+	}
+
+	expires, err := signer.Verify(key, query)
+	if err != nil {
+		writeJSONError(w, http.StatusForbidden, err.Error())
+		return
+	}
+
+	width := 0
+	if value := query.Get("w"); value != "" {
+		width, err = strconv.Atoi(value)
+		if err != nil || !h.widths[width] {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("width %q is not allowed", value))
+			return
+		}
+	}
+
+	object, info, err := h.store.Open(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			writeJSONError(w, http.StatusNotFound, "image not found")
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "Failed to open stored image", "key", key, "error", err)
+		writeJSONError(w, http.StatusBadGateway, "failed to read image")
+		return
+	}
+	defer object.Close()
+
+	content := io.ReadSeeker(object)
+	contentType := info.ContentType
+	etag := imageETag(info)
+	if width > 0 {
+		content, contentType, err = h.resize(r, object, width)
+		switch {
+		case errors.Is(err, storage.ErrResizeUnsupported):
+			// 无法解码的格式（如WebP）返回原图
+			content = object
+			object.Seek(0, io.SeekStart)
+		case err != nil:
+			h.logger.ErrorContext(r.Context(), "Failed to resize image", "key", key, "width", width, "error", err)
+			writeJSONError(w, http.StatusInternalServerError, "failed to resize image")
+			return
+		default:
+			etag = strings.TrimSuffix(etag, `"`) + "-w" + strconv.Itoa(width) + `"`
+		}
+	}
+
+	// 内容不可变，但缓存时间不超过签名的有效期
+	maxAge := int(time.Until(expires).Seconds())
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	w.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d, immutable", maxAge))
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+
+	// ServeContent处理Range、If-None-Match、If-Modified-Since和HEAD
+	http.ServeContent(w, r, path.Base(key), info.ModTime, content)
+}
+
+// resize 读取原图并缩放到指定宽度
+func (h *imageHandler) resize(r *http.Request, object io.Reader, width int) (io.ReadSeeker, string, error) {
+	select {
+	case h.resizeSlots <- struct{}{}:
+		defer func() { <-h.resizeSlots }()
+	case <-r.Context().Done():
+		return nil, "", r.Context().Err()
+	}
+
+	data, err := io.ReadAll(object)
+	if err != nil {
+		return nil, "", err
+	}
+	resized, contentType, err := storage.Resize(data, width)
+	if err != nil {
+		return nil, "", err
+	}
+	return bytes.NewReader(resized), contentType, nil
+}
+
+// imageETag 生成强ETag：内容寻址的对象键直接使用文件名中的SHA-256，否则使用大小和修改时间
+func imageETag(info *storage.ObjectInfo) string {
+	name := strings.TrimSuffix(path.Base(info.Key), path.Ext(info.Key))
+	if len(name) == 64 && strings.Trim(name, "0123456789abcdef") == "" {
+		return `"` + name + `"`
+	}
+	return fmt.Sprintf(`"%x-%x"`, info.Size, info.ModTime.UnixNano())
+}
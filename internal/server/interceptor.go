@@ -0,0 +1,279 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	imagev1 "sia/api/image/v1"
+	"sia/internal/auth"
+	"sia/internal/metrics"
+	"sia/internal/tracing"
+	"sia/pkg/logger"
+)
+
+// methodScopes 各RPC方法所需的权限范围
+// ImageService中未列出的方法默认需要admin权限
+var methodScopes = map[string]string{
+	imagev1.ImageService_GenerateImage_FullMethodName:            auth.ScopeGenerate,
+	imagev1.ImageService_GenerateSequentialImages_FullMethodName: auth.ScopeGenerate,
+	imagev1.ImageService_GenerateImageAsync_FullMethodName:       auth.ScopeAsync,
+	imagev1.ImageService_GetImageTask_FullMethodName:             auth.ScopeTasksRead,
+	imagev1.ImageService_GetUsage_FullMethodName:                 auth.ScopeUsageRead,
+	imagev1.ImageService_EstimateCost_FullMethodName:             auth.ScopeGenerate,
+	imagev1.ImageService_ListModels_FullMethodName:               auth.ScopeGenerate,
+}
+
+// publicMethods 无需认证的方法
+var publicMethods = map[string]bool{
+	imagev1.ImageService_HealthCheck_FullMethodName: true,
+}
+
+// publicServicePrefixes 无需认证的服务（健康检查、反射）
+var publicServicePrefixes = []string{
+	"/grpc.health.v1.Health/",
+	"/grpc.reflection.v1.ServerReflection/",
+	"/grpc.reflection.v1alpha.ServerReflection/",
+}
+
+// isPublicMethod 判断方法是否免认证
+func isPublicMethod(fullMethod string) bool {
+	if publicMethods[fullMethod] {
+		return true
+	}
+	for _, prefix := range publicServicePrefixes {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// requiredScope 获取方法所需的权限范围
+func requiredScope(fullMethod string) string {
+	if scope, ok := methodScopes[fullMethod]; ok {
+		return scope
+	}
+	return auth.ScopeAdmin
+}
+
+// authorize 认证并鉴权，返回附加了主体的上下文
+func authorize(ctx context.Context, authenticator auth.Authenticator, fullMethod string) (context.Context, error) {
+	if isPublicMethod(fullMethod) {
+		return ctx, nil
+	}
+
+	var header string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			header = values[0]
+		}
+	}
+
+	credential, err := auth.ParseAuthorization(header)
+	if err != nil {
+		return nil, err
+	}
+
+	principal, err := authenticator.Authenticate(ctx, credential)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := requiredScope(fullMethod)
+	if !principal.HasScope(scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "missing required scope %q", scope)
+	}
+
+	return auth.NewContext(ctx, principal), nil
+}
+
+// authError 将认证错误转换为gRPC状态，不向调用方暴露具体原因
+func authError(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, auth.ErrMissingCredentials) {
+		return status.Error(codes.Unauthenticated, "missing credentials")
+	}
+	return status.Error(codes.Unauthenticated, "invalid credentials")
+}
+
+// authUnaryInterceptor 一元调用认证拦截器
+func authUnaryInterceptor(authenticator auth.Authenticator, logger *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		authCtx, err := authorize(ctx, authenticator, info.FullMethod)
+		if err != nil {
+			logger.WarnContext(ctx, "Request rejected by auth", "method", info.FullMethod, "error", err)
+			return nil, authError(err)
+		}
+		return handler(authCtx, req)
+	}
+}
+
+// authStreamInterceptor 流式调用认证拦截器
+func authStreamInterceptor(authenticator auth.Authenticator, logger *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		authCtx, err := authorize(ss.Context(), authenticator, info.FullMethod)
+		if err != nil {
+			logger.WarnContext(ss.Context(), "Stream rejected by auth", "method", info.FullMethod, "error", err)
+			return authError(err)
+		}
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: authCtx})
+	}
+}
+
+// tracingUnaryInterceptor 一元调用追踪拦截器，从元数据中提取trace上下文并创建服务端span
+func tracingUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, span := startRPCSpan(ctx, info.FullMethod)
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+		endRPCSpan(span, err)
+		return resp, err
+	}
+}
+
+// tracingStreamInterceptor 流式调用追踪拦截器
+func tracingStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := startRPCSpan(ss.Context(), info.FullMethod)
+		defer span.End()
+
+		err := handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+		endRPCSpan(span, err)
+		return err
+	}
+}
+
+// startRPCSpan 提取调用方的trace上下文并创建服务端span
+func startRPCSpan(ctx context.Context, fullMethod string) (context.Context, trace.Span) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, tracing.MetadataCarrier(md))
+	}
+
+	service, method := splitFullMethod(fullMethod)
+	return tracing.Start(ctx, strings.TrimPrefix(fullMethod, "/"),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.RPCSystemGRPC,
+			semconv.RPCService(service),
+			semconv.RPCMethod(method),
+		),
+	)
+}
+
+// endRPCSpan 记录RPC结果
+func endRPCSpan(span trace.Span, err error) {
+	code := status.Code(err)
+	span.SetAttributes(semconv.RPCGRPCStatusCodeKey.Int(int(code)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, code.String())
+	}
+}
+
+// splitFullMethod 拆分"/package.Service/Method"形式的方法名
+func splitFullMethod(fullMethod string) (string, string) {
+	name := strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return "", name
+}
+
+// requestIDHeader 请求ID元数据键
+const requestIDHeader = "x-request-id"
+
+// requestIDUnaryInterceptor 一元调用请求ID拦截器
+// 优先使用调用方传入的x-request-id，否则生成新的ID；请求ID写入上下文并通过响应头返回
+func requestIDUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = withRequestID(ctx)
+		grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, logger.RequestIDFromContext(ctx)))
+		return handler(ctx, req)
+	}
+}
+
+// requestIDStreamInterceptor 流式调用请求ID拦截器
+func requestIDStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := withRequestID(ss.Context())
+		ss.SetHeader(metadata.Pairs(requestIDHeader, logger.RequestIDFromContext(ctx)))
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// withRequestID 从元数据中读取或生成请求ID并附加到上下文
+func withRequestID(ctx context.Context) context.Context {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDHeader); len(values) > 0 && len(values[0]) <= 128 {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("request.id", requestID))
+	return logger.WithRequestID(ctx, requestID)
+}
+
+// newRequestID 生成随机请求ID
+func newRequestID() string {
+	buf := make([]byte, 12)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// metricsUnaryInterceptor 一元调用指标拦截器，记录请求数与耗时
+func metricsUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		observeRPC(info.FullMethod, err, start)
+		return resp, err
+	}
+}
+
+// metricsStreamInterceptor 流式调用指标拦截器，记录请求数与耗时
+func metricsStreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		observeRPC(info.FullMethod, err, start)
+		return err
+	}
+}
+
+// observeRPC 记录一次RPC的指标
+func observeRPC(method string, err error, start time.Time) {
+	code := status.Code(err).String()
+	metrics.RPCRequests.WithLabelValues(method, code).Inc()
+	metrics.RPCDuration.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+}
+
+// wrappedStream 替换上下文的服务端流
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context 返回替换后的上下文
+func (w *wrappedStream) Context() context.Context {
+	return w.ctx
+}
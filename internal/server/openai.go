@@ -0,0 +1,321 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	imagev1 "sia/api/image/v1"
+)
+
+const (
+	// maxOpenAIUploadSize edits接口multipart请求体的最大大小
+	maxOpenAIUploadSize = 32 << 20
+	// maxOpenAIJSONSize JSON请求体的最大大小（图片以URL或data URL传入）
+	maxOpenAIJSONSize = maxOpenAIUploadSize * 4 / 3
+)
+
+// openAIModels OpenAI的模型名，使用默认模型代替，便于现有SDK只改base URL即可接入
+var openAIModels = map[string]bool{
+	"dall-e-2":    true,
+	"dall-e-3":    true,
+	"gpt-image-1": true,
+}
+
+// openAIImageRequest OpenAI Images API请求（generations与edits共用）
+type openAIImageRequest struct {
+	Model          string     `json:"model"`
+	Prompt         string     `json:"prompt"`
+	N              int        `json:"n"`
+	Size           string     `json:"size"`
+	ResponseFormat string     `json:"response_format"`
+	Image          stringList `json:"image"`
+	User           string     `json:"user"`
+	Watermark      bool       `json:"watermark"` // 扩展字段，OpenAI没有该参数
+}
+
+// stringList 兼容单个字符串和字符串数组的JSON字段
+type stringList []string
+
+// UnmarshalJSON 实现json.Unmarshaler
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = stringList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("must be a string or an array of strings")
+	}
+	*l = list
+	return nil
+}
+
+// openAIImageResponse OpenAI Images API响应
+type openAIImageResponse struct {
+	Created int64         `json:"created"`
+	Data    []openAIImage `json:"data"`
+	Usage   *openAIUsage  `json:"usage,omitempty"`
+}
+
+// openAIImage 单张图片
+type openAIImage struct {
+	URL           string `json:"url,omitempty"`
+	B64JSON       string `json:"b64_json,omitempty"`
+	RevisedPrompt string `json:"revised_prompt,omitempty"`
+}
+
+// openAIUsage 用量（上游不返回输入token数，恒为0）
+type openAIUsage struct {
+	InputTokens  int32 `json:"input_tokens"`
+	OutputTokens int32 `json:"output_tokens"`
+	TotalTokens  int32 `json:"total_tokens"`
+}
+
+// openAIHandler OpenAI Images API兼容接口，请求转换为ImageService调用
+type openAIHandler struct {
+	client imagev1.ImageServiceClient
+}
+
+// generations 处理POST /v1/images/generations
+func (h *openAIHandler) generations(w http.ResponseWriter, r *http.Request) {
+	var req openAIImageRequest
+	r.Body = http.MaxBytesReader(w, r.Body, maxOpenAIJSONSize)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeOpenAIError(w, status.Errorf(codes.InvalidArgument, "invalid JSON body: %v", err))
+		return
+	}
+	h.generate(w, r, &req)
+}
+
+// edits 处理POST /v1/images/edits，支持multipart上传图片或JSON传入图片URL
+func (h *openAIHandler) edits(w http.ResponseWriter, r *http.Request) {
+	var req openAIImageRequest
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := parseOpenAIMultipart(r, &req); err != nil {
+			writeOpenAIError(w, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+	} else if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxOpenAIJSONSize)).Decode(&req); err != nil {
+		writeOpenAIError(w, status.Errorf(codes.InvalidArgument, "invalid JSON body: %v", err))
+		return
+	}
+
+	if len(req.Image) == 0 {
+		writeOpenAIError(w, status.Error(codes.InvalidArgument, "image is required"))
+		return
+	}
+	h.generate(w, r, &req)
+}
+
+// generate 调用ImageService生成图片
+// 只支持n为1：n张相互独立的图片需要n次生成，而同时发起的相同请求会被合并、命中结果缓存，得到的是同一张图片；
+// 序列图片（GenerateSequentialImages）生成的是一组相关的图片，语义不同，不用于代替
+func (h *openAIHandler) generate(w http.ResponseWriter, r *http.Request, req *openAIImageRequest) {
+	if req.N == 0 {
+		req.N = 1
+	}
+	if req.N != 1 {
+		writeOpenAIError(w, status.Error(codes.InvalidArgument, "n must be 1: send one request per image, or use POST /v1/images:generateSequential for a set of related images"))
+		return
+	}
+
+	model := req.Model
+	if openAIModels[model] {
+		model = ""
+	}
+	size := req.Size
+	if size == "auto" {
+		size = ""
+	}
+	var md map[string]string
+	if req.User != "" {
+		md = map[string]string{"user": req.User}
+	}
+
+	var header metadata.MD
+	resp, err := h.client.GenerateImage(forwardHTTPHeaders(r), &imagev1.GenerateImageRequest{
+		Prompt:         req.Prompt,
+		ImageUrls:      req.Image,
+		Model:          model,
+		Size:           size,
+		Watermark:      req.Watermark,
+		Metadata:       md,
+		ResponseFormat: req.ResponseFormat,
+	}, grpc.Header(&header))
+
+	if values := header.Get(requestIDHeader); len(values) > 0 {
+		w.Header().Set("X-Request-Id", values[0])
+	}
+	if err != nil {
+		writeOpenAIError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, convertOpenAIResponse(resp))
+}
+
+// forwardHTTPHeaders 将凭据、请求ID和trace上下文转为gRPC元数据
+func forwardHTTPHeaders(r *http.Request) context.Context {
+	md := metadata.MD{}
+	if value := r.Header.Get("Authorization"); value != "" {
+		md.Set("authorization", value)
+	}
+	for header := range gatewayForwardedHeaders {
+		if value := r.Header.Get(header); value != "" {
+			md.Set(strings.ToLower(header), value)
+		}
+	}
+	return metadata.NewOutgoingContext(r.Context(), md)
+}
+
+// parseOpenAIMultipart 解析multipart请求，上传的图片转为data URL
+func parseOpenAIMultipart(r *http.Request, req *openAIImageRequest) error {
+	r.Body = http.MaxBytesReader(nil, r.Body, maxOpenAIUploadSize)
+	if err := r.ParseMultipartForm(maxOpenAIUploadSize); err != nil {
+		return fmt.Errorf("invalid multipart body: %w", err)
+	}
+
+	req.Model = r.FormValue("model")
+	req.Prompt = r.FormValue("prompt")
+	req.Size = r.FormValue("size")
+	req.ResponseFormat = r.FormValue("response_format")
+	req.User = r.FormValue("user")
+	req.Watermark, _ = strconv.ParseBool(r.FormValue("watermark"))
+	if n := r.FormValue("n"); n != "" {
+		value, err := strconv.Atoi(n)
+		if err != nil {
+			return fmt.Errorf("n must be an integer")
+		}
+		req.N = value
+	}
+
+	for _, field := range []string{"image", "image[]"} {
+		// 图片也可以直接以URL的形式作为表单字段传入
+		req.Image = append(req.Image, r.MultipartForm.Value[field]...)
+		for _, file := range r.MultipartForm.File[field] {
+			dataURL, err := imageDataURL(file)
+			if err != nil {
+				return err
+			}
+			req.Image = append(req.Image, dataURL)
+		}
+	}
+	return nil
+}
+
+// imageDataURL 将上传的图片编码为data URL（上游支持base64格式的参考图）
+func imageDataURL(file *multipart.FileHeader) (string, error) {
+	f, err := file.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read image %s: %w", file.Filename, err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", fmt.Errorf("failed to read image %s: %w", file.Filename, err)
+	}
+
+	contentType := file.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType = http.DetectContentType(data)
+	}
+	if !strings.HasPrefix(contentType, "image/") {
+		return "", fmt.Errorf("image %s is not a supported image", file.Filename)
+	}
+
+	return "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// convertOpenAIResponse 转换为OpenAI响应格式
+func convertOpenAIResponse(resp *imagev1.GenerateImageResponse) *openAIImageResponse {
+	result := &openAIImageResponse{
+		Created: time.Now().Unix(),
+		Data:    make([]openAIImage, 0, len(resp.Images)),
+	}
+	if resp.CreatedAt != nil {
+		result.Created = resp.CreatedAt.AsTime().Unix()
+	}
+	for _, image := range resp.Images {
+		// 图片已持久化时返回稳定URL（或签名地址），上游的临时URL会过期
+		url := image.Url
+		if url != "" {
+			if stored := image.Stored.GetUrl(); stored != "" {
+				url = stored
+			} else if signed := image.Stored.GetSignedUrl(); signed != "" {
+				url = signed
+			}
+		}
+		result.Data = append(result.Data, openAIImage{
+			URL:           url,
+			B64JSON:       image.B64Json,
+			RevisedPrompt: image.RevisedPrompt,
+		})
+	}
+	if resp.Usage != nil {
+		result.Usage = &openAIUsage{
+			OutputTokens: resp.Usage.CompletionTokens,
+			TotalTokens:  resp.Usage.TotalTokens,
+		}
+	}
+	return result
+}
+
+// writeOpenAIError 按OpenAI的错误格式输出gRPC错误
+func writeOpenAIError(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	code := runtime.HTTPStatusFromCode(st.Code())
+	setRetryAfter(w, st)
+
+	writeJSON(w, code, map[string]interface{}{
+		"error": map[string]interface{}{
+			"message": st.Message(),
+			"type":    openAIErrorType(code),
+			"param":   nil,
+			"code":    snakeCase(st.Code().String()),
+		},
+	})
+}
+
+// openAIErrorType 根据HTTP状态码确定OpenAI错误类型
+func openAIErrorType(code int) string {
+	switch {
+	case code == http.StatusUnauthorized:
+		return "authentication_error"
+	case code == http.StatusForbidden:
+		return "permission_error"
+	case code == http.StatusTooManyRequests:
+		return "rate_limit_error"
+	case code >= http.StatusInternalServerError:
+		return "server_error"
+	default:
+		return "invalid_request_error"
+	}
+}
+
+// snakeCase 将gRPC状态码名称转换为snake_case（如ResourceExhausted转为resource_exhausted）
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToLower(b.String())
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	imagev1 "sia/api/image/v1"
+	"sia/internal/config"
+	"sia/internal/domain"
+	"sia/internal/metrics"
+)
+
+// newResultCache 根据配置创建结果缓存，未启用时返回nil
+func newResultCache(cfg config.CacheConfig) *domain.ResultCache {
+	if !cfg.Enabled {
+		return nil
+	}
+	return domain.NewResultCache(time.Duration(cfg.TTL)*time.Second, cfg.MaxEntries)
+}
+
+// cacheKey 返回请求的缓存键，未启用缓存或调用方要求跳过缓存时返回空字符串
+// 缓存按租户隔离：持久化的图片保存在各租户自己的前缀下
+func (s *ImageService) cacheKey(tenant string, req *domain.ImageGenerationRequest, mode imagev1.CacheMode) string {
+	if s.cache == nil {
+		return ""
+	}
+	if mode == imagev1.CacheMode_CACHE_MODE_BYPASS {
+		metrics.ResultCacheLookups.WithLabelValues("bypass").Inc()
+		return ""
+	}
+	return s.imageClient.RequestKey(tenant, req)
+}
+
+// cachedResult 查询缓存结果并确认引用的图片仍然存在，未命中时返回nil
+func (s *ImageService) cachedResult(ctx context.Context, key string, mode imagev1.CacheMode, format string) *domain.CachedResult {
+	if key == "" {
+		return nil
+	}
+	if mode == imagev1.CacheMode_CACHE_MODE_REFRESH {
+		metrics.ResultCacheLookups.WithLabelValues("refresh").Inc()
+		return nil
+	}
+
+	result, ok := s.cache.Get(key)
+	if !ok {
+		metrics.ResultCacheLookups.WithLabelValues("miss").Inc()
+		return nil
+	}
+
+	// 图片可能已被保留策略回收，此时丢弃缓存结果重新生成
+	if err := s.loadCachedImages(ctx, result.Response, format); err != nil {
+		s.logger.WarnContext(ctx, "Cached result is no longer available", "error", err)
+		s.cache.Delete(key)
+		metrics.ResultCacheLookups.WithLabelValues("stale").Inc()
+		return nil
+	}
+
+	metrics.ResultCacheLookups.WithLabelValues("hit").Inc()
+	return result
+}
+
+// loadCachedImages 补全缓存结果中的图片：url格式返回稳定URL，b64_json格式从存储读取图片内容
+func (s *ImageService) loadCachedImages(ctx context.Context, response *domain.ImageGenerationResponse, format string) error {
+	store := s.BlobStore()
+	for i := range response.Data {
+		image := &response.Data[i]
+		key := image.Stored.Key
+
+		if format != "b64_json" {
+			if _, err := store.Stat(ctx, key); err != nil {
+				return fmt.Errorf("failed to stat %s: %w", key, err)
+			}
+			image.URL = image.Stored.URL
+			continue
+		}
+
+		object, _, err := store.Open(ctx, key)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", key, err)
+		}
+		data, err := io.ReadAll(object)
+		object.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", key, err)
+		}
+		image.B64JSON = base64.StdEncoding.EncodeToString(data)
+	}
+	return nil
+}
+
+// cacheResult 缓存生成结果，只有全部图片都已持久化时才会缓存
+func (s *ImageService) cacheResult(ctx context.Context, key string, response *domain.ImageGenerationResponse) {
+	if key == "" {
+		return
+	}
+	if !s.cache.Put(key, response) {
+		s.logger.DebugContext(ctx, "Result not cached, some images were not persisted", "request_id", response.ID)
+	}
+}
+
+// convertCachedResponse 转换命中的缓存结果：没有请求上游，用量为0，不计入台账
+func (s *ImageService) convertCachedResponse(plan *costPlan, result *domain.CachedResult) *imagev1.GenerateImageResponse {
+	s.waiveCost(plan, result.Response)
+
+	grpcResponse := s.convertToGRPCResponse(result.Response)
+	grpcResponse.CacheHit = true
+	grpcResponse.CachedAt = timestamppb.New(result.CachedAt)
+	grpcResponse.Alias = plan.alias
+	return grpcResponse
+}
+
+// waiveCost 没有为本次请求调用上游时清空用量，实际费用为0
+func (s *ImageService) waiveCost(plan *costPlan, response *domain.ImageGenerationResponse) {
+	response.Usage = domain.Usage{}
+	response.Cost = &domain.Cost{
+		Currency:      s.pricing.Load().Currency,
+		Estimated:     plan.estimated,
+		Downgraded:    plan.downgraded,
+		DowngradeNote: plan.downgradeNote,
+	}
+}
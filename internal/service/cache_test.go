@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	imagev1 "sia/api/image/v1"
+	"sia/internal/domain"
+	"sia/internal/storage"
+	"sia/pkg/logger"
+)
+
+// newCacheService 创建使用本地存储和结果缓存的服务，返回写入的图片对象键
+func newCacheService(t *testing.T) (*ImageService, storage.BlobStore, string) {
+	t.Helper()
+	store, err := storage.NewLocalStore(t.TempDir(), "https://cdn.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := "acme/2024/05/01/a.png"
+	if err := store.Put(context.Background(), key, []byte("png"), "image/png", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &ImageService{
+		logger:    &logger.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))},
+		persister: storage.NewPersister(store, 1<<20, time.Second),
+		cache:     domain.NewResultCache(time.Hour, 10),
+	}
+	s.cache.Put("request", &domain.ImageGenerationResponse{
+		ID:   "resp",
+		Data: []domain.ImageData{{Stored: &domain.StoredImage{Key: key, URL: store.URL(key)}}},
+	})
+	return s, store, key
+}
+
+func TestCachedResult(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		mode    imagev1.CacheMode
+		format  string
+		wantHit bool
+	}{
+		{name: "url", key: "request", format: "url", wantHit: true},
+		{name: "b64_json", key: "request", format: "b64_json", wantHit: true},
+		{name: "miss", key: "other", format: "url"},
+		{name: "refresh", key: "request", mode: imagev1.CacheMode_CACHE_MODE_REFRESH, format: "url"},
+		{name: "disabled", key: "", format: "url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store, key := newCacheService(t)
+			result := s.cachedResult(context.Background(), tt.key, tt.mode, tt.format)
+			if (result != nil) != tt.wantHit {
+				t.Fatalf("cachedResult() hit = %v, want %v", result != nil, tt.wantHit)
+			}
+			if result == nil {
+				return
+			}
+
+			image := result.Response.Data[0]
+			switch tt.format {
+			case "b64_json":
+				if image.B64JSON != base64.StdEncoding.EncodeToString([]byte("png")) {
+					t.Errorf("B64JSON = %q", image.B64JSON)
+				}
+			default:
+				if image.URL != store.URL(key) {
+					t.Errorf("URL = %q, want %q", image.URL, store.URL(key))
+				}
+			}
+		})
+	}
+}
+
+func TestCachedResultDropsStaleBlobs(t *testing.T) {
+	for _, format := range []string{"url", "b64_json"} {
+		t.Run(format, func(t *testing.T) {
+			s, store, key := newCacheService(t)
+
+			// 图片被保留策略回收后缓存结果失效并被删除
+			if err := store.Delete(context.Background(), key); err != nil {
+				t.Fatal(err)
+			}
+			if result := s.cachedResult(context.Background(), "request", imagev1.CacheMode_CACHE_MODE_UNSPECIFIED, format); result != nil {
+				t.Fatalf("cachedResult() returned a result whose image was deleted: %+v", result.Response)
+			}
+			if _, ok := s.cache.Get("request"); ok {
+				t.Fatal("stale entry still cached")
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"sia/internal/domain"
+	"sia/internal/metrics"
+	"sia/internal/models"
+)
+
+// generate 请求上游生成图片，并记录用量、保存图片、缓存结果
+// 同一租户同时进行的相同请求合并为一次上游调用，shared表示结果由其他请求结算（本请求不计费）；
+// 限流对每个调用方分别生效；调用方取消只会放弃等待，不会取消其他调用方仍在等待的调用
+// release释放调用方的配额预留，由generate在结算之后（或调用结束后）调用；成功时plan.model更新为实际使用的模型
+func (s *ImageService) generate(ctx context.Context, method string, plan *costPlan, req *domain.ImageGenerationRequest, tags map[string]string, cacheKey string, release func()) (*domain.ImageGenerationResponse, bool, error) {
+	tenant, clientID, _ := callerIdentity(ctx)
+
+	releaseLimit, err := s.acquireLimit(ctx, plan.model)
+	if err != nil {
+		release()
+		plan.releaseBudget()
+		return nil, false, err
+	}
+
+	// 配额、预算预留和并发配额至少有一份保持到上游调用结束，用量记在实际收到结果的请求上
+	hold := domain.CoalesceHold{
+		Settle: func(response *domain.ImageGenerationResponse) {
+			plan.model = response.ResolvedModel
+			s.recordUsage(ctx, tenant, clientID, method, plan, response)
+		},
+		Release: func() {
+			releaseLimit()
+			release()
+			plan.releaseBudget()
+		},
+	}
+
+	var key string
+	if s.config.Load().Image.Coalesce {
+		key = s.coalesceKey(tenant, plan, req, tags, cacheKey)
+	}
+
+	response, shared, err := s.coalescer.Do(ctx, key, hold, func(callCtx context.Context) (*domain.ImageGenerationResponse, error) {
+		response, err := s.generateWithFailover(callCtx, plan, req, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		s.persistImages(callCtx, response, tenant, "", tags)
+		// 备用模型的结果不缓存，避免之后命中缓存的请求拿到的不是所请求模型的结果
+		if !response.FallbackUsed {
+			s.cacheResult(callCtx, cacheKey, response)
+		}
+		return response, nil
+	})
+	if err != nil {
+		return nil, shared, err
+	}
+	plan.model = response.ResolvedModel
+
+	if shared {
+		metrics.CoalescedRequests.WithLabelValues(plan.model).Inc()
+		s.logger.InfoContext(ctx, "Joined identical in-flight request", "request_id", response.ID)
+		s.waiveCost(plan, response)
+	}
+	return response, shared, nil
+}
+
+// coalesceKey 返回合并请求的键：除上游请求相同外，故障转移计划、是否写入结果缓存和请求元数据（保存图片时使用）也都相同的请求才合并
+func (s *ImageService) coalesceKey(tenant string, plan *costPlan, req *domain.ImageGenerationRequest, tags map[string]string, cacheKey string) string {
+	// 结构体字段顺序固定、map按键排序，json.Marshal的结果是确定的
+	data, _ := json.Marshal(struct {
+		Provider   string            `json:"provider"`
+		Fallbacks  []models.Fallback `json:"fallbacks"`
+		FailoverOn []string          `json:"failover_on"`
+		NoFailover bool              `json:"no_failover"`
+		Cache      bool              `json:"cache"`
+		Tags       map[string]string `json:"tags"`
+	}{plan.provider, plan.fallbacks, plan.failoverOn, plan.noFailover, cacheKey != "", tags})
+
+	hash := sha256.New()
+	hash.Write([]byte(s.imageClient.RequestKey(tenant, req)))
+	hash.Write([]byte{0})
+	hash.Write(data)
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"sia/internal/auth"
+	"sia/internal/config"
+	"sia/internal/domain"
+	"sia/internal/models"
+	"sia/internal/ratelimit"
+	"sia/internal/usage"
+	"sia/pkg/logger"
+)
+
+func TestCoalesceKey(t *testing.T) {
+	var calls int
+	s := &ImageService{imageClient: newUpstream(t, 0, &calls)}
+	req := &domain.ImageGenerationRequest{Model: "primary", Prompt: "cat"}
+	base := s.coalesceKey("acme", newFailoverPlan(), req, map[string]string{"kind": "preview"}, "cache")
+
+	tests := []struct {
+		name   string
+		tenant string
+		modify func(plan *costPlan)
+		req    *domain.ImageGenerationRequest
+		tags   map[string]string
+		cache  string
+		same   bool
+	}{
+		{name: "identical", same: true},
+		{name: "other cache key", cache: "other", same: true},
+		{name: "other tenant", tenant: "other"},
+		{name: "other prompt", req: &domain.ImageGenerationRequest{Model: "primary", Prompt: "dog"}},
+		{name: "other tags", tags: map[string]string{"kind": "final"}},
+		{name: "no tags", tags: map[string]string{}},
+		{name: "not cached", cache: "-"},
+		{name: "failover disabled", modify: func(plan *costPlan) { plan.noFailover = true }},
+		{name: "other fallbacks", modify: func(plan *costPlan) { plan.fallbacks = nil }},
+		{name: "other failover errors", modify: func(plan *costPlan) { plan.failoverOn = []string{models.FailoverRateLimited} }},
+		{name: "other provider", modify: func(plan *costPlan) { plan.provider = "backup" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tenant, plan, request, tags, cache := "acme", newFailoverPlan(), req, map[string]string{"kind": "preview"}, "cache"
+			if tt.tenant != "" {
+				tenant = tt.tenant
+			}
+			if tt.modify != nil {
+				tt.modify(plan)
+			}
+			if tt.req != nil {
+				request = tt.req
+			}
+			if tt.tags != nil {
+				tags = tt.tags
+			}
+			switch tt.cache {
+			case "":
+			case "-":
+				cache = ""
+			default:
+				cache = tt.cache
+			}
+
+			if got := s.coalesceKey(tenant, plan, request, tags, cache) == base; got != tt.same {
+				t.Errorf("same key = %v, want %v", got, tt.same)
+			}
+		})
+	}
+}
+
+// newBlockingUpstream 创建在release关闭前不返回结果的上游服务，started在第一次请求时关闭
+func newBlockingUpstream(t *testing.T, calls *atomic.Int32, started, release chan struct{}) *domain.ImageClient {
+	t.Helper()
+	var once sync.Once
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		once.Do(func() { close(started) })
+		<-release
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"type\":\"image_generation.partial_succeeded\",\"id\":\"req\",\"url\":\"http://example.com/0.png\"}\n\n")
+		fmt.Fprint(w, "data: {\"type\":\"image_generation.completed\",\"usage\":{\"generated_images\":1}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(server.Close)
+
+	return domain.NewImageClient(&domain.ImageClientConfig{
+		Keys:            []domain.UpstreamKey{{ID: "test", Key: "test"}},
+		BaseURL:         server.URL,
+		Timeout:         5,
+		BreakerFailures: 100,
+		BreakerCooldown: 1,
+	})
+}
+
+func TestGenerateCoalescesPerCallerLimits(t *testing.T) {
+	var calls atomic.Int32
+	started, release := make(chan struct{}), make(chan struct{})
+	client := newBlockingUpstream(t, &calls, started, release)
+
+	cfg := &config.Config{}
+	cfg.Image.Coalesce = true
+	cfg.RateLimit.KeyBy = "client"
+	ledger, _ := usage.NewLedger("")
+	s := &ImageService{
+		logger:      &logger.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))},
+		imageClient: client,
+		providers:   map[string]*domain.ImageClient{models.ProviderArk: client},
+		ledger:      ledger,
+		coalescer:   domain.NewCoalescer(),
+		// 每个客户端同时只能有一个请求
+		limiter: ratelimit.New(ratelimit.Config{DefaultTier: "default", Tiers: map[string]ratelimit.Limits{"default": {MaxConcurrent: 1}}}),
+	}
+	s.config.Store(cfg)
+	s.pricing.Store(usage.NewPriceTable("CNY", map[string]usage.Price{"primary": {PerImage: 0.2}}))
+
+	callerCtx := func(clientID string) context.Context {
+		return auth.NewContext(context.Background(), &auth.Principal{Tenant: "acme", ClientID: clientID})
+	}
+	generate := func(ctx context.Context) (*domain.ImageGenerationResponse, bool, error) {
+		plan := &costPlan{model: "primary", provider: models.ProviderArk, size: "2K", images: 1, estimated: 0.2}
+		return s.generate(ctx, "GenerateImage", plan, &domain.ImageGenerationRequest{Model: "primary", Prompt: "cat"}, nil, "", func() {})
+	}
+
+	type result struct {
+		shared bool
+		err    error
+	}
+	results := make(chan result, 2)
+	for _, clientID := range []string{"a", "b"} {
+		go func(ctx context.Context) {
+			_, shared, err := generate(ctx)
+			results <- result{shared, err}
+		}(callerCtx(clientID))
+		if clientID == "a" {
+			<-started
+		}
+	}
+	// 合并的请求不绕过限流：客户端a的并发名额仍被占用
+	if _, _, err := generate(callerCtx("a")); err == nil {
+		t.Fatal("a second request from the same client bypassed the concurrency limit by coalescing")
+	}
+
+	close(release)
+	var shared int
+	for i := 0; i < 2; i++ {
+		r := <-results
+		if r.err != nil {
+			t.Fatalf("generate() error = %v", r.err)
+		}
+		if r.shared {
+			shared++
+		}
+	}
+	// 客户端b通常会加入a的调用；无论是否合并，每次上游调用只有一个请求计费
+	if upstream := int(calls.Load()); upstream != 2-shared {
+		t.Errorf("upstream calls = %d with %d shared results", upstream, shared)
+	}
+	if summary := ledger.Summarize(usage.Filter{Tenant: "acme"}); summary.Total.Requests != int64(calls.Load()) {
+		t.Errorf("ledger requests = %d, want one per upstream call (%d)", summary.Total.Requests, calls.Load())
+	}
+
+	// 调用结束后各自的名额都已释放
+	if _, _, err := generate(callerCtx("a")); err != nil {
+		t.Fatalf("generate() after the shared call = %v", err)
+	}
+}
@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	imagev1 "sia/api/image/v1"
+	"sia/internal/config"
+	"sia/internal/domain"
+	"sia/internal/models"
+	"sia/internal/usage"
+)
+
+// 预算策略结果
+const (
+	budgetAllow     = "allow"
+	budgetDowngrade = "downgrade"
+	budgetReject    = "reject"
+)
+
+// costPlan 一次请求经预算策略调整后的执行计划
+type costPlan struct {
+	model         string
+	alias         string            // 请求使用的模型别名，未使用别名时为空
+	provider      string            // 模型的上游服务
+	fallbacks     []models.Fallback // 故障转移链，只在使用别名时设置
+	failoverOn    []string          // 触发故障转移的错误类型
+	noFailover    bool              // 调用方关闭了故障转移
+	size          string
+	images        int
+	estimated     float64
+	downgraded    bool
+	downgradeNote string
+
+	budgetMutex    sync.Mutex
+	budgetHolds    []func() // 为本次请求预留的预算（计划的费用及故障转移时备用模型多出的费用）的释放函数
+	budgetReleased bool
+}
+
+// holdBudget 记录为本次请求预留的预算，随releaseBudget一起释放；预算已经释放（调用方已经离开）时立即释放
+func (p *costPlan) holdBudget(release func()) {
+	p.budgetMutex.Lock()
+	if p.budgetReleased {
+		p.budgetMutex.Unlock()
+		release()
+		return
+	}
+	p.budgetHolds = append(p.budgetHolds, release)
+	p.budgetMutex.Unlock()
+}
+
+// releaseBudget 释放为本次请求预留的全部预算，重复调用无效
+func (p *costPlan) releaseBudget() {
+	p.budgetMutex.Lock()
+	holds := p.budgetHolds
+	p.budgetHolds, p.budgetReleased = nil, true
+	p.budgetMutex.Unlock()
+
+	for _, release := range holds {
+		release()
+	}
+}
+
+// newPriceTable 根据配置创建价格表：模型注册表中的价格打底，usage.pricing中配置的价格优先
+func newPriceTable(cfg *config.Config) *usage.PriceTable {
+	prices := make(map[string]usage.Price)
+	for name, model := range cfg.Models.Models {
+		if model.Pricing.PerImage > 0 || len(model.Pricing.Sizes) > 0 {
+			prices[name] = usage.Price(model.Pricing)
+		}
+	}
+	for name, price := range cfg.Usage.Pricing.Models {
+		// 未定价的条目（如未设置PRICING_PER_IMAGE时默认模型的条目）不覆盖注册表中的价格
+		if _, ok := prices[name]; ok && price.PerImage == 0 && len(price.Sizes) == 0 {
+			continue
+		}
+		prices[name] = usage.Price(price)
+	}
+	return usage.NewPriceTable(cfg.Usage.Pricing.Currency, prices)
+}
+
+// newBudget 根据配置创建预算检查器，未启用时返回nil
+func newBudget(ledger *usage.Ledger, cfg *config.Config) *usage.Budget {
+	if !cfg.Usage.Budget.Enabled {
+		return nil
+	}
+
+	tiers := make(map[string]usage.BudgetLimits, len(cfg.Usage.Budget.Tiers))
+	for name, tier := range cfg.Usage.Budget.Tiers {
+		tiers[name] = usage.BudgetLimits(tier)
+	}
+
+	tenants := make(map[string]usage.BudgetLimits, len(cfg.Usage.Budget.Tenants))
+	for name, tenant := range cfg.Usage.Budget.Tenants {
+		tenants[name] = usage.BudgetLimits(tenant)
+	}
+
+	return usage.NewBudget(ledger, usage.BudgetConfig{
+		DefaultTier: cfg.RateLimit.DefaultTier,
+		Tiers:       tiers,
+		Tenants:     tenants,
+	})
+}
+
+// planCost 估算费用并执行预算策略，按计划的费用预留预算
+// 请求结束后必须调用plan.releaseBudget（成功时在记录用量之后）
+func (s *ImageService) planCost(ctx context.Context, model, size string, images int, allowFewer bool) (*costPlan, error) {
+	plan := &costPlan{
+		model:     model,
+		size:      size,
+		images:    images,
+		estimated: s.pricing.Load().Cost(model, size, images),
+	}
+
+	budget := s.budget.Load()
+	if budget == nil {
+		return plan, nil
+	}
+
+	tenant, _, tier := callerIdentity(ctx)
+	release, err := budget.Reserve(tenant, tier, time.Now(), func(remaining float64, tightest *usage.BudgetStatus) (float64, error) {
+		if err := s.applyBudget(plan, remaining, tightest, allowFewer); err != nil {
+			return 0, err
+		}
+		return plan.estimated, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	plan.holdBudget(release)
+	return plan, nil
+}
+
+// applyBudget 按剩余预算调整执行计划
+// 超出预算时按配置拒绝，或依次尝试更便宜的尺寸、更少的图片数量（仅allowFewer时）
+func (s *ImageService) applyBudget(plan *costPlan, remaining float64, tightest *usage.BudgetStatus, allowFewer bool) error {
+	if tightest == nil || plan.estimated <= remaining {
+		return nil
+	}
+
+	budgetErr := &usage.BudgetError{BudgetStatus: *tightest, Estimate: plan.estimated}
+	if s.config.Load().Usage.Budget.Action != budgetDowngrade {
+		return budgetErr
+	}
+
+	model, size, images := plan.model, plan.size, plan.images
+	counts := []int{images}
+	if allowFewer {
+		for n := images - 1; n >= 1; n-- {
+			counts = append(counts, n)
+		}
+	}
+
+	sizes := s.cheaperSizes(model, size)
+	for _, n := range counts {
+		for _, candidate := range sizes {
+			cost := s.pricing.Load().Cost(model, candidate, n)
+			if cost > remaining {
+				continue
+			}
+
+			var notes []string
+			if candidate != size {
+				notes = append(notes, fmt.Sprintf("size %s -> %s", size, candidate))
+			}
+			if n != images {
+				notes = append(notes, fmt.Sprintf("images %d -> %d", images, n))
+			}
+
+			plan.size = candidate
+			plan.images = n
+			plan.estimated = cost
+			plan.downgraded = true
+			plan.downgradeNote = strings.Join(notes, ", ")
+			return nil
+		}
+	}
+
+	return budgetErr
+}
+
+// cheaperSizes 返回当前尺寸及配置的降级尺寸中模型支持且单价更低的部分，按配置顺序排列
+func (s *ImageService) cheaperSizes(model, size string) []string {
+	sizes := []string{size}
+	current, _ := s.pricing.Load().UnitPrice(model, size)
+	capabilities, _ := s.registry.Load().Get(model)
+	for _, candidate := range s.config.Load().Usage.Budget.DowngradeSizes {
+		if strings.EqualFold(candidate, size) || !capabilities.SupportsSize(candidate) {
+			continue
+		}
+		if price, _ := s.pricing.Load().UnitPrice(model, candidate); price < current {
+			sizes = append(sizes, candidate)
+		}
+	}
+	return sizes
+}
+
+// checkBudget 执行预算策略，超出预算时返回带RetryInfo的RESOURCE_EXHAUSTED状态
+func (s *ImageService) checkBudget(ctx context.Context, model, size string, images int, allowFewer bool) (*costPlan, error) {
+	plan, err := s.planCost(ctx, model, size, images, allowFewer)
+	if err == nil {
+		if plan.downgraded {
+			s.logger.InfoContext(ctx, "Request downgraded by budget", "model", model, "downgrade", plan.downgradeNote)
+		}
+		return plan, nil
+	}
+
+	tenant, _, _ := callerIdentity(ctx)
+	s.logger.WarnContext(ctx, "Request rejected by budget", "tenant", tenant, "error", err)
+
+	var budgetErr *usage.BudgetError
+	if !errors.As(err, &budgetErr) {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	st := status.New(codes.ResourceExhausted, budgetErr.Error())
+	detailed, detailErr := st.WithDetails(
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Until(budgetErr.ResetsAt))},
+		&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{
+			Subject:     "tenant:" + tenant,
+			Description: budgetErr.Error(),
+		}}},
+	)
+	if detailErr != nil {
+		return nil, st.Err()
+	}
+	return nil, detailed.Err()
+}
+
+// applyCost 按实际生成的图片数计算费用并写入响应
+func (s *ImageService) applyCost(plan *costPlan, response *domain.ImageGenerationResponse) {
+	response.Cost = &domain.Cost{
+		Currency:      s.pricing.Load().Currency,
+		Estimated:     plan.estimated,
+		Actual:        s.pricing.Load().Cost(plan.model, plan.size, response.Usage.GeneratedImages),
+		Downgraded:    plan.downgraded,
+		DowngradeNote: plan.downgradeNote,
+	}
+}
+
+// EstimateCost 估算请求费用并预检预算
+func (s *ImageService) EstimateCost(ctx context.Context, req *imagev1.EstimateCostRequest) (*imagev1.EstimateCostResponse, error) {
+	images := int(req.Images)
+	if images == 0 {
+		images = 1
+	}
+
+	model, _ := s.resolveModel(ctx, req.Model, nil)
+	size := s.getSize(req.Size)
+	if err := s.validateModel(models.Request{Model: model, Size: size, Sequential: req.Sequential, Images: images}); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	unitPrice, ok := s.pricing.Load().UnitPrice(model, size)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no price configured for model %q", model)
+	}
+
+	response := &imagev1.EstimateCostResponse{
+		Model:         model,
+		Size:          size,
+		Images:        int32(images),
+		Currency:      s.pricing.Load().Currency,
+		UnitPrice:     unitPrice,
+		EstimatedCost: s.pricing.Load().Cost(model, size, images),
+		BudgetAction:  budgetAllow,
+	}
+
+	// 只是预检，不为之后的请求保留预算
+	plan, err := s.planCost(ctx, model, size, images, req.Sequential)
+	if err == nil {
+		plan.releaseBudget()
+	}
+	var budgetErr *usage.BudgetError
+	switch {
+	case errors.As(err, &budgetErr):
+		response.BudgetAction = budgetReject
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	default:
+		if plan.downgraded {
+			response.BudgetAction = budgetDowngrade
+		}
+		response.PlannedSize = plan.size
+		response.PlannedImages = int32(plan.images)
+		response.PlannedCost = plan.estimated
+	}
+
+	tenant, _, tier := callerIdentity(ctx)
+	response.Budgets = s.budgetStatus(tenant, tier)
+
+	return response, nil
+}
+
+// budgetStatus 获取租户当前的预算状态，未启用预算时返回nil
+func (s *ImageService) budgetStatus(tenant, tier string) []*imagev1.BudgetStatus {
+	checker := s.budget.Load()
+	if checker == nil {
+		return nil
+	}
+
+	var statuses []*imagev1.BudgetStatus
+	for _, budget := range checker.Status(tenant, tier, time.Now()) {
+		statuses = append(statuses, &imagev1.BudgetStatus{
+			Window:   budget.Window,
+			Spent:    budget.Spent,
+			Limit:    budget.Limit,
+			ResetsAt: timestamppb.New(budget.ResetsAt),
+		})
+	}
+	return statuses
+}
+
+// convertCost 转换费用
+func convertCost(cost *domain.Cost) *imagev1.Cost {
+	if cost == nil {
+		return nil
+	}
+	return &imagev1.Cost{
+		Currency:      cost.Currency,
+		Estimated:     cost.Estimated,
+		Actual:        cost.Actual,
+		Downgraded:    cost.Downgraded,
+		DowngradeNote: cost.DowngradeNote,
+	}
+}
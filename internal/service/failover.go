@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"sia/internal/config"
+	"sia/internal/domain"
+	"sia/internal/metrics"
+	"sia/internal/models"
+	"sia/internal/usage"
+)
+
+// errProviderUnavailable 模型引用的上游服务没有客户端（providers的修改需要重启才能生效）
+var errProviderUnavailable = errors.New("upstream provider is not available")
+
+// newProviderClients 创建全部上游服务的客户端：ark使用image配置，备用上游服务使用各自的地址和API Key池
+// 熔断器和API Key池的指标按provider标签区分
+func newProviderClients(cfg *config.Config) map[string]*domain.ImageClient {
+	clients := map[string]*domain.ImageClient{
+		models.ProviderArk: domain.NewImageClient(newImageClientConfig(cfg.Image)),
+	}
+	for name, provider := range cfg.Models.Providers {
+		clients[name] = domain.NewImageClient(newProviderClientConfig(cfg.Image, provider))
+	}
+
+	for name, client := range clients {
+		registerer := prometheus.WrapRegistererWith(prometheus.Labels{"provider": name}, metrics.Registry)
+		registerer.MustRegister(client.Breaker())
+		registerer.MustRegister(client.Keys())
+	}
+	return clients
+}
+
+// newProviderClientConfig 转换备用上游服务的客户端配置
+func newProviderClientConfig(image config.ImageConfig, provider config.ProviderConfig) *domain.ImageClientConfig {
+	clientConfig := newImageClientConfig(image)
+	clientConfig.BaseURL = provider.BaseURL
+	clientConfig.Keys = make([]domain.UpstreamKey, len(provider.APIKeys))
+	for i, key := range provider.APIKeys {
+		clientConfig.Keys[i] = domain.UpstreamKey{ID: key.ID, Key: key.Key}
+	}
+	return clientConfig
+}
+
+// upstream 获取上游服务的客户端
+func (s *ImageService) upstream(provider string) (*domain.ImageClient, error) {
+	client, ok := s.providers[provider]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", errProviderUnavailable, provider)
+	}
+	return client, nil
+}
+
+// planFailover 确定主模型的上游服务，以及使用别名时的故障转移链：别名的备用模型中支持本次请求的部分
+// req为补全默认值后的请求参数，调用方禁用故障转移时不设置故障转移链
+func (s *ImageService) planFailover(ctx context.Context, plan *costPlan, req models.Request, disabled bool) {
+	registry := s.registry.Load()
+	model, _ := registry.Get(plan.model)
+	plan.provider = model.Provider
+	plan.noFailover = disabled
+
+	alias, ok := registry.Alias(plan.alias)
+	if !ok || disabled {
+		return
+	}
+
+	plan.failoverOn = alias.FailoverOn
+	for _, fallback := range alias.Fallbacks {
+		req.Model = fallback.Model
+		if err := registry.Validate(req); err != nil {
+			s.logger.DebugContext(ctx, "Fallback model skipped", "alias", alias.Name, "model", fallback.Model, "reason", err)
+			continue
+		}
+		if fallback.Provider == "" {
+			capabilities, _ := registry.Get(fallback.Model)
+			fallback.Provider = capabilities.Provider
+		}
+		if fallback.Model == plan.model && fallback.Provider == plan.provider {
+			continue
+		}
+		plan.fallbacks = append(plan.fallbacks, fallback)
+	}
+}
+
+// generateWithFailover 请求主模型生成图片，失败且错误类型在故障转移规则中时依次尝试备用模型
+// 响应中记录实际使用的模型（按其计价）、上游服务和是否使用了备用模型；除了为备用模型追加的预算预留外plan不会被修改，合并的请求可以共享同一次调用
+// 之后还可能故障转移的尝试先缓存收到的图片，成功后才交给onImage，失败尝试的图片不会和备用模型的图片混在一起
+func (s *ImageService) generateWithFailover(ctx context.Context, plan *costPlan, req *domain.ImageGenerationRequest, onImage func(domain.ImageData)) (*domain.ImageGenerationResponse, error) {
+	steps := append([]models.Fallback{{Model: plan.model, Provider: plan.provider}}, plan.fallbacks...)
+
+	var err error
+	var extra float64 // 已为备用模型追加预留的费用
+	previous := steps[0]
+	for i, step := range steps {
+		if i > 0 {
+			reason := failoverReason(err)
+			if ctx.Err() != nil || !slices.Contains(plan.failoverOn, reason) {
+				return nil, err
+			}
+
+			// 备用模型可能更贵，按其价格追加预留预算，预算不足时跳过
+			reserved, budgetErr := s.reserveFallbackBudget(ctx, plan, step.Model, extra)
+			if budgetErr != nil {
+				s.logger.WarnContext(ctx, "Fallback model skipped by budget", "alias", plan.alias, "model", step.Model, "error", budgetErr)
+				continue
+			}
+			extra += reserved
+
+			metrics.ModelFailovers.WithLabelValues(plan.alias, step.Model, step.Provider, reason).Inc()
+			trace.SpanFromContext(ctx).AddEvent("image.failover", trace.WithAttributes(
+				attribute.String("image.model", step.Model),
+				attribute.String("image.provider", step.Provider),
+				attribute.String("failover.reason", reason),
+			))
+			s.logger.WarnContext(ctx, "Failing over to fallback model",
+				"alias", plan.alias, "from", previous.Model, "model", step.Model, "provider", step.Provider, "reason", reason, "error", err)
+		}
+		previous = step
+
+		var client *domain.ImageClient
+		if client, err = s.upstream(step.Provider); err != nil {
+			continue
+		}
+
+		attempt := *req
+		attempt.Model = step.Model
+		attemptImages := onImage
+		var buffered []domain.ImageData
+		if onImage != nil && i < len(steps)-1 {
+			attemptImages = func(image domain.ImageData) { buffered = append(buffered, image) }
+		}
+		var response *domain.ImageGenerationResponse
+		if response, err = client.GenerateImageStream(ctx, &attempt, attemptImages); err != nil {
+			continue
+		}
+		for _, image := range buffered {
+			onImage(image)
+		}
+
+		response.ResolvedModel = step.Model
+		response.Provider = step.Provider
+		response.FallbackUsed = i > 0
+		return response, nil
+	}
+	return nil, err
+}
+
+// reserveFallbackBudget 为备用模型比计划多出的预估费用追加预留预算，返回本次追加的费用
+// 计划的费用和之前追加的reserved已经预留；追加的预留随plan.releaseBudget一起释放，剩余预算不足时返回错误
+func (s *ImageService) reserveFallbackBudget(ctx context.Context, plan *costPlan, model string, reserved float64) (float64, error) {
+	budget := s.budget.Load()
+	if budget == nil {
+		return 0, nil
+	}
+
+	extra := s.pricing.Load().Cost(model, plan.size, plan.images) - plan.estimated - reserved
+	if extra <= 0 {
+		return 0, nil
+	}
+	tenant, _, tier := callerIdentity(ctx)
+	release, err := budget.Reserve(tenant, tier, time.Now(), func(remaining float64, tightest *usage.BudgetStatus) (float64, error) {
+		if tightest != nil && extra > remaining {
+			return 0, &usage.BudgetError{BudgetStatus: *tightest, Estimate: extra}
+		}
+		return extra, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	plan.holdBudget(release)
+	return extra, nil
+}
+
+// failoverReason 返回上游错误的类型，不属于任何可以故障转移的类型（如请求参数错误）时返回空字符串
+func failoverReason(err error) string {
+	var statusErr *domain.StatusError
+	var urlErr *url.Error
+	switch {
+	case errors.Is(err, domain.ErrCircuitOpen):
+		return models.FailoverCircuitOpen
+	case errors.Is(err, domain.ErrNoUpstreamKey):
+		return models.FailoverRateLimited
+	case errors.As(err, &statusErr):
+		switch {
+		case statusErr.StatusCode == http.StatusTooManyRequests:
+			return models.FailoverRateLimited
+		case statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden:
+			return models.FailoverRejected
+		case statusErr.StatusCode >= http.StatusInternalServerError:
+			return models.FailoverServerError
+		}
+	case errors.Is(err, errProviderUnavailable), errors.As(err, &urlErr):
+		return models.FailoverUnreachable
+	}
+	return ""
+}
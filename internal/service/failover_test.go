@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"sia/internal/config"
+	"sia/internal/domain"
+	"sia/internal/models"
+	"sia/internal/usage"
+	"sia/pkg/logger"
+)
+
+func TestFailoverReason(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{domain.ErrCircuitOpen, models.FailoverCircuitOpen},
+		{fmt.Errorf("wrapped: %w", domain.ErrNoUpstreamKey), models.FailoverRateLimited},
+		{&domain.StatusError{StatusCode: http.StatusTooManyRequests}, models.FailoverRateLimited},
+		{&domain.StatusError{StatusCode: http.StatusUnauthorized}, models.FailoverRejected},
+		{&domain.StatusError{StatusCode: http.StatusForbidden}, models.FailoverRejected},
+		{&domain.StatusError{StatusCode: http.StatusBadGateway}, models.FailoverServerError},
+		{&domain.StatusError{StatusCode: http.StatusBadRequest}, ""},
+		{fmt.Errorf("%w: backup", errProviderUnavailable), models.FailoverUnreachable},
+		{&url.Error{Op: "Post", URL: "http://upstream", Err: errors.New("connection refused")}, models.FailoverUnreachable},
+		{errors.New("no images generated"), ""},
+	}
+	for _, tt := range tests {
+		if got := failoverReason(tt.err); got != tt.want {
+			t.Errorf("failoverReason(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+// newUpstream 创建模拟的上游服务：status为0时返回一张图片的SSE流，否则返回该状态码；calls记录收到请求的次数
+func newUpstream(t *testing.T, status int, calls *int) *domain.ImageClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		if status != 0 {
+			http.Error(w, `{"error":{"code":"test","message":"failed"}}`, status)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"type\":\"image_generation.partial_succeeded\",\"id\":\"req\",\"url\":\"http://example.com/0.png\"}\n\n")
+		fmt.Fprint(w, "data: {\"type\":\"image_generation.completed\",\"usage\":{\"generated_images\":1}}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	t.Cleanup(server.Close)
+
+	return domain.NewImageClient(&domain.ImageClientConfig{
+		Keys:            []domain.UpstreamKey{{ID: "test", Key: "test"}},
+		BaseURL:         server.URL,
+		Timeout:         5,
+		BreakerFailures: 100,
+		BreakerCooldown: 1,
+	})
+}
+
+// newFailoverService 创建只包含故障转移所需部分的服务，fallback模型的单价为price
+// 设置了预算时与planCost一样为计划的费用（0.2）预留预算
+func newFailoverService(primary, backup *domain.ImageClient, price float64, budget *usage.BudgetLimits) *ImageService {
+	s := &ImageService{
+		logger:    &logger.Logger{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))},
+		providers: map[string]*domain.ImageClient{models.ProviderArk: primary, "backup": backup},
+	}
+	s.pricing.Store(usage.NewPriceTable("CNY", map[string]usage.Price{
+		"primary":  {PerImage: 0.2},
+		"fallback": {PerImage: price},
+	}))
+	if budget != nil {
+		ledger, _ := usage.NewLedger("")
+		s.ledger = ledger
+		s.budget.Store(usage.NewBudget(ledger, usage.BudgetConfig{
+			DefaultTier: "free",
+			Tiers:       map[string]usage.BudgetLimits{"free": *budget},
+		}))
+		s.budget.Load().Reserve(anonymousSubject, "", time.Now(), func(float64, *usage.BudgetStatus) (float64, error) {
+			return newFailoverPlan().estimated, nil
+		})
+	}
+	return s
+}
+
+func newFailoverPlan() *costPlan {
+	return &costPlan{
+		model:      "primary",
+		alias:      "image",
+		provider:   models.ProviderArk,
+		fallbacks:  []models.Fallback{{Model: "fallback", Provider: "backup"}},
+		failoverOn: []string{models.FailoverServerError},
+		size:       "2K",
+		images:     1,
+		estimated:  0.2,
+	}
+}
+
+func TestGenerateWithFailover(t *testing.T) {
+	var primaryCalls, backupCalls int
+	s := newFailoverService(newUpstream(t, http.StatusServiceUnavailable, &primaryCalls), newUpstream(t, 0, &backupCalls), 0.2, nil)
+	plan := newFailoverPlan()
+
+	response, err := s.generateWithFailover(context.Background(), plan, &domain.ImageGenerationRequest{Model: "primary", Prompt: "cat"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.ResolvedModel != "fallback" || response.Provider != "backup" || !response.FallbackUsed {
+		t.Errorf("response = %s/%s fallback_used=%v, want fallback/backup", response.ResolvedModel, response.Provider, response.FallbackUsed)
+	}
+	if plan.model != "primary" {
+		t.Errorf("plan.model = %q, the shared plan must not be modified", plan.model)
+	}
+	if backupCalls != 1 {
+		t.Errorf("backup calls = %d, want 1", backupCalls)
+	}
+}
+
+func TestGenerateWithFailoverSkipsUnlistedErrors(t *testing.T) {
+	var primaryCalls, backupCalls int
+	s := newFailoverService(newUpstream(t, http.StatusBadRequest, &primaryCalls), newUpstream(t, 0, &backupCalls), 0.2, nil)
+
+	_, err := s.generateWithFailover(context.Background(), newFailoverPlan(), &domain.ImageGenerationRequest{Model: "primary", Prompt: "cat"}, nil)
+	var statusErr *domain.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("error = %v, want the primary's 400", err)
+	}
+	if backupCalls != 0 {
+		t.Errorf("backup calls = %d, want no failover for a request error", backupCalls)
+	}
+}
+
+func TestGenerateWithFailoverChecksFallbackBudget(t *testing.T) {
+	var primaryCalls, backupCalls int
+	// 计划的0.2已预留，剩余预算0.3，备用模型比计划贵0.5
+	s := newFailoverService(newUpstream(t, http.StatusServiceUnavailable, &primaryCalls), newUpstream(t, 0, &backupCalls), 0.7, &usage.BudgetLimits{DailySpend: 0.5})
+
+	_, err := s.generateWithFailover(context.Background(), newFailoverPlan(), &domain.ImageGenerationRequest{Model: "primary", Prompt: "cat"}, nil)
+	var statusErr *domain.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("error = %v, want the primary's 503", err)
+	}
+	if backupCalls != 0 {
+		t.Errorf("backup calls = %d, want the fallback skipped by budget", backupCalls)
+	}
+
+	if reserved := s.ledger.Reserved(anonymousSubject).Cost; reserved != 0.2 {
+		t.Errorf("reserved = %v after skipping the fallback, want only the plan's 0.2", reserved)
+	}
+
+	// 预算足够时使用备用模型，多出的0.5随计划的预留一起释放
+	s = newFailoverService(newUpstream(t, http.StatusServiceUnavailable, &primaryCalls), newUpstream(t, 0, &backupCalls), 0.7, &usage.BudgetLimits{DailySpend: 1})
+	plan := newFailoverPlan()
+	if _, err := s.generateWithFailover(context.Background(), plan, &domain.ImageGenerationRequest{Model: "primary", Prompt: "cat"}, nil); err != nil {
+		t.Fatalf("generateWithFailover() with enough budget = %v", err)
+	}
+	if reserved := s.ledger.Reserved(anonymousSubject).Cost; math.Abs(reserved-0.7) > 1e-9 {
+		t.Errorf("reserved = %v during the request, want 0.7 including the fallback's extra cost", reserved)
+	}
+	plan.releaseBudget()
+	plan.releaseBudget()
+	if reserved := s.ledger.Reserved(anonymousSubject).Cost; math.Abs(reserved-0.2) > 1e-9 {
+		t.Errorf("reserved = %v after the request, want the fallback's extra cost released once", reserved)
+	}
+}
+
+// newBrokenUpstream 创建返回一张图片后断开连接的上游服务
+func newBrokenUpstream(t *testing.T) *domain.ImageClient {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"type\":\"image_generation.partial_succeeded\",\"id\":\"req\",\"url\":\"http://example.com/broken.png\"}\n\n")
+		w.(http.Flusher).Flush()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return domain.NewImageClient(&domain.ImageClientConfig{
+		Keys:            []domain.UpstreamKey{{ID: "test", Key: "test"}},
+		BaseURL:         server.URL,
+		Timeout:         5,
+		BreakerFailures: 100,
+		BreakerCooldown: 1,
+	})
+}
+
+func TestGenerateWithFailoverBuffersPartialImages(t *testing.T) {
+	var calls int
+	tests := []struct {
+		name      string
+		primary   *domain.ImageClient
+		fallbacks bool
+		wantErr   bool
+		wantURLs  []string
+	}{
+		// 之后还可能故障转移的尝试失败时，已收到的图片不交给调用方
+		{name: "failed attempt", primary: newBrokenUpstream(t), fallbacks: true, wantErr: true},
+		{name: "successful attempt", primary: newUpstream(t, 0, &calls), fallbacks: true, wantURLs: []string{"http://example.com/0.png"}},
+		// 没有备用模型时直接交给调用方
+		{name: "last attempt", primary: newBrokenUpstream(t), wantErr: true, wantURLs: []string{"http://example.com/broken.png"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newFailoverService(tt.primary, newUpstream(t, 0, &calls), 0.2, nil)
+			plan := newFailoverPlan()
+			if !tt.fallbacks {
+				plan.fallbacks = nil
+			}
+
+			var urls []string
+			_, err := s.generateWithFailover(context.Background(), plan, &domain.ImageGenerationRequest{Model: "primary", Prompt: "cat"}, func(image domain.ImageData) {
+				urls = append(urls, image.URL)
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("generateWithFailover() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if fmt.Sprint(urls) != fmt.Sprint(tt.wantURLs) {
+				t.Errorf("images = %v, want %v", urls, tt.wantURLs)
+			}
+		})
+	}
+}
+
+func TestRotateProviderKeys(t *testing.T) {
+	var primaryCalls, backupCalls int
+	s := newFailoverService(newUpstream(t, 0, &primaryCalls), newUpstream(t, 0, &backupCalls), 0.2, nil)
+	providers := map[string]config.ProviderConfig{
+		"backup": {BaseURL: "https://backup.example.com", APIKeys: []config.UpstreamKeyConfig{{ID: "b1", Key: "old"}}},
+	}
+	s.config.Store(&config.Config{Models: config.ModelsConfig{Providers: providers}})
+
+	s.RotateProviderKeys("backup", []config.UpstreamKeyConfig{{ID: "b2", Key: "new"}})
+	// 没有客户端的上游服务被忽略
+	s.RotateProviderKeys("missing", []config.UpstreamKeyConfig{{ID: "m1", Key: "key"}})
+
+	if status := s.providers["backup"].Keys().Status(); len(status) != 1 || status[0].ID != "b2" {
+		t.Errorf("backup keys = %+v, want b2", status)
+	}
+	current := s.Config().Models.Providers
+	if keys := current["backup"].APIKeys; len(keys) != 1 || keys[0].ID != "b2" {
+		t.Errorf("current config keys = %+v, want b2", keys)
+	}
+	if _, ok := current["missing"]; ok {
+		t.Error("rotating an unknown provider added it to the config")
+	}
+	// 之前的配置不被修改
+	if keys := providers["backup"].APIKeys; keys[0].ID != "b1" {
+		t.Errorf("previous config was modified: %+v", keys)
+	}
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	imagev1 "sia/api/image/v1"
+	"sia/internal/domain"
+	"sia/internal/health"
+)
+
+// newHealthChecker 注册就绪检查项：上游探测、任务存储、任务队列饱和度和熔断器状态
+func (s *ImageService) newHealthChecker() *health.Checker {
+	cfg := s.config.Load().Health
+	checker := health.NewChecker()
+
+	checker.Register(health.Check{
+		Name:    "upstream",
+		TTL:     time.Duration(cfg.ProbeInterval) * time.Second,
+		Timeout: time.Duration(cfg.ProbeTimeout) * time.Second,
+		Func: func(ctx context.Context) error {
+			return s.imageClient.Probe(ctx, cfg.ProbePath)
+		},
+	})
+
+	checker.Register(health.Check{
+		Name:    "task_store",
+		Timeout: time.Second,
+		Func: func(ctx context.Context) error {
+			done := make(chan struct{})
+			go func() {
+				s.taskManager.Counts()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return fmt.Errorf("task store not responding: %w", ctx.Err())
+			}
+		},
+	})
+
+	checker.Register(health.Check{
+		Name: "task_queue",
+		Func: func(ctx context.Context) error {
+			if cfg.MaxQueueDepth == 0 {
+				return nil
+			}
+			if pending := s.taskManager.Counts()[domain.TaskStatusPending]; pending >= cfg.MaxQueueDepth {
+				return fmt.Errorf("task queue saturated: %d pending tasks, limit %d", pending, cfg.MaxQueueDepth)
+			}
+			return nil
+		},
+	})
+
+	checker.Register(health.Check{
+		Name: "circuit_breaker",
+		Func: func(ctx context.Context) error {
+			if state := s.imageClient.Breaker().State(); state == domain.BreakerOpen {
+				return fmt.Errorf("upstream circuit breaker is %s", state)
+			}
+			return nil
+		},
+	})
+
+	return checker
+}
+
+// Readiness 获取就绪检查器
+func (s *ImageService) Readiness() *health.Checker {
+	return s.health
+}
+
+// HealthCheck 健康检查
+func (s *ImageService) HealthCheck(ctx context.Context, req *imagev1.HealthCheckRequest) (*imagev1.HealthCheckResponse, error) {
+	report := s.health.Check(ctx)
+
+	// 检查服务状态
+	details := make(map[string]string)
+	details["service"] = "image-service"
+	details["version"] = s.config.Load().App.Version
+	details["environment"] = s.config.Load().App.Environment
+	for _, result := range report.Checks {
+		details[result.Name] = result.Status
+		if result.Message != "" {
+			details[result.Name] += ": " + result.Message
+		}
+	}
+
+	if !report.Ready {
+		return &imagev1.HealthCheckResponse{
+			Status:  imagev1.HealthStatus_HEALTH_STATUS_NOT_SERVING,
+			Message: "Service is not ready",
+			Details: details,
+		}, nil
+	}
+
+	return &imagev1.HealthCheckResponse{
+		Status:  imagev1.HealthStatus_HEALTH_STATUS_SERVING,
+		Message: "Service is healthy",
+		Details: details,
+	}, nil
+}
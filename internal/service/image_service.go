@@ -2,125 +2,265 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
 	imagev1 "sia/api/image/v1"
+	"sia/internal/auth"
 	"sia/internal/config"
 	"sia/internal/domain"
+	"sia/internal/health"
+	"sia/internal/metrics"
+	"sia/internal/models"
+	"sia/internal/ratelimit"
+	"sia/internal/storage"
+	"sia/internal/tracing"
+	"sia/internal/usage"
 	"sia/pkg/logger"
 )
 
 // ImageService 图片生成服务
 type ImageService struct {
 	imagev1.UnimplementedImageServiceServer
-	config      *config.Config
+	config      atomic.Pointer[config.Config]
 	logger      *logger.Logger
 	imageClient *domain.ImageClient
+	providers   map[string]*domain.ImageClient // 按上游服务名称，包括imageClient（ark）
 	taskManager *domain.TaskManager
+	limiter     *ratelimit.Limiter
+	ledger      *usage.Ledger
+	registry    atomic.Pointer[models.Registry]
+	quota       atomic.Pointer[usage.Quota]
+	pricing     atomic.Pointer[usage.PriceTable]
+	budget      atomic.Pointer[usage.Budget]
+	health      *health.Checker
+	persister   *storage.Persister
+	signer      atomic.Pointer[storage.URLSigner]
+	gc          *storage.GC
+	cache       *domain.ResultCache
+	coalescer   *domain.Coalescer
 }
 
 // NewImageService 创建新的图片生成服务
-func NewImageService(cfg *config.Config, logger *logger.Logger) *ImageService {
-	imageClient := domain.NewImageClient(&domain.ImageClientConfig{
-		APIKey:      cfg.Image.APIKey,
-		BaseURL:     cfg.Image.BaseURL,
-		Model:       cfg.Image.Model,
-		DefaultSize: cfg.Image.DefaultSize,
-		Timeout:     cfg.Image.Timeout,
-		MaxRetries:  cfg.Image.MaxRetries,
-	})
+func NewImageService(cfg *config.Config, logger *logger.Logger) (*ImageService, error) {
+	providers := newProviderClients(cfg)
 
 	taskManager := domain.NewTaskManager()
+	metrics.Registry.MustRegister(taskManager)
+
+	limiter := newLimiter(cfg.RateLimit)
+	if limiter != nil {
+		metrics.Registry.MustRegister(limiter)
+	}
+
+	ledger, err := usage.NewLedger(cfg.Usage.LedgerFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+
+	persister, err := newPersister(cfg.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blob store: %w", err)
+	}
 
-	return &ImageService{
-		config:      cfg,
+	coalescer := domain.NewCoalescer()
+	metrics.Registry.MustRegister(coalescer)
+
+	cache := newResultCache(cfg.Cache)
+	if cache != nil {
+		metrics.Registry.MustRegister(cache)
+	}
+
+	s := &ImageService{
 		logger:      logger,
-		imageClient: imageClient,
+		imageClient: providers[models.ProviderArk],
+		providers:   providers,
 		taskManager: taskManager,
+		limiter:     limiter,
+		ledger:      ledger,
+		persister:   persister,
+		cache:       cache,
+		coalescer:   coalescer,
+	}
+	s.config.Store(cfg)
+	s.registry.Store(cfg.Models.Registry())
+	s.quota.Store(newQuota(ledger, cfg))
+	s.pricing.Store(newPriceTable(cfg))
+	s.budget.Store(newBudget(ledger, cfg))
+	s.signer.Store(newURLSigner(cfg.Storage))
+	s.health = s.newHealthChecker()
+
+	if s.gc, err = s.newGC(cfg.Storage); err != nil {
+		return nil, fmt.Errorf("failed to create storage GC: %w", err)
+	}
+
+	return s, nil
+}
+
+// Close 释放服务持有的资源
+func (s *ImageService) Close() error {
+	if s.gc != nil {
+		s.gc.Close()
 	}
+	return s.ledger.Close()
 }
 
 // GenerateImage 生成图片
 func (s *ImageService) GenerateImage(ctx context.Context, req *imagev1.GenerateImageRequest) (*imagev1.GenerateImageResponse, error) {
-	s.logger.Info("Generating image", "prompt", req.Prompt)
+	s.logger.InfoContext(ctx, "Generating image", "prompt", req.Prompt)
 
-	// 验证请求
-	if err := s.validateGenerateImageRequest(req); err != nil {
-		s.logger.Error("Invalid request", "error", err)
+	// 解析模型别名并验证请求
+	model, alias := s.resolveModel(ctx, req.Model, req.Metadata)
+	if err := traceValidation(ctx, func() error { return s.validateGenerateImageRequest(req, model) }); err != nil {
+		s.logger.ErrorContext(ctx, "Invalid request", "error", err)
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	// 预算、配额与限流
+	plan, err := s.checkBudget(ctx, model, s.getSize(req.Size), 1, false)
+	if err != nil {
+		return nil, err
+	}
+	plan.alias = alias
+	s.planFailover(ctx, plan, models.Request{Size: plan.size, ReferenceImages: len(req.ImageUrls), Images: plan.images}, req.DisableFailover)
+
 	// 创建域对象请求
 	domainReq := &domain.ImageGenerationRequest{
-		Model:          s.getModel(req.Model),
+		Model:          plan.model,
 		Prompt:         req.Prompt,
 		Image:          req.ImageUrls,
-		ResponseFormat: "url",
-		Size:           s.getSize(req.Size),
+		ResponseFormat: responseFormat(req.ResponseFormat),
+		Size:           plan.size,
 		Stream:         true,
 		Watermark:      req.Watermark,
 	}
 
-	// 调用图片生成
-	response, err := s.imageClient.GenerateImage(ctx, domainReq)
+	// 命中结果缓存时直接返回，不占用配额和并发
+	tenant, _, _ := callerIdentity(ctx)
+	cacheKey := s.cacheKey(tenant, domainReq, req.CacheMode)
+	if cached := s.cachedResult(ctx, cacheKey, req.CacheMode, domainReq.ResponseFormat); cached != nil {
+		s.logger.InfoContext(ctx, "Image served from result cache", "image_count", len(cached.Response.Data))
+		plan.releaseBudget()
+		return s.convertCachedResponse(plan, cached), nil
+	}
+
+	releaseQuota, err := s.checkQuota(ctx, plan.images)
 	if err != nil {
-		s.logger.Error("Failed to generate image", "error", err)
-		return nil, status.Error(codes.Internal, "Failed to generate image")
+		plan.releaseBudget()
+		return nil, err
+	}
+
+	// 调用图片生成，同时进行的相同请求共享一次上游调用；预算和配额预留由generate释放
+	response, shared, err := s.generate(ctx, "GenerateImage", plan, domainReq, req.Metadata, cacheKey, releaseQuota)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to generate image", "error", err)
+		return nil, upstreamError(err, "Failed to generate image")
 	}
 
 	// 转换响应
 	grpcResponse := s.convertToGRPCResponse(response)
-	s.logger.Info("Image generated successfully", "image_count", len(grpcResponse.Images))
+	grpcResponse.Coalesced = shared
+	grpcResponse.Alias = plan.alias
+	s.logger.InfoContext(ctx, "Image generated successfully", "image_count", len(grpcResponse.Images), "model", plan.model, "alias", plan.alias, "fallback_used", grpcResponse.FallbackUsed)
 
 	return grpcResponse, nil
 }
 
 // GenerateImageAsync 异步生成图片
 func (s *ImageService) GenerateImageAsync(ctx context.Context, req *imagev1.GenerateImageRequest) (*imagev1.GenerateImageAsyncResponse, error) {
-	s.logger.Info("Starting async image generation", "prompt", req.Prompt)
+	s.logger.InfoContext(ctx, "Starting async image generation", "prompt", req.Prompt)
 
-	// 验证请求
-	if err := s.validateGenerateImageRequest(req); err != nil {
-		s.logger.Error("Invalid request", "error", err)
+	// 解析模型别名并验证请求
+	model, alias := s.resolveModel(ctx, req.Model, req.Metadata)
+	if err := traceValidation(ctx, func() error { return s.validateGenerateImageRequest(req, model) }); err != nil {
+		s.logger.ErrorContext(ctx, "Invalid request", "error", err)
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	// 预算、配额与限流：异步任务在执行结束前一直占用并发配额
+	plan, err := s.checkBudget(ctx, model, s.getSize(req.Size), 1, false)
+	if err != nil {
+		return nil, err
+	}
+	plan.alias = alias
+	s.planFailover(ctx, plan, models.Request{Size: plan.size, ReferenceImages: len(req.ImageUrls), Images: plan.images}, req.DisableFailover)
+	releaseQuota, err := s.checkQuota(ctx, plan.images)
+	if err != nil {
+		plan.releaseBudget()
+		return nil, err
+	}
+	release, err := s.acquireLimit(ctx, plan.model)
+	if err != nil {
+		releaseQuota()
+		plan.releaseBudget()
+		return nil, err
+	}
+
 	// 创建任务
-	task := s.taskManager.CreateTask(req.Prompt)
+	tenant, clientID, _ := callerIdentity(ctx)
+	task := s.taskManager.CreateTask(req.Prompt, tenant, clientID)
+
+	// 任务span延续调用方的trace和请求ID，但不随请求结束而取消
+	detached := logger.WithRequestID(tracing.Detach(ctx), logger.RequestIDFromContext(ctx))
+	spanCtx, taskSpan := tracing.Start(detached, "image.task",
+		trace.WithAttributes(attribute.String("task.id", task.ID)))
+	_, pendingSpan := tracing.Start(spanCtx, "task.pending")
 
 	// 异步执行
 	go func() {
-		taskCtx, cancel := context.WithTimeout(context.Background(), time.Duration(s.config.Image.Timeout)*time.Second)
+		defer release()
+		defer releaseQuota()
+		defer plan.releaseBudget()
+		defer taskSpan.End()
+		pendingSpan.End()
+
+		taskCtx, cancel := context.WithTimeout(spanCtx, time.Duration(s.config.Load().Image.Timeout)*time.Second)
 		defer cancel()
 
 		// 创建域对象请求
 		domainReq := &domain.ImageGenerationRequest{
-			Model:          s.getModel(req.Model),
+			Model:          plan.model,
 			Prompt:         req.Prompt,
 			Image:          req.ImageUrls,
-			ResponseFormat: "url",
-			Size:           s.getSize(req.Size),
+			ResponseFormat: responseFormat(req.ResponseFormat),
+			Size:           plan.size,
 			Stream:         true,
 			Watermark:      req.Watermark,
 		}
 
 		// 更新任务状态为处理中
 		s.taskManager.UpdateTaskStatus(task.ID, domain.TaskStatusProcessing)
+		processingCtx, processingSpan := tracing.Start(taskCtx, "task.processing")
 
 		// 执行图片生成
-		response, err := s.imageClient.GenerateImage(taskCtx, domainReq)
+		response, err := s.generateWithFailover(processingCtx, plan, domainReq, func(image domain.ImageData) {
+			s.taskManager.AddTaskImage(task.ID, image)
+		})
 		if err != nil {
-			s.logger.Error("Async image generation failed", "task_id", task.ID, "error", err)
+			s.logger.ErrorContext(taskCtx, "Async image generation failed", "task_id", task.ID, "error", err)
+			processingSpan.RecordError(err)
+			processingSpan.SetStatus(otelcodes.Error, err.Error())
 			s.taskManager.UpdateTaskError(task.ID, err.Error())
+			taskSpan.AddEvent("task.failed")
 		} else {
-			s.logger.Info("Async image generation completed", "task_id", task.ID, "image_count", len(response.Data))
+			plan.model = response.ResolvedModel
+			s.logger.InfoContext(taskCtx, "Async image generation completed", "task_id", task.ID, "image_count", len(response.Data), "model", plan.model, "alias", plan.alias, "fallback_used", response.FallbackUsed)
+			response.Alias = plan.alias
+			s.recordUsage(taskCtx, tenant, clientID, "GenerateImageAsync", plan, response)
+			s.persistImages(processingCtx, response, tenant, task.ID, req.Metadata)
 			s.taskManager.UpdateTaskResult(task.ID, response)
+			taskSpan.AddEvent("task.completed")
 		}
+		processingSpan.End()
 	}()
 
 	return &imagev1.GenerateImageAsyncResponse{
@@ -132,13 +272,18 @@ func (s *ImageService) GenerateImageAsync(ctx context.Context, req *imagev1.Gene
 
 // GetImageTask 获取图片生成任务状态
 func (s *ImageService) GetImageTask(ctx context.Context, req *imagev1.GetImageTaskRequest) (*imagev1.GetImageTaskResponse, error) {
-	s.logger.Debug("Getting task status", "task_id", req.TaskId)
+	s.logger.DebugContext(ctx, "Getting task status", "task_id", req.TaskId)
 
 	task, exists := s.taskManager.GetTask(req.TaskId)
-	if !exists {
+	if !exists || !s.canAccessTask(ctx, task) {
 		return nil, status.Error(codes.NotFound, "Task not found")
 	}
 
+	return s.convertTask(task), nil
+}
+
+// convertTask 转换任务状态及结果
+func (s *ImageService) convertTask(task *domain.Task) *imagev1.GetImageTaskResponse {
 	response := &imagev1.GetImageTaskResponse{
 		TaskId:    task.ID,
 		Status:    s.convertTaskStatus(task.Status),
@@ -154,66 +299,114 @@ func (s *ImageService) GetImageTask(ctx context.Context, req *imagev1.GetImageTa
 		response.ErrorMessage = task.Error
 	}
 
-	return response, nil
+	return response
 }
 
 // GenerateSequentialImages 生成序列图片
 func (s *ImageService) GenerateSequentialImages(ctx context.Context, req *imagev1.GenerateSequentialImagesRequest) (*imagev1.GenerateImageResponse, error) {
-	s.logger.Info("Generating sequential images", "prompt", req.Prompt, "max_images", req.MaxImages)
+	s.logger.InfoContext(ctx, "Generating sequential images", "prompt", req.Prompt, "max_images", req.MaxImages)
 
-	// 验证请求
-	if err := s.validateSequentialImagesRequest(req); err != nil {
-		s.logger.Error("Invalid request", "error", err)
+	// 解析模型别名并验证请求
+	model, alias := s.resolveModel(ctx, req.Model, req.Metadata)
+	if err := traceValidation(ctx, func() error { return s.validateSequentialImagesRequest(req, model) }); err != nil {
+		s.logger.ErrorContext(ctx, "Invalid request", "error", err)
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	// 预算、配额与限流：预算不足时序列请求可以降级为更少的图片
+	plan, err := s.checkBudget(ctx, model, s.getSize(req.Size), int(req.MaxImages), true)
+	if err != nil {
+		return nil, err
+	}
+	plan.alias = alias
+	s.planFailover(ctx, plan, models.Request{Size: plan.size, ReferenceImages: len(req.ImageUrls), Sequential: true, Images: plan.images}, req.DisableFailover)
+
 	// 创建域对象请求
 	domainReq := &domain.ImageGenerationRequest{
-		Model:                     s.getModel(req.Model),
+		Model:                     plan.model,
 		Prompt:                    req.Prompt,
+		Image:                     req.ImageUrls,
 		SequentialImageGeneration: "auto",
 		SequentialImageGenerationOptions: &domain.SequentialImageGenerationOptions{
-			MaxImages: int(req.MaxImages),
+			MaxImages: plan.images,
 		},
-		ResponseFormat: "url",
-		Size:           s.getSize(req.Size),
+		ResponseFormat: responseFormat(req.ResponseFormat),
+		Size:           plan.size,
 		Stream:         true,
 		Watermark:      req.Watermark,
 	}
 
-	// 调用图片生成
-	response, err := s.imageClient.GenerateImage(ctx, domainReq)
+	// 命中结果缓存时直接返回，不占用配额和并发
+	tenant, _, _ := callerIdentity(ctx)
+	cacheKey := s.cacheKey(tenant, domainReq, req.CacheMode)
+	if cached := s.cachedResult(ctx, cacheKey, req.CacheMode, domainReq.ResponseFormat); cached != nil {
+		s.logger.InfoContext(ctx, "Sequential images served from result cache", "image_count", len(cached.Response.Data))
+		plan.releaseBudget()
+		return s.convertCachedResponse(plan, cached), nil
+	}
+
+	releaseQuota, err := s.checkQuota(ctx, plan.images)
 	if err != nil {
-		s.logger.Error("Failed to generate sequential images", "error", err)
-		return nil, status.Error(codes.Internal, "Failed to generate sequential images")
+		plan.releaseBudget()
+		return nil, err
+	}
+
+	// 调用图片生成，同时进行的相同请求共享一次上游调用；预算和配额预留由generate释放
+	response, shared, err := s.generate(ctx, "GenerateSequentialImages", plan, domainReq, req.Metadata, cacheKey, releaseQuota)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to generate sequential images", "error", err)
+		return nil, upstreamError(err, "Failed to generate sequential images")
 	}
 
 	// 转换响应
 	grpcResponse := s.convertToGRPCResponse(response)
-	s.logger.Info("Sequential images generated successfully", "image_count", len(grpcResponse.Images))
+	grpcResponse.Coalesced = shared
+	grpcResponse.Alias = plan.alias
+	s.logger.InfoContext(ctx, "Sequential images generated successfully", "image_count", len(grpcResponse.Images), "model", plan.model, "alias", plan.alias, "fallback_used", grpcResponse.FallbackUsed)
 
 	return grpcResponse, nil
 }
 
-// HealthCheck 健康检查
-func (s *ImageService) HealthCheck(ctx context.Context, req *imagev1.HealthCheckRequest) (*imagev1.HealthCheckResponse, error) {
-	// 检查服务状态
-	details := make(map[string]string)
-	details["service"] = "image-service"
-	details["version"] = s.config.App.Version
-	details["environment"] = s.config.App.Environment
+// upstreamError 将上游调用错误转换为gRPC状态，熔断、没有可用的API Key或上游服务不可用时返回UNAVAILABLE便于客户端重试其他实例
+// 已经是gRPC状态的错误（如限流）和调用方取消原样返回
+func upstreamError(err error, message string) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	if errors.Is(err, domain.ErrCircuitOpen) || errors.Is(err, domain.ErrNoUpstreamKey) || errors.Is(err, errProviderUnavailable) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Internal, message)
+}
 
-	// 可以添加更多健康检查逻辑，比如检查外部API连接等
+// canAccessTask 检查调用方是否可以访问任务
+// 未启用认证时不做限制；admin可以访问所有租户的任务
+func (s *ImageService) canAccessTask(ctx context.Context, task *domain.Task) bool {
+	principal, ok := auth.FromContext(ctx)
+	if !ok {
+		return !s.config.Load().Auth.Enabled
+	}
+	return principal.HasScope(auth.ScopeAdmin) || principal.Tenant == task.Tenant
+}
 
-	return &imagev1.HealthCheckResponse{
-		Status:  imagev1.HealthStatus_HEALTH_STATUS_SERVING,
-		Message: "Service is healthy",
-		Details: details,
-	}, nil
+// traceValidation 在独立的span中执行请求验证
+func traceValidation(ctx context.Context, validate func() error) error {
+	_, span := tracing.Start(ctx, "validate")
+	defer span.End()
+
+	if err := validate(); err != nil {
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+		return err
+	}
+	return nil
 }
 
-// validateGenerateImageRequest 验证生成图片请求
-func (s *ImageService) validateGenerateImageRequest(req *imagev1.GenerateImageRequest) error {
+// validateGenerateImageRequest 验证生成图片请求，model为解析别名后的模型
+func (s *ImageService) validateGenerateImageRequest(req *imagev1.GenerateImageRequest, model string) error {
 	if req.Prompt == "" {
 		return fmt.Errorf("prompt is required")
 	}
@@ -222,11 +415,20 @@ func (s *ImageService) validateGenerateImageRequest(req *imagev1.GenerateImageRe
 		return fmt.Errorf("prompt too long, maximum 1000 characters")
 	}
 
-	return nil
+	if err := validateResponseFormat(req.ResponseFormat); err != nil {
+		return err
+	}
+
+	return s.validateModel(models.Request{
+		Model:           model,
+		Size:            s.getSize(req.Size),
+		ReferenceImages: len(req.ImageUrls),
+		Images:          1,
+	})
 }
 
-// validateSequentialImagesRequest 验证序列图片请求
-func (s *ImageService) validateSequentialImagesRequest(req *imagev1.GenerateSequentialImagesRequest) error {
+// validateSequentialImagesRequest 验证序列图片请求，model为解析别名后的模型
+func (s *ImageService) validateSequentialImagesRequest(req *imagev1.GenerateSequentialImagesRequest, model string) error {
 	if req.Prompt == "" {
 		return fmt.Errorf("prompt is required")
 	}
@@ -235,17 +437,41 @@ func (s *ImageService) validateSequentialImagesRequest(req *imagev1.GenerateSequ
 		return fmt.Errorf("prompt too long, maximum 1000 characters")
 	}
 
-	if req.MaxImages <= 0 || req.MaxImages > 10 {
-		return fmt.Errorf("max_images must be between 1 and 10")
+	if err := validateResponseFormat(req.ResponseFormat); err != nil {
+		return err
 	}
 
-	return nil
+	return s.validateModel(models.Request{
+		Model:           model,
+		Size:            s.getSize(req.Size),
+		ReferenceImages: len(req.ImageUrls),
+		Sequential:      true,
+		Images:          int(req.MaxImages),
+	})
+}
+
+// validateResponseFormat 验证返回格式
+func validateResponseFormat(format string) error {
+	switch format {
+	case "", "url", "b64_json":
+		return nil
+	default:
+		return fmt.Errorf("invalid response_format %q, must be url or b64_json", format)
+	}
+}
+
+// responseFormat 获取返回格式，默认返回图片URL
+func responseFormat(format string) string {
+	if format == "" {
+		return "url"
+	}
+	return format
 }
 
 // getModel 获取模型名称
 func (s *ImageService) getModel(model string) string {
 	if model == "" {
-		return s.config.Image.Model
+		return s.config.Load().Image.Model
 	}
 	return model
 }
@@ -253,7 +479,7 @@ func (s *ImageService) getModel(model string) string {
 // getSize 获取图片尺寸
 func (s *ImageService) getSize(size string) string {
 	if size == "" {
-		return s.config.Image.DefaultSize
+		return s.config.Load().Image.DefaultSize
 	}
 	return size
 }
@@ -266,6 +492,7 @@ func (s *ImageService) convertToGRPCResponse(response *domain.ImageGenerationRes
 			Url:           img.URL,
 			B64Json:       img.B64JSON,
 			RevisedPrompt: img.RevisedPrompt,
+			Stored:        s.convertStoredImage(img.Stored),
 		}
 	}
 
@@ -273,12 +500,16 @@ func (s *ImageService) convertToGRPCResponse(response *domain.ImageGenerationRes
 		RequestId: response.ID,
 		Images:    images,
 		Usage: &imagev1.Usage{
-			PromptTokens:     int32(response.Usage.PromptTokens),
-			CompletionTokens: int32(response.Usage.CompletionTokens),
+			CompletionTokens: int32(response.Usage.OutputTokens),
 			TotalTokens:      int32(response.Usage.TotalTokens),
+			GeneratedImages:  int32(response.Usage.GeneratedImages),
 		},
-		Model:     response.Model,
-		CreatedAt: timestamppb.New(time.Unix(response.Created, 0)),
+		Model:        response.Model,
+		Alias:        response.Alias,
+		FallbackUsed: response.FallbackUsed,
+		Provider:     response.Provider,
+		CreatedAt:    timestamppb.New(time.Unix(response.Created, 0)),
+		Cost:         convertCost(response.Cost),
 	}
 }
 
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"sia/internal/auth"
+	"sia/internal/config"
+	"sia/internal/ratelimit"
+)
+
+// anonymousSubject 未启用认证时的限流主体
+const anonymousSubject = "anonymous"
+
+// newLimiter 根据配置创建限流器，未启用时返回nil
+func newLimiter(cfg config.RateLimitConfig) *ratelimit.Limiter {
+	if !cfg.Enabled {
+		return nil
+	}
+	return ratelimit.New(limiterConfig(cfg))
+}
+
+// limiterConfig 转换限流配置
+func limiterConfig(cfg config.RateLimitConfig) ratelimit.Config {
+	tiers := make(map[string]ratelimit.Limits, len(cfg.Tiers))
+	for name, tier := range cfg.Tiers {
+		tiers[name] = ratelimit.Limits(tier)
+	}
+
+	models := make(map[string]ratelimit.Limits, len(cfg.Models))
+	for name, model := range cfg.Models {
+		models[name] = ratelimit.Limits(model)
+	}
+
+	return ratelimit.Config{
+		DefaultTier: cfg.DefaultTier,
+		Tiers:       tiers,
+		Models:      models,
+	}
+}
+
+// acquireLimit 申请限流配额，返回的释放函数在请求（或异步任务）结束时调用
+func (s *ImageService) acquireLimit(ctx context.Context, model string) (func(), error) {
+	if s.limiter == nil {
+		return func() {}, nil
+	}
+
+	subject := ratelimit.Subject{Key: anonymousSubject}
+	if principal, ok := auth.FromContext(ctx); ok {
+		subject.Key = principal.Tenant
+		if s.config.Load().RateLimit.KeyBy == "client" {
+			subject.Key = principal.ClientID
+		}
+		subject.Tier = principal.Tier
+	}
+
+	release, err := s.limiter.Acquire(subject, model)
+	if err != nil {
+		s.logger.WarnContext(ctx, "Request rate limited", "subject", subject.Key, "model", model, "error", err)
+		return nil, limitStatus(err)
+	}
+
+	return release, nil
+}
+
+// limitStatus 将限流错误转换为带RetryInfo的RESOURCE_EXHAUSTED状态
+func limitStatus(err error) error {
+	var limitErr *ratelimit.LimitError
+	if !errors.As(err, &limitErr) {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	st := status.New(codes.ResourceExhausted, limitErr.Error())
+	detailed, detailErr := st.WithDetails(
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(limitErr.RetryAfter)},
+		&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{
+			Subject:     limitErr.Scope + ":" + limitErr.Key,
+			Description: limitErr.Reason + " limit exceeded",
+		}}},
+	)
+	if detailErr != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
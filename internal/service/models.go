@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	imagev1 "sia/api/image/v1"
+	"sia/internal/metrics"
+	"sia/internal/models"
+)
+
+// resolveModel 补全默认模型并解析模型别名，返回具体模型和命中的别名
+func (s *ImageService) resolveModel(ctx context.Context, requested string, metadata map[string]string) (string, string) {
+	model, alias := s.registry.Load().Resolve(s.getModel(requested), metadata)
+	if alias != "" {
+		metrics.ModelAliasResolutions.WithLabelValues(alias, model).Inc()
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("image.alias", alias), attribute.String("image.model", model))
+		s.logger.DebugContext(ctx, "Model alias resolved", "alias", alias, "model", model)
+	}
+	return model, alias
+}
+
+// validateModel 按模型注册表验证补全默认值后的模型、尺寸、参考图片和图片数量
+func (s *ImageService) validateModel(req models.Request) error {
+	return s.registry.Load().Validate(req)
+}
+
+// ListModels 列出可用的模型及其能力和价格，以及模型别名和故障转移链
+func (s *ImageService) ListModels(ctx context.Context, req *imagev1.ListModelsRequest) (*imagev1.ListModelsResponse, error) {
+	cfg := s.config.Load()
+	registry := s.registry.Load()
+	pricing := s.pricing.Load()
+
+	response := &imagev1.ListModelsResponse{
+		DefaultModel: cfg.Image.Model,
+		DefaultSize:  cfg.Image.DefaultSize,
+		Currency:     pricing.Currency,
+	}
+	for _, model := range registry.List() {
+		if req.Provider != "" && model.Provider != req.Provider {
+			continue
+		}
+
+		info := &imagev1.ModelInfo{
+			Name:               model.Name,
+			Provider:           model.Provider,
+			Sizes:              model.Sizes,
+			AspectRatios:       model.AspectRatios,
+			MaxReferenceImages: int32(model.MaxReferenceImages),
+			Sequential:         model.Sequential,
+			MaxImages:          int32(model.MaxImages),
+		}
+		if price, ok := pricing.Price(model.Name); ok {
+			info.Pricing = &imagev1.ModelPricing{PerImage: price.PerImage, Sizes: price.Sizes}
+		}
+		response.Models = append(response.Models, info)
+	}
+	for _, alias := range registry.Aliases() {
+		info := &imagev1.ModelAlias{Name: alias.Name, StickyKey: alias.StickyKey, FailoverOn: alias.FailoverOn}
+		for _, target := range alias.Targets {
+			info.Targets = append(info.Targets, &imagev1.AliasTarget{Model: target.Model, Weight: int32(target.Weight)})
+		}
+		for _, fallback := range alias.Fallbacks {
+			info.Fallbacks = append(info.Fallbacks, &imagev1.ModelFallback{Model: fallback.Model, Provider: fallback.Provider})
+		}
+		response.Aliases = append(response.Aliases, info)
+	}
+	return response, nil
+}
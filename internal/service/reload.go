@@ -0,0 +1,97 @@
+package service
+
+import (
+	"sia/internal/config"
+	"sia/internal/domain"
+)
+
+// newImageClientConfig 转换图片客户端配置
+func newImageClientConfig(cfg config.ImageConfig) *domain.ImageClientConfig {
+	return &domain.ImageClientConfig{
+		Keys:        upstreamKeys(cfg),
+		BaseURL:     cfg.BaseURL,
+		Model:       cfg.Model,
+		DefaultSize: cfg.DefaultSize,
+		Timeout:     cfg.Timeout,
+		MaxRetries:  cfg.MaxRetries,
+
+		BreakerFailures: cfg.BreakerFailures,
+		BreakerCooldown: cfg.BreakerCooldown,
+
+		KeySelection:         cfg.KeySelection,
+		KeyRateLimitCooldown: cfg.KeyRateLimitCooldown,
+		KeyAuthCooldown:      cfg.KeyAuthCooldown,
+	}
+}
+
+// upstreamKeys 转换上游API Key池
+func upstreamKeys(cfg config.ImageConfig) []domain.UpstreamKey {
+	pool := cfg.UpstreamKeys()
+	keys := make([]domain.UpstreamKey, len(pool))
+	for i, key := range pool {
+		keys[i] = domain.UpstreamKey{ID: key.ID, Key: key.Key}
+	}
+	return keys
+}
+
+// Reload 应用热加载的配置：上游密钥与默认模型、模型注册表、限流、配额、价格、预算和图片地址签名密钥
+// 调用方负责确认只有可以热加载的配置项发生了变化；进行中的请求继续使用原配置
+func (s *ImageService) Reload(cfg *config.Config) {
+	s.imageClient.SetConfig(newImageClientConfig(cfg.Image))
+	for name, provider := range cfg.Models.Providers {
+		// 新增的上游服务需要重启才会创建客户端
+		if client, ok := s.providers[name]; ok {
+			client.SetConfig(newProviderClientConfig(cfg.Image, provider))
+		}
+	}
+	if s.limiter != nil {
+		s.limiter.SetConfig(limiterConfig(cfg.RateLimit))
+	}
+	s.quota.Store(newQuota(s.ledger, cfg))
+	s.registry.Store(cfg.Models.Registry())
+	s.pricing.Store(newPriceTable(cfg))
+	s.budget.Store(newBudget(s.ledger, cfg))
+	s.signer.Store(newURLSigner(cfg.Storage))
+	s.config.Store(cfg)
+}
+
+// RotateAPIKeys 轮换上游API Key池，只使用image中的密钥，不影响其他配置；未轮换的密钥保留使用统计和暂停状态
+// 新的密钥同时写入当前配置，之后的轮换以其为基础
+func (s *ImageService) RotateAPIKeys(image config.ImageConfig) {
+	cfg := *s.config.Load()
+	cfg.Image.APIKey = image.APIKey
+	cfg.Image.APIKeys = image.APIKeys
+	s.config.Store(&cfg)
+	s.imageClient.SetKeys(upstreamKeys(cfg.Image))
+}
+
+// RotateProviderKeys 轮换备用上游服务的API Key池，未创建客户端的上游服务忽略；新的密钥同时写入当前配置
+func (s *ImageService) RotateProviderKeys(name string, keys []config.UpstreamKeyConfig) {
+	client, ok := s.providers[name]
+	if !ok {
+		return
+	}
+
+	cfg := *s.config.Load()
+	// 复制map，当前配置可能正在被其他请求读取
+	providers := make(map[string]config.ProviderConfig, len(cfg.Models.Providers))
+	for providerName, provider := range cfg.Models.Providers {
+		providers[providerName] = provider
+	}
+	provider := providers[name]
+	provider.APIKeys = keys
+	providers[name] = provider
+	cfg.Models.Providers = providers
+	s.config.Store(&cfg)
+	client.SetKeys(newProviderClientConfig(cfg.Image, provider).Keys)
+}
+
+// Config 返回当前生效的配置（包括热加载和密钥轮换的结果），调用方不能修改
+func (s *ImageService) Config() *config.Config {
+	return s.config.Load()
+}
+
+// UpstreamKeys 返回上游API Key池中各密钥的使用情况和健康状态
+func (s *ImageService) UpstreamKeys() []domain.KeyStatus {
+	return s.imageClient.Keys().Status()
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	imagev1 "sia/api/image/v1"
+	"sia/internal/config"
+	"sia/internal/storage"
+)
+
+const (
+	// defaultGCItems 默认列出的过期对象数
+	defaultGCItems = 100
+	// maxGCItems 最多列出的过期对象数
+	maxGCItems = 1000
+)
+
+// newGC 根据保留策略配置创建GC，未启用存储时返回nil
+func (s *ImageService) newGC(cfg config.StorageConfig) (*storage.GC, error) {
+	store := s.BlobStore()
+	if store == nil {
+		return nil, nil
+	}
+
+	archive, err := newArchiveStore(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive store: %w", err)
+	}
+
+	var audit *storage.AuditLog
+	if cfg.Retention.AuditFile != "" {
+		if audit, err = storage.NewAuditLog(cfg.Retention.AuditFile); err != nil {
+			return nil, err
+		}
+	}
+
+	policies := make([]storage.RetentionPolicy, len(cfg.Retention.Policies))
+	for i, policy := range cfg.Retention.Policies {
+		policies[i] = storage.RetentionPolicy(policy)
+	}
+
+	return storage.NewGC(store, storage.GCOptions{
+		Policies: policies,
+		Default:  storage.RetentionPolicy{Name: "default", KeepDays: cfg.Retention.KeepDays, Action: storage.ActionDelete},
+		Grace:    time.Duration(cfg.Retention.Grace) * time.Second,
+		Archive:  archive,
+		TaskTTL:  time.Duration(cfg.Retention.TaskTTL) * time.Second,
+		Audit:    audit,
+	}), nil
+}
+
+// newArchiveStore 创建归档存储，未配置时返回nil
+func newArchiveStore(cfg config.StorageConfig) (storage.BlobStore, error) {
+	archive := cfg.Retention.Archive
+	switch archive.Backend {
+	case "local":
+		return storage.NewLocalStore(archive.LocalDir, "")
+	case "s3":
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:  archive.S3.Endpoint,
+			Region:    archive.S3.Region,
+			Bucket:    archive.S3.Bucket,
+			AccessKey: archive.S3.AccessKey,
+			SecretKey: archive.S3.SecretKey,
+			PathStyle: archive.S3.PathStyle,
+			Timeout:   time.Duration(cfg.DownloadTimeout) * time.Second,
+		})
+	default:
+		return nil, nil
+	}
+}
+
+// RunRetention 按间隔执行后台GC，直到ctx结束；未启用存储或保留策略时直接返回
+func (s *ImageService) RunRetention(ctx context.Context) {
+	retention := s.config.Load().Storage.Retention
+	if s.gc == nil || !retention.Enabled {
+		return
+	}
+
+	mode := storage.GCModeRun
+	if retention.DryRun {
+		mode = storage.GCModeDryRun
+	}
+
+	ticker := time.NewTicker(time.Duration(retention.Interval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		report, err := s.gc.Run(ctx, storage.GCRequest{Mode: mode})
+		if errors.Is(err, storage.ErrGCRunning) {
+			continue
+		}
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Storage GC failed", "error", err)
+		}
+		if report != nil {
+			s.logger.InfoContext(ctx, "Storage GC finished",
+				"run_id", report.RunID,
+				"mode", report.Mode,
+				"scanned", report.Totals.ScannedObjects,
+				"expired", report.Totals.ExpiredObjects,
+				"deleted", report.Totals.DeletedObjects,
+				"archived", report.Totals.ArchivedObjects,
+				"failed", report.Totals.FailedObjects)
+		}
+	}
+}
+
+// RunStorageGC 按保留策略回收存储中的图片，支持试运行和统计报告
+func (s *ImageService) RunStorageGC(ctx context.Context, req *imagev1.RunStorageGCRequest) (*imagev1.RunStorageGCResponse, error) {
+	if s.gc == nil {
+		return nil, status.Error(codes.FailedPrecondition, "image storage is not enabled")
+	}
+
+	maxItems := int(req.MaxItems)
+	if maxItems == 0 {
+		maxItems = defaultGCItems
+	}
+	if maxItems < 0 || maxItems > maxGCItems {
+		return nil, status.Errorf(codes.InvalidArgument, "max_items must be between 1 and %d", maxGCItems)
+	}
+
+	gcReq := storage.GCRequest{Mode: storage.GCModeDryRun, MaxItems: maxItems}
+	switch req.Mode {
+	case imagev1.StorageGCMode_STORAGE_GC_MODE_RUN:
+		gcReq.Mode = storage.GCModeRun
+	case imagev1.StorageGCMode_STORAGE_GC_MODE_REPORT:
+		gcReq.Mode = storage.GCModeReport
+	}
+	if req.Tenant != "" {
+		gcReq.Prefix = storageSegment(req.Tenant) + "/"
+	}
+
+	s.logger.InfoContext(ctx, "Running storage GC", "mode", gcReq.Mode, "tenant", req.Tenant)
+
+	report, err := s.gc.Run(ctx, gcReq)
+	if errors.Is(err, storage.ErrGCRunning) {
+		return nil, status.Error(codes.Aborted, err.Error())
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Storage GC failed", "error", err)
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &imagev1.RunStorageGCResponse{
+		Report:  convertGCReport(report),
+		LastRun: convertGCReport(s.gc.LastRun()),
+	}, nil
+}
+
+// convertGCReport 转换GC执行结果
+func convertGCReport(report *storage.GCReport) *imagev1.StorageGCReport {
+	if report == nil {
+		return nil
+	}
+
+	result := &imagev1.StorageGCReport{
+		RunId:          report.RunID,
+		Mode:           convertGCMode(report.Mode),
+		StartedAt:      timestamppb.New(report.StartedAt),
+		FinishedAt:     timestamppb.New(report.FinishedAt),
+		Totals:         convertGCTotals(report.Totals),
+		ItemsTruncated: report.Truncated,
+	}
+
+	names := make([]string, 0, len(report.Policies))
+	for name := range report.Policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		result.Policies = append(result.Policies, &imagev1.StorageGCPolicyTotals{
+			Policy: name,
+			Totals: convertGCTotals(*report.Policies[name]),
+		})
+	}
+
+	for _, item := range report.Items {
+		result.Items = append(result.Items, &imagev1.StorageGCItem{
+			Key:        item.Key,
+			Tenant:     item.Tenant,
+			Policy:     item.Policy,
+			Action:     item.Action,
+			Reason:     item.Reason,
+			SizeBytes:  item.Size,
+			ModifiedAt: timestamppb.New(item.ModTime),
+			Error:      item.Error,
+		})
+	}
+	return result
+}
+
+// convertGCTotals 转换GC统计
+func convertGCTotals(totals storage.GCTotals) *imagev1.StorageGCTotals {
+	return &imagev1.StorageGCTotals{
+		ScannedObjects:  totals.ScannedObjects,
+		ScannedBytes:    totals.ScannedBytes,
+		ExpiredObjects:  totals.ExpiredObjects,
+		ExpiredBytes:    totals.ExpiredBytes,
+		DeletedObjects:  totals.DeletedObjects,
+		ArchivedObjects: totals.ArchivedObjects,
+		FailedObjects:   totals.FailedObjects,
+	}
+}
+
+// convertGCMode 转换GC执行模式
+func convertGCMode(mode string) imagev1.StorageGCMode {
+	switch mode {
+	case storage.GCModeRun:
+		return imagev1.StorageGCMode_STORAGE_GC_MODE_RUN
+	case storage.GCModeReport:
+		return imagev1.StorageGCMode_STORAGE_GC_MODE_REPORT
+	default:
+		return imagev1.StorageGCMode_STORAGE_GC_MODE_DRY_RUN
+	}
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	imagev1 "sia/api/image/v1"
+	"sia/internal/config"
+	"sia/internal/domain"
+	"sia/internal/storage"
+	"sia/internal/tracing"
+)
+
+// persistConcurrency 同一响应中并行下载的图片数
+const persistConcurrency = 4
+
+// newPersister 根据配置创建图片持久化器，未启用存储时返回nil
+func newPersister(cfg config.StorageConfig) (*storage.Persister, error) {
+	var store storage.BlobStore
+	var err error
+	switch cfg.Backend {
+	case "local":
+		store, err = storage.NewLocalStore(cfg.LocalDir, cfg.PublicURL)
+	case "s3":
+		store, err = storage.NewS3Store(storage.S3Config{
+			Endpoint:  cfg.S3.Endpoint,
+			Region:    cfg.S3.Region,
+			Bucket:    cfg.S3.Bucket,
+			AccessKey: cfg.S3.AccessKey,
+			SecretKey: cfg.S3.SecretKey,
+			PathStyle: cfg.S3.PathStyle,
+			PublicURL: cfg.PublicURL,
+			Timeout:   time.Duration(cfg.DownloadTimeout) * time.Second,
+		})
+	default:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.NewPersister(store, int64(cfg.MaxImageSize)<<20, time.Duration(cfg.DownloadTimeout)*time.Second), nil
+}
+
+// persistImages 下载响应中的图片并保存到对象存储
+// 保存失败的图片只记录日志，调用方仍可使用上游URL，不影响生成结果
+// tags为请求的metadata，以tag-前缀写入对象元信息，供保留策略匹配
+func (s *ImageService) persistImages(ctx context.Context, response *domain.ImageGenerationResponse, tenant, taskID string, tags map[string]string) {
+	if s.persister == nil || len(response.Data) == 0 {
+		return
+	}
+
+	ctx, span := tracing.Start(ctx, "persist")
+	defer span.End()
+
+	// 元信息键只使用小写字母和连字符，S3以x-amz-meta-*请求头保存
+	metadata := map[string]string{
+		storage.MetaTenant: tenant,
+		"request-id":       response.ID,
+		"model":            response.Model,
+	}
+	if taskID != "" {
+		metadata[storage.MetaTaskID] = taskID
+	}
+	for name, value := range tags {
+		if name = metadataName(name); name != "" && isPrintableASCII(value) {
+			metadata[storage.MetaTagPrefix+name] = value
+		}
+	}
+	prefix := storageSegment(tenant)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, persistConcurrency)
+	for i := range response.Data {
+		wg.Add(1)
+		go func(index int, image *domain.ImageData) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			stored, err := s.saveImage(ctx, prefix, image, metadata)
+			if err != nil {
+				s.logger.WarnContext(ctx, "Failed to persist image", "index", index, "url", image.URL, "error", err)
+				return
+			}
+			image.Stored = stored
+		}(i, &response.Data[i])
+	}
+	wg.Wait()
+}
+
+// saveImage 保存单张图片，b64_json格式直接解码，否则下载URL
+func (s *ImageService) saveImage(ctx context.Context, prefix string, image *domain.ImageData, metadata map[string]string) (*domain.StoredImage, error) {
+	var stored *storage.Image
+	var err error
+	switch {
+	case image.B64JSON != "":
+		data, decodeErr := base64.StdEncoding.DecodeString(image.B64JSON)
+		if decodeErr != nil {
+			return nil, fmt.Errorf("invalid b64_json: %w", decodeErr)
+		}
+		stored, err = s.persister.SaveBytes(ctx, prefix, data, metadata)
+	case image.URL != "":
+		stored, err = s.persister.SaveURL(ctx, prefix, image.URL, metadata)
+	default:
+		return nil, fmt.Errorf("image has neither url nor b64_json")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.StoredImage{
+		URL:         stored.URL,
+		Key:         stored.Key,
+		SHA256:      stored.SHA256,
+		Size:        stored.Size,
+		Width:       stored.Width,
+		Height:      stored.Height,
+		ContentType: stored.ContentType,
+	}, nil
+}
+
+// storageSegment 将租户名转换为可用作对象键的路径片段
+func storageSegment(name string) string {
+	name = strings.Trim(name, ".")
+	if name == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// newURLSigner 根据配置创建图片地址签名器，未启用存储或未配置签名密钥时返回nil
+func newURLSigner(cfg config.StorageConfig) *storage.URLSigner {
+	if cfg.Backend == "none" || len(cfg.SigningKeys) == 0 {
+		return nil
+	}
+
+	keys := make([]storage.SigningKey, len(cfg.SigningKeys))
+	for i, key := range cfg.SigningKeys {
+		keys[i] = storage.SigningKey{ID: key.ID, Secret: []byte(key.Secret)}
+	}
+	return storage.NewURLSigner(keys, time.Duration(cfg.SignedURLTTL)*time.Second, cfg.ServeBaseURL)
+}
+
+// BlobStore 返回图片存储，未启用存储时返回nil
+func (s *ImageService) BlobStore() storage.BlobStore {
+	if s.persister == nil {
+		return nil
+	}
+	return s.persister.Store()
+}
+
+// URLSigner 返回当前的图片地址签名器，未配置签名密钥时返回nil；签名密钥热加载后返回新的签名器
+func (s *ImageService) URLSigner() *storage.URLSigner {
+	return s.signer.Load()
+}
+
+// metadataName 将元数据键转换为小写字母、数字和连字符
+func metadataName(name string) string {
+	return strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return '-'
+		}
+	}, name), "-")
+}
+
+// isPrintableASCII 检查值能否作为HTTP头保存（S3以请求头保存元信息）
+func isPrintableASCII(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] < 0x20 || value[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// convertStoredImage 转换持久化图片信息，每次转换都生成新的签名地址
+func (s *ImageService) convertStoredImage(stored *domain.StoredImage) *imagev1.StoredImage {
+	if stored == nil {
+		return nil
+	}
+	result := &imagev1.StoredImage{
+		Url:         stored.URL,
+		Key:         stored.Key,
+		Sha256:      stored.SHA256,
+		SizeBytes:   stored.Size,
+		Width:       int32(stored.Width),
+		Height:      int32(stored.Height),
+		ContentType: stored.ContentType,
+	}
+	if signer := s.signer.Load(); signer != nil {
+		signedURL, expires := signer.Sign(stored.Key)
+		result.SignedUrl = signedURL
+		result.SignedUrlExpiresAt = timestamppb.New(expires)
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	imagev1 "sia/api/image/v1"
+	"sia/internal/domain"
+)
+
+// SubscribeTask 订阅任务事件，返回序号大于afterSeq的历史事件和后续事件的订阅
+// 与GetImageTask一样，任务不存在或调用方无权访问时返回NOT_FOUND
+func (s *ImageService) SubscribeTask(ctx context.Context, taskID string, afterSeq int) ([]domain.TaskEvent, *domain.TaskSubscription, error) {
+	task, exists := s.taskManager.GetTask(taskID)
+	if !exists || !s.canAccessTask(ctx, task) {
+		return nil, nil, status.Error(codes.NotFound, "Task not found")
+	}
+
+	history, sub, exists := s.taskManager.Subscribe(taskID, afterSeq)
+	if !exists {
+		return nil, nil, status.Error(codes.NotFound, "Task not found")
+	}
+
+	s.logger.DebugContext(ctx, "Task events subscribed", "task_id", taskID, "after", afterSeq, "replayed", len(history))
+	return history, sub, nil
+}
+
+// TaskEventPayload 获取任务事件对外输出的内容
+// 状态事件只包含事件发生时的状态，完成和失败事件包含完整的任务结果
+func (s *ImageService) TaskEventPayload(event domain.TaskEvent) *imagev1.GetImageTaskResponse {
+	if event.Terminal() {
+		if task, exists := s.taskManager.GetTask(event.TaskID); exists {
+			return s.convertTask(task)
+		}
+	}
+
+	return &imagev1.GetImageTaskResponse{
+		TaskId:    event.TaskID,
+		Status:    s.convertTaskStatus(event.Status),
+		UpdatedAt: timestamppb.New(event.Time),
+	}
+}
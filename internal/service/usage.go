@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	imagev1 "sia/api/image/v1"
+	"sia/internal/auth"
+	"sia/internal/config"
+	"sia/internal/domain"
+	"sia/internal/usage"
+)
+
+// newQuota 根据配置创建配额检查器，未启用时返回nil
+func newQuota(ledger *usage.Ledger, cfg *config.Config) *usage.Quota {
+	if !cfg.Usage.Quota.Enabled {
+		return nil
+	}
+
+	tiers := make(map[string]usage.Limits, len(cfg.Usage.Quota.Tiers))
+	for name, tier := range cfg.Usage.Quota.Tiers {
+		tiers[name] = usage.Limits(tier)
+	}
+
+	tenants := make(map[string]usage.Limits, len(cfg.Usage.Quota.Tenants))
+	for name, tenant := range cfg.Usage.Quota.Tenants {
+		tenants[name] = usage.Limits(tenant)
+	}
+
+	return usage.NewQuota(ledger, usage.QuotaConfig{
+		DefaultTier: cfg.RateLimit.DefaultTier,
+		Tiers:       tiers,
+		Tenants:     tenants,
+	})
+}
+
+// callerIdentity 获取调用方的租户、客户端ID和分级
+func callerIdentity(ctx context.Context) (tenant, clientID, tier string) {
+	if principal, ok := auth.FromContext(ctx); ok {
+		return principal.Tenant, principal.ClientID, principal.Tier
+	}
+	return anonymousSubject, "", ""
+}
+
+// checkQuota 检查调用方配额并预留本次请求的图片数，images为本次请求最多可能生成的图片数
+// 请求结束后必须调用返回的释放函数（成功时在记录用量之后），未启用配额时释放函数为空操作
+func (s *ImageService) checkQuota(ctx context.Context, images int) (func(), error) {
+	quota := s.quota.Load()
+	if quota == nil {
+		return func() {}, nil
+	}
+
+	tenant, _, tier := callerIdentity(ctx)
+	release, err := quota.Reserve(tenant, tier, images, time.Now())
+	if err == nil {
+		return release, nil
+	}
+
+	s.logger.WarnContext(ctx, "Request rejected by quota", "tenant", tenant, "error", err)
+
+	var quotaErr *usage.QuotaError
+	if !errors.As(err, &quotaErr) {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	st := status.New(codes.ResourceExhausted, quotaErr.Error())
+	detailed, detailErr := st.WithDetails(
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Until(quotaErr.ResetsAt))},
+		&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{
+			Subject:     "tenant:" + tenant,
+			Description: quotaErr.Error(),
+		}}},
+	)
+	if detailErr != nil {
+		return nil, st.Err()
+	}
+	return nil, detailed.Err()
+}
+
+// recordUsage 计算实际费用并记录一次成功请求的用量
+func (s *ImageService) recordUsage(ctx context.Context, tenant, clientID, method string, plan *costPlan, response *domain.ImageGenerationResponse) {
+	s.applyCost(plan, response)
+
+	err := s.ledger.Record(usage.Entry{
+		RequestID:       response.ID,
+		Tenant:          tenant,
+		ClientID:        clientID,
+		Model:           response.Model,
+		Method:          method,
+		GeneratedImages: response.Usage.GeneratedImages,
+		OutputTokens:    response.Usage.OutputTokens,
+		TotalTokens:     response.Usage.TotalTokens,
+		Cost:            response.Cost.Actual,
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record usage", "request_id", response.ID, "error", err)
+	}
+}
+
+// GetUsage 查询用量
+func (s *ImageService) GetUsage(ctx context.Context, req *imagev1.GetUsageRequest) (*imagev1.GetUsageResponse, error) {
+	tenant := req.Tenant
+	var tier string
+
+	// 非admin只能查询本租户；admin不指定租户时汇总全部租户
+	if principal, ok := auth.FromContext(ctx); ok {
+		isAdmin := principal.HasScope(auth.ScopeAdmin)
+		if tenant == "" && !isAdmin {
+			tenant = principal.Tenant
+		}
+		if tenant != principal.Tenant && !isAdmin {
+			return nil, status.Error(codes.PermissionDenied, "cannot read usage of another tenant")
+		}
+		if tenant == principal.Tenant {
+			tier = principal.Tier
+		}
+	}
+
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if req.StartTime != nil {
+		start = req.StartTime.AsTime()
+	}
+	end := now
+	if req.EndTime != nil {
+		end = req.EndTime.AsTime()
+	}
+	if !end.After(start) {
+		return nil, status.Error(codes.InvalidArgument, "end_time must be after start_time")
+	}
+
+	summary := s.ledger.Summarize(usage.Filter{
+		Tenant: tenant,
+		Model:  req.Model,
+		Start:  start,
+		End:    end,
+	})
+
+	response := &imagev1.GetUsageResponse{
+		Tenant:    tenant,
+		StartTime: timestamppb.New(summary.Start),
+		EndTime:   timestamppb.New(summary.End),
+		Total:     convertUsageTotals(summary.Total),
+		Currency:  s.pricing.Load().Currency,
+	}
+
+	for _, model := range summary.Models() {
+		response.Models = append(response.Models, &imagev1.ModelUsage{
+			Model: model,
+			Usage: convertUsageTotals(summary.ByModel[model]),
+		})
+	}
+
+	if quota := s.quota.Load(); quota != nil && tenant != "" {
+		for _, quota := range quota.Status(tenant, tier, now) {
+			response.Quotas = append(response.Quotas, &imagev1.QuotaStatus{
+				Window:   quota.Window,
+				Resource: quota.Resource,
+				Used:     quota.Used,
+				Limit:    quota.Limit,
+				ResetsAt: timestamppb.New(quota.ResetsAt),
+			})
+		}
+	}
+
+	if tenant != "" {
+		response.Budgets = s.budgetStatus(tenant, tier)
+	}
+
+	return response, nil
+}
+
+// convertUsageTotals 转换用量合计
+func convertUsageTotals(totals usage.Totals) *imagev1.UsageSummary {
+	return &imagev1.UsageSummary{
+		Requests:        totals.Requests,
+		GeneratedImages: totals.GeneratedImages,
+		OutputTokens:    totals.OutputTokens,
+		TotalTokens:     totals.TotalTokens,
+		Cost:            totals.Cost,
+	}
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// AuditRecord GC审计记录：每个被删除或归档的对象一条，每次执行结束时一条汇总（Action为run）
+type AuditRecord struct {
+	Time    time.Time `json:"time"`
+	RunID   string    `json:"run_id"`
+	Mode    string    `json:"mode"`
+	Action  string    `json:"action"`
+	Key     string    `json:"key,omitempty"`
+	Tenant  string    `json:"tenant,omitempty"`
+	Policy  string    `json:"policy,omitempty"`
+	Size    int64     `json:"size,omitempty"`
+	Reason  string    `json:"reason,omitempty"`
+	Error   string    `json:"error,omitempty"`
+	Summary *GCTotals `json:"summary,omitempty"`
+}
+
+// AuditLog 追加写入JSONL文件的审计日志
+type AuditLog struct {
+	mutex sync.Mutex
+	file  *os.File
+}
+
+// NewAuditLog 打开（或创建）审计日志文件
+func NewAuditLog(filename string) (*AuditLog, error) {
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	return &AuditLog{file: file}, nil
+}
+
+// Write 写入一条记录
+func (a *AuditLog) Write(record AuditRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	_, err = a.file.Write(append(line, '\n'))
+	return err
+}
+
+// Close 关闭审计日志
+func (a *AuditLog) Close() error {
+	return a.file.Close()
+}
@@ -0,0 +1,375 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"sia/internal/metrics"
+)
+
+// 保留策略的处理方式
+const (
+	ActionDelete  = "delete"
+	ActionArchive = "archive"
+)
+
+// GC执行模式
+const (
+	GCModeDryRun = "dry_run" // 只列出将被回收的对象
+	GCModeRun    = "run"     // 删除或归档过期对象
+	GCModeReport = "report"  // 只统计各策略下的对象数和大小
+)
+
+// 对象元信息中由服务写入的键
+const (
+	MetaTenant    = "tenant"
+	MetaTaskID    = "task-id"
+	MetaTagPrefix = "tag-" // 请求元数据以tag-前缀保存，供保留策略匹配
+)
+
+// ErrGCRunning 已有GC正在执行
+var ErrGCRunning = errors.New("storage GC is already running")
+
+// RetentionPolicy 保留策略，按租户和请求元数据匹配对象
+type RetentionPolicy struct {
+	Name          string
+	Tenant        string            // 为空时匹配所有租户
+	Tags          map[string]string // 请求元数据，全部相等才匹配
+	KeepDays      int               // 超过该天数的对象过期，0表示不按时间过期
+	LiveTasksOnly bool              // 任务的对象超过GCOptions.TaskTTL后过期，不属于任务的对象只按KeepDays过期
+	Action        string            // delete, archive
+}
+
+// matches 判断对象是否适用该策略
+func (p *RetentionPolicy) matches(tenant string, metadata map[string]string) bool {
+	if p.Tenant != "" && p.Tenant != tenant {
+		return false
+	}
+	for name, value := range p.Tags {
+		if metadata[MetaTagPrefix+name] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// needsMetadata 匹配或判断过期是否需要对象元信息
+func (p *RetentionPolicy) needsMetadata() bool {
+	return len(p.Tags) > 0 || p.LiveTasksOnly
+}
+
+// GCOptions GC配置
+type GCOptions struct {
+	Policies []RetentionPolicy // 按顺序匹配，第一个匹配的策略生效
+	Default  RetentionPolicy   // 没有策略匹配时使用
+	Grace    time.Duration     // 新写入的对象在该时间内不会被回收
+	Archive  BlobStore         // 归档存储，为nil时archive策略按delete处理
+	TaskTTL  time.Duration     // 任务的对象在写入后该时间内视为被存活任务引用
+	Audit    *AuditLog         // 为nil时不写审计记录
+}
+
+// GCRequest 一次GC执行的参数
+type GCRequest struct {
+	Mode     string
+	Prefix   string // 只处理键以该前缀开头的对象（如某个租户）
+	MaxItems int    // 报告中最多列出的对象数
+}
+
+// GCTotals GC统计
+type GCTotals struct {
+	ScannedObjects  int64 `json:"scanned_objects"`
+	ScannedBytes    int64 `json:"scanned_bytes"`
+	ExpiredObjects  int64 `json:"expired_objects"`
+	ExpiredBytes    int64 `json:"expired_bytes"`
+	DeletedObjects  int64 `json:"deleted_objects"`
+	ArchivedObjects int64 `json:"archived_objects"`
+	FailedObjects   int64 `json:"failed_objects"`
+}
+
+// GCItem 过期对象
+type GCItem struct {
+	Key     string
+	Tenant  string
+	Policy  string
+	Action  string
+	Reason  string
+	Size    int64
+	ModTime time.Time
+	Error   string
+}
+
+// GCReport GC执行结果
+type GCReport struct {
+	RunID      string
+	Mode       string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Totals     GCTotals
+	Policies   map[string]*GCTotals
+	Items      []GCItem
+	Truncated  bool
+}
+
+// GC 按保留策略回收存储中的图片
+type GC struct {
+	store   BlobStore
+	options GCOptions
+	running sync.Mutex
+	mutex   sync.RWMutex
+	lastRun *GCReport
+	now     func() time.Time
+}
+
+// NewGC 创建GC
+func NewGC(store BlobStore, options GCOptions) *GC {
+	if options.Default.Name == "" {
+		options.Default.Name = "default"
+	}
+	return &GC{
+		store:   store,
+		options: options,
+		now:     time.Now,
+	}
+}
+
+// LastRun 返回最近一次执行（run模式）的结果，尚未执行时返回nil
+func (g *GC) LastRun() *GCReport {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+	return g.lastRun
+}
+
+// Run 执行一次GC；run模式同一时间只允许一个执行
+func (g *GC) Run(ctx context.Context, req GCRequest) (*GCReport, error) {
+	if req.Mode == GCModeRun {
+		if !g.running.TryLock() {
+			return nil, ErrGCRunning
+		}
+		defer g.running.Unlock()
+	}
+
+	started := g.now()
+	report := &GCReport{
+		RunID:     fmt.Sprintf("gc_%d", started.UnixNano()),
+		Mode:      req.Mode,
+		StartedAt: started,
+		Policies:  make(map[string]*GCTotals),
+	}
+
+	err := g.store.List(ctx, req.Prefix, func(info *ObjectInfo) error {
+		g.inspect(ctx, req, report, info)
+		return nil
+	})
+	report.FinishedAt = g.now()
+
+	if req.Mode == GCModeRun {
+		// 只保留汇总，明细已写入审计日志
+		summary := *report
+		summary.Items = nil
+		g.mutex.Lock()
+		g.lastRun = &summary
+		g.mutex.Unlock()
+	}
+	if req.Mode != GCModeReport {
+		totals := report.Totals
+		g.audit(AuditRecord{Time: report.FinishedAt, RunID: report.RunID, Mode: req.Mode, Action: "run", Summary: &totals})
+	}
+
+	if err != nil {
+		return report, fmt.Errorf("failed to list objects: %w", err)
+	}
+	return report, nil
+}
+
+// inspect 检查单个对象，过期时按模式处理
+func (g *GC) inspect(ctx context.Context, req GCRequest, report *GCReport, info *ObjectInfo) {
+	policy := g.policyFor(ctx, info, g.tenantOf(info))
+	// 匹配策略时可能补全了元信息，重新确定租户
+	tenant := g.tenantOf(info)
+
+	stats := report.Policies[policy.Name]
+	if stats == nil {
+		stats = &GCTotals{}
+		report.Policies[policy.Name] = stats
+	}
+	stats.ScannedObjects++
+	stats.ScannedBytes += info.Size
+	report.Totals.ScannedObjects++
+	report.Totals.ScannedBytes += info.Size
+
+	reason := g.expired(policy, info)
+	if reason == "" {
+		return
+	}
+	stats.ExpiredObjects++
+	stats.ExpiredBytes += info.Size
+	report.Totals.ExpiredObjects++
+	report.Totals.ExpiredBytes += info.Size
+
+	if req.Mode == GCModeReport {
+		return
+	}
+
+	item := GCItem{
+		Key:     info.Key,
+		Tenant:  tenant,
+		Policy:  policy.Name,
+		Action:  g.actionFor(policy),
+		Reason:  reason,
+		Size:    info.Size,
+		ModTime: info.ModTime,
+	}
+
+	if req.Mode == GCModeRun {
+		if err := g.apply(ctx, item); err != nil {
+			item.Error = err.Error()
+			stats.FailedObjects++
+			report.Totals.FailedObjects++
+			metrics.StorageGCObjects.WithLabelValues(item.Action, "error").Inc()
+		} else {
+			if item.Action == ActionArchive {
+				stats.ArchivedObjects++
+				report.Totals.ArchivedObjects++
+			} else {
+				stats.DeletedObjects++
+				report.Totals.DeletedObjects++
+			}
+			metrics.StorageGCObjects.WithLabelValues(item.Action, "ok").Inc()
+			metrics.StorageGCBytes.WithLabelValues(item.Action).Add(float64(item.Size))
+		}
+		g.audit(AuditRecord{
+			Time:   g.now(),
+			RunID:  report.RunID,
+			Mode:   req.Mode,
+			Action: item.Action,
+			Key:    item.Key,
+			Tenant: item.Tenant,
+			Policy: item.Policy,
+			Size:   item.Size,
+			Reason: item.Reason,
+			Error:  item.Error,
+		})
+	}
+
+	if len(report.Items) < req.MaxItems {
+		report.Items = append(report.Items, item)
+	} else {
+		report.Truncated = true
+	}
+}
+
+// policyFor 查找适用于对象的策略，需要时读取对象元信息
+func (g *GC) policyFor(ctx context.Context, info *ObjectInfo, tenant string) *RetentionPolicy {
+	for i := range g.options.Policies {
+		policy := &g.options.Policies[i]
+		if policy.Tenant != "" && policy.Tenant != tenant {
+			continue
+		}
+		if policy.needsMetadata() && info.Metadata == nil {
+			g.loadMetadata(ctx, info)
+		}
+		if policy.matches(g.tenantOf(info), info.Metadata) {
+			return policy
+		}
+	}
+	if g.options.Default.needsMetadata() && info.Metadata == nil {
+		g.loadMetadata(ctx, info)
+	}
+	return &g.options.Default
+}
+
+// loadMetadata 通过Stat补全对象元信息，失败时置为空映射避免重复请求
+func (g *GC) loadMetadata(ctx context.Context, info *ObjectInfo) {
+	info.Metadata = map[string]string{}
+	if stat, err := g.store.Stat(ctx, info.Key); err == nil && stat.Metadata != nil {
+		info.Metadata = stat.Metadata
+	}
+}
+
+// tenantOf 对象所属租户：优先使用元信息，否则取对象键的第一段
+func (g *GC) tenantOf(info *ObjectInfo) string {
+	if tenant := info.Metadata[MetaTenant]; tenant != "" {
+		return tenant
+	}
+	tenant, _, _ := strings.Cut(info.Key, "/")
+	return tenant
+}
+
+// expired 判断对象是否过期，返回过期原因，未过期时返回空字符串
+func (g *GC) expired(policy *RetentionPolicy, info *ObjectInfo) string {
+	age := g.now().Sub(info.ModTime)
+	if age < g.options.Grace {
+		return ""
+	}
+	// 以对象的写入时间判断任务是否存活，不依赖内存中的任务状态，重启后结果不变
+	if policy.LiveTasksOnly && info.Metadata[MetaTaskID] != "" && age > g.options.TaskTTL {
+		return "not referenced by a live task"
+	}
+	if policy.KeepDays > 0 && age > time.Duration(policy.KeepDays)*24*time.Hour {
+		return fmt.Sprintf("older than %d days", policy.KeepDays)
+	}
+	return ""
+}
+
+// actionFor 策略的实际处理方式，未配置归档存储时归档按删除处理
+func (g *GC) actionFor(policy *RetentionPolicy) string {
+	if policy.Action == ActionArchive && g.options.Archive != nil {
+		return ActionArchive
+	}
+	return ActionDelete
+}
+
+// apply 删除或归档对象
+func (g *GC) apply(ctx context.Context, item GCItem) error {
+	if item.Action == ActionArchive {
+		if err := g.copyToArchive(ctx, item.Key); err != nil {
+			return err
+		}
+	}
+	return g.store.Delete(ctx, item.Key)
+}
+
+// copyToArchive 将对象连同元信息复制到归档存储
+func (g *GC) copyToArchive(ctx context.Context, key string) error {
+	object, info, err := g.store.Open(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to read object: %w", err)
+	}
+	defer object.Close()
+
+	data, err := io.ReadAll(object)
+	if err != nil {
+		return fmt.Errorf("failed to read object: %w", err)
+	}
+
+	metadata := info.Metadata
+	if metadata == nil {
+		if stat, err := g.store.Stat(ctx, key); err == nil {
+			metadata = stat.Metadata
+		}
+	}
+	if err := g.options.Archive.Put(ctx, key, data, info.ContentType, metadata); err != nil {
+		return fmt.Errorf("failed to archive object: %w", err)
+	}
+	return nil
+}
+
+// audit 写入审计记录
+func (g *GC) audit(record AuditRecord) {
+	if g.options.Audit != nil {
+		g.options.Audit.Write(record)
+	}
+}
+
+// Close 关闭审计日志
+func (g *GC) Close() error {
+	if g.options.Audit != nil {
+		return g.options.Audit.Close()
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memStore 内存对象存储，List与S3一样不返回元信息
+type memStore struct {
+	mutex   sync.Mutex
+	objects map[string]*memObject
+	now     func() time.Time
+}
+
+type memObject struct {
+	data        []byte
+	contentType string
+	modTime     time.Time
+	metadata    map[string]string
+}
+
+func newMemStore(now time.Time) *memStore {
+	return &memStore{objects: make(map[string]*memObject), now: func() time.Time { return now }}
+}
+
+func (s *memStore) Name() string { return "memory" }
+
+func (s *memStore) Put(ctx context.Context, key string, data []byte, contentType string, metadata map[string]string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.objects[key] = &memObject{data: data, contentType: contentType, modTime: s.now(), metadata: metadata}
+	return nil
+}
+
+func (s *memStore) Open(ctx context.Context, key string) (io.ReadSeekCloser, *ObjectInfo, error) {
+	info, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return nopCloser{bytes.NewReader(s.objects[key].data)}, info, nil
+}
+
+func (s *memStore) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	object, ok := s.objects[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &ObjectInfo{Key: key, Size: int64(len(object.data)), ContentType: object.contentType, ModTime: object.modTime, Metadata: object.metadata}, nil
+}
+
+func (s *memStore) Delete(ctx context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memStore) List(ctx context.Context, prefix string, fn func(*ObjectInfo) error) error {
+	s.mutex.Lock()
+	var infos []*ObjectInfo
+	for key, object := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			infos = append(infos, &ObjectInfo{Key: key, Size: int64(len(object.data)), ContentType: object.contentType, ModTime: object.modTime})
+		}
+	}
+	s.mutex.Unlock()
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+	for _, info := range infos {
+		if err := fn(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memStore) URL(key string) string { return "" }
+
+// keys 返回存储中的全部对象键
+func (s *memStore) keys() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	keys := make([]string, 0, len(s.objects))
+	for key := range s.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// put 写入修改时间为now-age的对象
+func (s *memStore) put(t *testing.T, key string, age time.Duration, metadata map[string]string) {
+	t.Helper()
+	if err := s.Put(context.Background(), key, []byte(key), "image/png", metadata); err != nil {
+		t.Fatal(err)
+	}
+	s.objects[key].modTime = s.now().Add(-age)
+}
+
+const day = 24 * time.Hour
+
+// newTestGC 创建包含各类对象的存储和GC：
+// 任务预览图按task_ttl过期，partner租户保留7天并归档，其余对象保留30天
+func newTestGC(t *testing.T, archive BlobStore, audit *AuditLog) (*GC, *memStore) {
+	t.Helper()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	store := newMemStore(now)
+
+	store.put(t, "acme/new.png", time.Minute, nil)
+	store.put(t, "acme/recent.png", 10*day, nil)
+	store.put(t, "acme/old.png", 40*day, nil)
+	store.put(t, "acme/task-fresh.png", 2*time.Hour, map[string]string{MetaTaskID: "task_1", MetaTagPrefix + "kind": "preview"})
+	store.put(t, "acme/task-stale.png", 3*day, map[string]string{MetaTaskID: "task_2", MetaTagPrefix + "kind": "preview"})
+	store.put(t, "acme/sync-preview.png", 3*day, map[string]string{MetaTagPrefix + "kind": "preview"})
+	store.put(t, "acme/sync-preview-old.png", 10*day, map[string]string{MetaTagPrefix + "kind": "preview"})
+	store.put(t, "partner/a.png", 8*day, map[string]string{MetaTenant: "partner"})
+	store.put(t, "partner/b.png", 2*day, map[string]string{MetaTenant: "partner"})
+
+	gc := NewGC(store, GCOptions{
+		Policies: []RetentionPolicy{
+			{Name: "previews", Tags: map[string]string{"kind": "preview"}, KeepDays: 7, LiveTasksOnly: true, Action: ActionDelete},
+			{Name: "partner", Tenant: "partner", KeepDays: 7, Action: ActionArchive},
+		},
+		Default: RetentionPolicy{KeepDays: 30, Action: ActionDelete},
+		Grace:   time.Hour,
+		Archive: archive,
+		TaskTTL: day,
+		Audit:   audit,
+	})
+	gc.now = func() time.Time { return now }
+	return gc, store
+}
+
+// expiredKeys 报告中列出的对象键
+func expiredKeys(report *GCReport) []string {
+	keys := make([]string, 0, len(report.Items))
+	for _, item := range report.Items {
+		keys = append(keys, item.Key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestGCExpiry(t *testing.T) {
+	gc, store := newTestGC(t, nil, nil)
+
+	report, err := gc.Run(context.Background(), GCRequest{Mode: GCModeDryRun, MaxItems: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]GCItem{
+		// 任务图片按写入时间判断存活（task-fresh保留），不属于任务的图片只按keep_days过期（sync-preview保留）
+		"acme/old.png":              {Policy: "default", Action: ActionDelete, Reason: "older than 30 days"},
+		"acme/task-stale.png":       {Policy: "previews", Action: ActionDelete, Reason: "not referenced by a live task"},
+		"acme/sync-preview-old.png": {Policy: "previews", Action: ActionDelete, Reason: "older than 7 days"},
+		// 未配置归档存储时归档按删除处理
+		"partner/a.png": {Policy: "partner", Action: ActionDelete, Reason: "older than 7 days", Tenant: "partner"},
+	}
+	if len(report.Items) != len(want) {
+		t.Fatalf("expired = %v, want %d objects", expiredKeys(report), len(want))
+	}
+	for _, item := range report.Items {
+		expected, ok := want[item.Key]
+		if !ok {
+			t.Errorf("%s expired unexpectedly (%s)", item.Key, item.Reason)
+			continue
+		}
+		if item.Policy != expected.Policy || item.Action != expected.Action || item.Reason != expected.Reason {
+			t.Errorf("%s = %+v, want %+v", item.Key, item, expected)
+		}
+		if expected.Tenant != "" && item.Tenant != expected.Tenant {
+			t.Errorf("%s tenant = %q, want %q", item.Key, item.Tenant, expected.Tenant)
+		}
+	}
+
+	// 试运行不修改存储
+	if got := len(store.keys()); got != 9 {
+		t.Errorf("objects after dry run = %d, want 9", got)
+	}
+	if report.Totals.DeletedObjects != 0 || gc.LastRun() != nil {
+		t.Errorf("dry run deleted objects or recorded a run: %+v", report.Totals)
+	}
+}
+
+func TestGCReport(t *testing.T) {
+	gc, store := newTestGC(t, nil, nil)
+
+	report, err := gc.Run(context.Background(), GCRequest{Mode: GCModeReport, MaxItems: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Items) != 0 {
+		t.Errorf("report mode listed items: %v", expiredKeys(report))
+	}
+	if report.Totals.ScannedObjects != 9 || report.Totals.ExpiredObjects != 4 {
+		t.Errorf("totals = %+v, want 9 scanned and 4 expired", report.Totals)
+	}
+
+	wantPolicies := map[string][2]int64{"default": {3, 1}, "previews": {4, 2}, "partner": {2, 1}}
+	for name, want := range wantPolicies {
+		stats := report.Policies[name]
+		if stats == nil || stats.ScannedObjects != want[0] || stats.ExpiredObjects != want[1] {
+			t.Errorf("policy %s = %+v, want scanned %d expired %d", name, stats, want[0], want[1])
+		}
+	}
+	if got := len(store.keys()); got != 9 {
+		t.Errorf("objects after report = %d, want 9", got)
+	}
+}
+
+func TestGCRunArchivesAndAudits(t *testing.T) {
+	dir := t.TempDir()
+	audit, err := NewAuditLog(filepath.Join(dir, "audit.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := newMemStore(time.Now())
+	gc, store := newTestGC(t, archive, audit)
+
+	report, err := gc.Run(context.Background(), GCRequest{Mode: GCModeRun, Prefix: "partner/", MaxItems: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 前缀之外的对象不受影响，过期对象连同元信息归档后删除
+	if report.Totals.ScannedObjects != 2 || report.Totals.ArchivedObjects != 1 || report.Totals.DeletedObjects != 0 {
+		t.Errorf("totals = %+v", report.Totals)
+	}
+	if keys := store.keys(); len(keys) != 8 {
+		t.Errorf("objects after run = %v", keys)
+	}
+	archived, err := archive.Stat(context.Background(), "partner/a.png")
+	if err != nil {
+		t.Fatalf("archived object: %v", err)
+	}
+	if archived.Metadata[MetaTenant] != "partner" || archived.ContentType != "image/png" {
+		t.Errorf("archived object = %+v", archived)
+	}
+
+	last := gc.LastRun()
+	if last == nil || last.RunID != report.RunID || last.Items != nil {
+		t.Errorf("LastRun() = %+v", last)
+	}
+
+	file, err := os.Open(filepath.Join(dir, "audit.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var records []AuditRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 2 {
+		t.Fatalf("audit records = %+v, want 2", records)
+	}
+	if records[0].Action != ActionArchive || records[0].Key != "partner/a.png" || records[0].Policy != "partner" {
+		t.Errorf("object record = %+v", records[0])
+	}
+	if records[1].Action != "run" || records[1].Summary == nil || records[1].Summary.ArchivedObjects != 1 {
+		t.Errorf("summary record = %+v", records[1])
+	}
+}
+
+func TestGCRunExclusive(t *testing.T) {
+	gc, _ := newTestGC(t, nil, nil)
+
+	gc.running.Lock()
+	if _, err := gc.Run(context.Background(), GCRequest{Mode: GCModeRun}); err != ErrGCRunning {
+		t.Fatalf("Run() while running = %v, want ErrGCRunning", err)
+	}
+	// 试运行不受正在执行的回收影响
+	if _, err := gc.Run(context.Background(), GCRequest{Mode: GCModeDryRun}); err != nil {
+		t.Fatalf("dry run while running: %v", err)
+	}
+	gc.running.Unlock()
+}
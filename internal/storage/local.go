@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// metaSuffix 本地存储中元信息文件的后缀
+const metaSuffix = ".meta.json"
+
+// localMeta 本地存储的元信息文件内容
+type localMeta struct {
+	ContentType string            `json:"content_type"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// LocalStore 本地文件系统存储，对象键即相对路径，元信息保存在同名的.meta.json文件中
+type LocalStore struct {
+	root      string
+	publicURL string
+}
+
+// NewLocalStore 创建本地文件系统存储
+// publicURL为对外提供这些文件的地址前缀（例如反向代理），为空时对象没有稳定URL
+func NewLocalStore(root, publicURL string) (*LocalStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &LocalStore{root: root, publicURL: publicURL}, nil
+}
+
+// Name 实现BlobStore
+func (s *LocalStore) Name() string {
+	return "local"
+}
+
+// path 对象键对应的文件路径
+func (s *LocalStore) path(key string) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put 实现BlobStore，先写入临时文件再重命名，避免读到写了一半的对象
+func (s *LocalStore) Put(ctx context.Context, key string, data []byte, contentType string, metadata map[string]string) error {
+	filename, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	meta, err := json.Marshal(localMeta{ContentType: contentType, Metadata: metadata})
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filename+metaSuffix, meta); err != nil {
+		return err
+	}
+	return writeFileAtomic(filename, data)
+}
+
+// Open 实现BlobStore
+func (s *LocalStore) Open(ctx context.Context, key string) (io.ReadSeekCloser, *ObjectInfo, error) {
+	info, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	filename, _ := s.path(key)
+	file, err := os.Open(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+	return file, info, nil
+}
+
+// Stat 实现BlobStore
+func (s *LocalStore) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	filename, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if stat.IsDir() {
+		return nil, ErrNotFound
+	}
+
+	var meta localMeta
+	if data, err := os.ReadFile(filename + metaSuffix); err == nil {
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return nil, fmt.Errorf("invalid metadata for %s: %w", key, err)
+		}
+	}
+	info := &ObjectInfo{
+		Key:         key,
+		Size:        stat.Size(),
+		ContentType: meta.ContentType,
+		ModTime:     stat.ModTime(),
+		Metadata:    meta.Metadata,
+	}
+	if info.ContentType == "" {
+		info.ContentType = ContentTypeByKey(key)
+	}
+	return info, nil
+}
+
+// Delete 实现BlobStore
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	filename, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	for _, name := range []string{filename, filename + metaSuffix} {
+		if err := os.Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// URL 实现BlobStore
+func (s *LocalStore) URL(key string) string {
+	return joinURL(s.publicURL, key)
+}
+
+// writeFileAtomic 通过临时文件和重命名写入文件
+func writeFileAtomic(filename string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(filename), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// List 实现BlobStore，跳过元信息文件和写入中的临时文件
+func (s *LocalStore) List(ctx context.Context, prefix string, fn func(*ObjectInfo) error) error {
+	err := filepath.WalkDir(s.root, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasSuffix(name, metaSuffix) || strings.HasPrefix(entry.Name(), ".tmp-") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.root, name)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+
+		info, err := s.Stat(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			// 遍历期间被删除
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return fn(info)
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,146 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif"  // 注册GIF解码器，用于读取图片尺寸
+	_ "image/jpeg" // 注册JPEG解码器
+	_ "image/png"  // 注册PNG解码器
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"sia/internal/metrics"
+)
+
+// Image 已持久化的图片
+type Image struct {
+	Key         string
+	URL         string
+	SHA256      string
+	Size        int64
+	Width       int
+	Height      int
+	ContentType string
+}
+
+// Persister 下载上游返回的临时图片并保存到对象存储
+type Persister struct {
+	store   BlobStore
+	client  *http.Client
+	maxSize int64
+	now     func() time.Time
+}
+
+// NewPersister 创建图片持久化器，maxSize为单张图片的最大字节数
+func NewPersister(store BlobStore, maxSize int64, timeout time.Duration) *Persister {
+	return &Persister{
+		store:   store,
+		client:  &http.Client{Timeout: timeout},
+		maxSize: maxSize,
+		now:     time.Now,
+	}
+}
+
+// Store 返回底层的对象存储
+func (p *Persister) Store() BlobStore {
+	return p.store
+}
+
+// SaveURL 下载图片并保存，对象键为 prefix/yyyy/mm/dd/<sha256>.<ext>
+func (p *Persister) SaveURL(ctx context.Context, prefix, imageURL string, metadata map[string]string) (*Image, error) {
+	data, contentType, err := p.download(ctx, imageURL)
+	if err != nil {
+		p.record("download_error", 0)
+		return nil, err
+	}
+	return p.save(ctx, prefix, data, contentType, metadata)
+}
+
+// SaveBytes 保存已有的图片内容（例如b64_json格式的返回）
+func (p *Persister) SaveBytes(ctx context.Context, prefix string, data []byte, metadata map[string]string) (*Image, error) {
+	if int64(len(data)) > p.maxSize {
+		p.record("too_large", 0)
+		return nil, fmt.Errorf("image exceeds maximum size of %d bytes", p.maxSize)
+	}
+	return p.save(ctx, prefix, data, "", metadata)
+}
+
+// save 计算校验和、尺寸与类型后写入存储
+func (p *Persister) save(ctx context.Context, prefix string, data []byte, contentType string, metadata map[string]string) (*Image, error) {
+	sum := sha256.Sum256(data)
+	img := &Image{
+		SHA256:      hex.EncodeToString(sum[:]),
+		Size:        int64(len(data)),
+		ContentType: sniffContentType(data, contentType),
+	}
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		img.Width, img.Height = cfg.Width, cfg.Height
+	}
+
+	img.Key = fmt.Sprintf("%s/%s/%s%s", prefix, p.now().UTC().Format("2006/01/02"), img.SHA256, Extension(img.ContentType))
+	if err := p.store.Put(ctx, img.Key, data, img.ContentType, metadata); err != nil {
+		p.record("store_error", 0)
+		return nil, fmt.Errorf("failed to store image: %w", err)
+	}
+	img.URL = p.store.URL(img.Key)
+
+	p.record("ok", img.Size)
+	return img, nil
+}
+
+// download 下载图片，超过最大大小时返回错误
+func (p *Persister) download(ctx context.Context, imageURL string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageURL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid image URL: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to download image: status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > p.maxSize {
+		return nil, "", fmt.Errorf("image exceeds maximum size of %d bytes", p.maxSize)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, p.maxSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download image: %w", err)
+	}
+	if int64(len(data)) > p.maxSize {
+		return nil, "", fmt.Errorf("image exceeds maximum size of %d bytes", p.maxSize)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// record 记录持久化结果指标
+func (p *Persister) record(result string, size int64) {
+	metrics.ImagesPersisted.WithLabelValues(p.store.Name(), result).Inc()
+	if size > 0 {
+		metrics.PersistedBytes.WithLabelValues(p.store.Name()).Add(float64(size))
+	}
+}
+
+// sniffContentType 根据内容识别图片类型，无法识别时使用响应头中的类型
+func sniffContentType(data []byte, declared string) string {
+	detected := http.DetectContentType(data)
+	if strings.HasPrefix(detected, "image/") {
+		return detected
+	}
+	mediaType, _, _ := strings.Cut(declared, ";")
+	if mediaType = strings.TrimSpace(mediaType); strings.HasPrefix(mediaType, "image/") {
+		return mediaType
+	}
+	return detected
+}
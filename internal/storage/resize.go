@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+)
+
+// resizeJPEGQuality 缩放后JPEG的编码质量
+const resizeJPEGQuality = 85
+
+// ErrResizeUnsupported 图片格式不支持缩放（例如WebP）
+var ErrResizeUnsupported = errors.New("image format does not support resizing")
+
+// Resize 将图片按宽度等比缩小，返回编码后的内容和内容类型
+// 目标宽度不小于原图时返回原图；JPEG保持JPEG，其余格式输出PNG
+func Resize(data []byte, width int) ([]byte, string, error) {
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", ErrResizeUnsupported
+	}
+
+	bounds := src.Bounds()
+	if width <= 0 || width >= bounds.Dx() {
+		return data, "image/" + format, nil
+	}
+	height := bounds.Dy() * width / bounds.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	dst := boxResize(src, width, height)
+
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: resizeJPEGQuality})
+	} else {
+		format = "png"
+		err = png.Encode(&buf, dst)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode resized image: %w", err)
+	}
+	return buf.Bytes(), "image/" + format, nil
+}
+
+// boxResize 使用区域平均（box filter）缩小图片，每个目标像素取对应源区域的平均值
+func boxResize(src image.Image, width, height int) *image.RGBA {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*srcH/height
+		y1 := bounds.Min.Y + (y+1)*srcH/height
+		if y1 == y0 {
+			y1++
+		}
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*srcW/width
+			x1 := bounds.Min.X + (x+1)*srcW/width
+			if x1 == x0 {
+				x1++
+			}
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r += uint64(pr)
+					g += uint64(pg)
+					b += uint64(pb)
+					a += uint64(pa)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,417 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// s3MetaPrefix 用户元信息的请求头前缀
+	s3MetaPrefix = "X-Amz-Meta-"
+	// s3Service SigV4签名中的服务名
+	s3Service = "s3"
+	// maxS3ErrorBody 读取错误响应的最大长度
+	maxS3ErrorBody = 4096
+)
+
+// S3Config S3兼容存储配置
+type S3Config struct {
+	Endpoint  string // 例如 https://s3.us-east-1.amazonaws.com 或 http://localhost:9000（MinIO）
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool   // 使用 endpoint/bucket/key 形式的地址（MinIO需要）
+	PublicURL string // 稳定URL的前缀，为空时使用对象地址
+	Timeout   time.Duration
+}
+
+// S3Store S3兼容的对象存储（AWS S3、MinIO、TOS等），使用SigV4签名
+type S3Store struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3Store 创建S3兼容存储
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 60 * time.Second
+	}
+
+	return &S3Store{
+		config:   cfg,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		now:      time.Now,
+	}, nil
+}
+
+// Name 实现BlobStore
+func (s *S3Store) Name() string {
+	return "s3"
+}
+
+// Put 实现BlobStore
+func (s *S3Store) Put(ctx context.Context, key string, data []byte, contentType string, metadata map[string]string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	for name, value := range metadata {
+		header.Set(s3MetaPrefix+name, value)
+	}
+
+	resp, err := s.do(ctx, http.MethodPut, key, nil, header, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s3Error(resp)
+	}
+	return nil
+}
+
+// Open 实现BlobStore，对象内容读入内存以支持Seek（图片通常只有几MB）
+func (s *S3Store) Open(ctx context.Context, key string) (io.ReadSeekCloser, *ObjectInfo, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := s.do(ctx, http.MethodGet, key, nil, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, s3Error(resp)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read object %s: %w", key, err)
+	}
+
+	info := s3ObjectInfo(key, resp.Header)
+	info.Size = int64(len(data))
+	return nopCloser{bytes.NewReader(data)}, info, nil
+}
+
+// Stat 实现BlobStore
+func (s *S3Store) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(ctx, http.MethodHead, key, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, s3Error(resp)
+	}
+	return s3ObjectInfo(key, resp.Header), nil
+}
+
+// Delete 实现BlobStore
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s3Error(resp)
+	}
+	return nil
+}
+
+// s3ListResult ListObjectsV2的响应
+type s3ListResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+}
+
+// List 实现BlobStore，使用ListObjectsV2分页遍历（不返回元信息）
+func (s *S3Store) List(ctx context.Context, prefix string, fn func(*ObjectInfo) error) error {
+	token := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := s.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode != http.StatusOK {
+			err := s3Error(resp)
+			resp.Body.Close()
+			return err
+		}
+
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("invalid S3 list response: %w", err)
+		}
+
+		for _, object := range result.Contents {
+			info := &ObjectInfo{
+				Key:         object.Key,
+				Size:        object.Size,
+				ContentType: ContentTypeByKey(object.Key),
+				ModTime:     object.LastModified,
+			}
+			if err := fn(info); err != nil {
+				return err
+			}
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// URL 实现BlobStore
+func (s *S3Store) URL(key string) string {
+	if s.config.PublicURL != "" {
+		return joinURL(s.config.PublicURL, key)
+	}
+	return s.objectURL(key, nil).String()
+}
+
+// objectURL 对象的请求地址
+func (s *S3Store) objectURL(key string, query url.Values) *url.URL {
+	u := *s.endpoint
+	basePath := strings.TrimRight(u.Path, "/")
+	if s.config.PathStyle {
+		u.Path = basePath + "/" + s.config.Bucket + "/" + key
+		u.RawPath = basePath + "/" + uriEncode(s.config.Bucket, false) + "/" + uriEncode(key, true)
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+		u.Path = basePath + "/" + key
+		u.RawPath = basePath + "/" + uriEncode(key, true)
+	}
+	u.RawQuery = canonicalQuery(query)
+	return &u
+}
+
+// do 发送签名后的请求
+func (s *S3Store) do(ctx context.Context, method, key string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	u := s.objectURL(key, query)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		req.Body = http.NoBody
+	}
+	req.ContentLength = int64(len(body))
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	payloadHash := sha256.Sum256(body)
+	s.sign(req, hex.EncodeToString(payloadHash[:]))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 %s %s failed: %w", method, key, err)
+	}
+	return resp, nil
+}
+
+// sign 按AWS Signature Version 4为请求签名
+func (s *S3Store) sign(req *http.Request, payloadHash string) {
+	now := s.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// 参与签名的请求头：host、content-type及所有x-amz-*
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/" + s3Service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretKey), date)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKey, scope, signedHeaders, signature))
+}
+
+// hmacSHA256 计算HMAC-SHA256
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// uriEncode 按SigV4规则进行URI编码，只保留非保留字符（keepSlash时保留/）
+func uriEncode(value string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && keepSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// canonicalQuery 按键排序并编码查询参数
+func canonicalQuery(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, uriEncode(key, false)+"="+uriEncode(value, false))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// s3ObjectInfo 从响应头解析对象元信息
+func s3ObjectInfo(key string, header http.Header) *ObjectInfo {
+	info := &ObjectInfo{
+		Key:         key,
+		ContentType: header.Get("Content-Type"),
+	}
+	info.Size, _ = strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	info.ModTime, _ = http.ParseTime(header.Get("Last-Modified"))
+	for name, values := range header {
+		if strings.HasPrefix(name, s3MetaPrefix) && len(values) > 0 {
+			if info.Metadata == nil {
+				info.Metadata = make(map[string]string)
+			}
+			info.Metadata[strings.ToLower(strings.TrimPrefix(name, s3MetaPrefix))] = values[0]
+		}
+	}
+	if info.ContentType == "" {
+		info.ContentType = ContentTypeByKey(key)
+	}
+	return info
+}
+
+// s3Error 将错误响应转换为错误，404返回ErrNotFound
+func s3Error(resp *http.Response) error {
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxS3ErrorBody))
+	var result struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	if err := xml.Unmarshal(body, &result); err == nil && result.Code != "" {
+		return fmt.Errorf("S3 error %d: %s: %s", resp.StatusCode, result.Code, result.Message)
+	}
+	return fmt.Errorf("S3 error %d", resp.StatusCode)
+}
+
+// nopCloser 为bytes.Reader增加Close方法
+type nopCloser struct {
+	*bytes.Reader
+}
+
+// Close 实现io.Closer
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// signedQuery 签名对象键并返回地址的查询参数
+func signedQuery(t *testing.T, signer *URLSigner, key string) url.Values {
+	t.Helper()
+	signed, _ := signer.Sign(key)
+	parsed, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Query()
+}
+
+// newTestSigner 创建使用固定时间的签名器
+func newTestSigner(now time.Time, keys ...SigningKey) *URLSigner {
+	signer := NewURLSigner(keys, time.Hour, "")
+	signer.now = func() time.Time { return now }
+	return signer
+}
+
+func TestURLSignerSign(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC)
+	signer := NewURLSigner([]SigningKey{{ID: "k1", Secret: []byte("secret")}}, time.Hour, "https://img.example.com/")
+	signer.now = func() time.Time { return now }
+
+	signed, expires := signer.Sign("2024/05/01/a b.png")
+	if !strings.HasPrefix(signed, "https://img.example.com/images/2024/05/01/a%20b.png?") {
+		t.Errorf("Sign() = %q", signed)
+	}
+	if want := now.Add(time.Hour).Truncate(time.Second); !expires.Equal(want) {
+		t.Errorf("expires = %v, want %v", expires, want)
+	}
+
+	relative, _ := newTestSigner(now, SigningKey{ID: "k1", Secret: []byte("secret")}).Sign("a.png")
+	if !strings.HasPrefix(relative, ImagePathPrefix+"a.png?") {
+		t.Errorf("Sign() without base URL = %q", relative)
+	}
+}
+
+func TestURLSignerVerify(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	key := SigningKey{ID: "k1", Secret: []byte("secret")}
+	signer := newTestSigner(now, key)
+	valid := signedQuery(t, signer, "a.png")
+
+	// with 复制查询参数并修改其中一项
+	with := func(name, value string) url.Values {
+		query := url.Values{}
+		for k, v := range valid {
+			query[k] = append([]string(nil), v...)
+		}
+		if value == "" {
+			query.Del(name)
+		} else {
+			query.Set(name, value)
+		}
+		return query
+	}
+
+	tests := []struct {
+		name    string
+		signer  *URLSigner
+		key     string
+		query   url.Values
+		wantErr error
+	}{
+		{name: "valid", signer: signer, key: "a.png", query: valid},
+		{name: "just before expiry", signer: newTestSigner(now.Add(time.Hour), key), key: "a.png", query: valid},
+		{name: "expired", signer: newTestSigner(now.Add(time.Hour+time.Second), key), key: "a.png", query: valid, wantErr: ErrSignatureExpired},
+		{name: "other object", signer: signer, key: "b.png", query: valid, wantErr: ErrSignatureInvalid},
+		{name: "extended expiry", signer: signer, key: "a.png", query: with("expires", "99999999999"), wantErr: ErrSignatureInvalid},
+		{name: "malformed expiry", signer: signer, key: "a.png", query: with("expires", "soon"), wantErr: ErrSignatureInvalid},
+		{name: "tampered signature", signer: signer, key: "a.png", query: with("sig", "AAAA"), wantErr: ErrSignatureInvalid},
+		{name: "missing signature", signer: signer, key: "a.png", query: with("sig", ""), wantErr: ErrSignatureInvalid},
+		{name: "unknown kid", signer: signer, key: "a.png", query: with("kid", "k9"), wantErr: ErrSignatureInvalid},
+		{name: "missing kid", signer: signer, key: "a.png", query: with("kid", ""), wantErr: ErrSignatureInvalid},
+		{name: "other secret", signer: newTestSigner(now, SigningKey{ID: "k1", Secret: []byte("other")}), key: "a.png", query: valid, wantErr: ErrSignatureInvalid},
+		{name: "width is not signed", signer: signer, key: "a.png", query: with("w", "256")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.signer.Verify(tt.key, tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestURLSignerKeyRotation(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	oldKey := SigningKey{ID: "old", Secret: []byte("old-secret")}
+	newKey := SigningKey{ID: "new", Secret: []byte("new-secret")}
+
+	oldURL := signedQuery(t, newTestSigner(now, oldKey), "a.png")
+
+	// 新密钥加到最前面：新地址使用新密钥签名，旧地址仍可验证
+	rotating := newTestSigner(now, newKey, oldKey)
+	newURL := signedQuery(t, rotating, "a.png")
+	if kid := newURL.Get("kid"); kid != "new" {
+		t.Fatalf("kid = %q, want new", kid)
+	}
+	if _, err := rotating.Verify("a.png", oldURL); err != nil {
+		t.Fatalf("old URL rejected during rotation: %v", err)
+	}
+	if _, err := rotating.Verify("a.png", newURL); err != nil {
+		t.Fatalf("new URL rejected during rotation: %v", err)
+	}
+
+	// 移除旧密钥后旧地址失效
+	rotated := newTestSigner(now, newKey)
+	if _, err := rotated.Verify("a.png", oldURL); !errors.Is(err, ErrSignatureInvalid) {
+		t.Fatalf("old URL after removing the old key: %v", err)
+	}
+	if _, err := rotated.Verify("a.png", newURL); err != nil {
+		t.Fatalf("new URL after removing the old key: %v", err)
+	}
+}
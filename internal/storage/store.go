@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrNotFound 对象不存在
+var ErrNotFound = errors.New("object not found")
+
+// ObjectInfo 对象元信息
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+	Metadata    map[string]string
+}
+
+// BlobStore 对象存储
+type BlobStore interface {
+	// Name 存储后端名称（用于日志和指标）
+	Name() string
+	// Put 写入对象，同名对象会被覆盖
+	Put(ctx context.Context, key string, data []byte, contentType string, metadata map[string]string) error
+	// Open 打开对象用于读取，调用方负责关闭
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, *ObjectInfo, error)
+	// Stat 获取对象元信息
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// List 遍历键以prefix开头的对象，fn返回错误时停止遍历
+	// 对象的Metadata可能为空（例如S3列表不返回元信息），需要时通过Stat获取
+	List(ctx context.Context, prefix string, fn func(*ObjectInfo) error) error
+	// URL 对象的稳定访问地址，没有可用地址时返回空字符串
+	URL(key string) string
+}
+
+// ValidateKey 检查对象键是否安全（相对路径，不含..等路径穿越）
+func ValidateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid object key %q", key)
+	}
+	if path.Clean(key) != key {
+		return fmt.Errorf("invalid object key %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "." || segment == ".." {
+			return fmt.Errorf("invalid object key %q", key)
+		}
+	}
+	return nil
+}
+
+// Extension 根据内容类型获取文件扩展名
+func Extension(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/webp":
+		return ".webp"
+	case "image/gif":
+		return ".gif"
+	}
+	if exts, err := mime.ExtensionsByType(contentType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ".bin"
+}
+
+// ContentTypeByKey 根据对象键的扩展名推断内容类型
+func ContentTypeByKey(key string) string {
+	if contentType := mime.TypeByExtension(path.Ext(key)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// joinURL 拼接URL前缀和对象键
+func joinURL(base, key string) string {
+	if base == "" {
+		return ""
+	}
+	return strings.TrimRight(base, "/") + "/" + key
+}
@@ -0,0 +1,31 @@
+package tracing
+
+import (
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataCarrier 基于gRPC元数据的TextMapCarrier
+type MetadataCarrier metadata.MD
+
+// Get 获取键对应的第一个值
+func (c MetadataCarrier) Get(key string) string {
+	values := metadata.MD(c).Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// Set 设置键值
+func (c MetadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+// Keys 返回所有键
+func (c MetadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
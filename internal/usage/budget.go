@@ -0,0 +1,124 @@
+package usage
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// BudgetLimits 费用上限，0表示不限制
+type BudgetLimits struct {
+	DailySpend   float64
+	MonthlySpend float64
+}
+
+// BudgetConfig 预算配置
+type BudgetConfig struct {
+	DefaultTier string
+	Tiers       map[string]BudgetLimits
+	Tenants     map[string]BudgetLimits // 租户级覆盖，优先于分级
+}
+
+// BudgetStatus 单个窗口的预算使用状态
+type BudgetStatus struct {
+	Window   string
+	Spent    float64
+	Limit    float64
+	ResetsAt time.Time
+}
+
+// BudgetError 超出预算错误
+type BudgetError struct {
+	BudgetStatus
+	Estimate float64
+}
+
+// Error 实现error接口
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("%s budget exceeded: spent %.4f + estimated %.4f > limit %.4f", e.Window, e.Spent, e.Estimate, e.Limit)
+}
+
+// Budget 基于用量台账的预算检查器
+type Budget struct {
+	ledger *Ledger
+	config BudgetConfig
+}
+
+// NewBudget 创建预算检查器
+func NewBudget(ledger *Ledger, config BudgetConfig) *Budget {
+	return &Budget{
+		ledger: ledger,
+		config: config,
+	}
+}
+
+// LimitsFor 获取租户适用的预算
+func (b *Budget) LimitsFor(tenant, tier string) BudgetLimits {
+	if limits, ok := b.config.Tenants[tenant]; ok {
+		return limits
+	}
+	if limits, ok := b.config.Tiers[tier]; ok {
+		return limits
+	}
+	return b.config.Tiers[b.config.DefaultTier]
+}
+
+// Status 获取租户在当前日/月窗口内的预算使用情况
+func (b *Budget) Status(tenant, tier string, now time.Time) []BudgetStatus {
+	limits := b.LimitsFor(tenant, tier)
+	dayStart, monthStart := windowStarts(now)
+
+	daily := b.ledger.Summarize(Filter{Tenant: tenant, Start: dayStart, End: now}).Total
+	monthly := b.ledger.Summarize(Filter{Tenant: tenant, Start: monthStart, End: now}).Total
+
+	return []BudgetStatus{
+		{Window: WindowDaily, Spent: daily.Cost, Limit: limits.DailySpend, ResetsAt: dayStart.AddDate(0, 0, 1)},
+		{Window: WindowMonthly, Spent: monthly.Cost, Limit: limits.MonthlySpend, ResetsAt: monthStart.AddDate(0, 1, 0)},
+	}
+}
+
+// Remaining 获取租户剩余可用预算（扣除进行中请求预留的费用）；不限制时返回+Inf，否则同时返回剩余最少的窗口
+func (b *Budget) Remaining(tenant, tier string, now time.Time) (float64, *BudgetStatus) {
+	return b.remaining(tenant, tier, b.ledger.Reserved(tenant).Cost, now)
+}
+
+// remaining 计算剩余预算，reserved为进行中请求预留的费用，计入返回窗口的Spent
+func (b *Budget) remaining(tenant, tier string, reserved float64, now time.Time) (float64, *BudgetStatus) {
+	remaining := math.Inf(1)
+	var tightest *BudgetStatus
+	for _, status := range b.Status(tenant, tier, now) {
+		if status.Limit <= 0 {
+			continue
+		}
+		status.Spent += reserved
+		if left := status.Limit - status.Spent; left < remaining {
+			remaining = left
+			s := status
+			tightest = &s
+		}
+	}
+	return remaining, tightest
+}
+
+// Check 检查租户是否还能承担estimate的费用
+func (b *Budget) Check(tenant, tier string, estimate float64, now time.Time) error {
+	remaining, tightest := b.Remaining(tenant, tier, now)
+	if tightest != nil && estimate > remaining {
+		return &BudgetError{BudgetStatus: *tightest, Estimate: estimate}
+	}
+	return nil
+}
+
+// Reserve 计算剩余预算并预留本次请求的费用，同一租户的并发请求不会因为都通过检查而超出预算
+// decide收到扣除进行中请求预留后的剩余预算，返回本次需要预留的费用；返回错误时不预留
+// 请求结束后必须调用返回的释放函数（成功时在记录用量之后）
+func (b *Budget) Reserve(tenant, tier string, now time.Time, decide func(remaining float64, tightest *BudgetStatus) (float64, error)) (func(), error) {
+	return b.ledger.Reserve(tenant, func(reserved Totals) (Totals, error) {
+		remaining, tightest := b.remaining(tenant, tier, reserved.Cost, now)
+		cost, err := decide(remaining, tightest)
+		if err != nil {
+			return Totals{}, err
+		}
+		return Totals{Requests: 1, Cost: cost}, nil
+	})
+}
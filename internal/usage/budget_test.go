@@ -0,0 +1,91 @@
+package usage
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// reserveCost 按decide返回的费用预留预算，超出剩余预算时返回BudgetError
+func reserveCost(budget *Budget, tenant string, cost float64, now time.Time) (func(), error) {
+	return budget.Reserve(tenant, "free", now, func(remaining float64, tightest *BudgetStatus) (float64, error) {
+		if tightest != nil && cost > remaining {
+			return 0, &BudgetError{BudgetStatus: *tightest, Estimate: cost}
+		}
+		return cost, nil
+	})
+}
+
+func TestBudgetRemaining(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 30, 0, 0, time.UTC)
+	ledger := newTestLedger(t)
+	budget := NewBudget(ledger, BudgetConfig{
+		DefaultTier: "free",
+		Tiers:       map[string]BudgetLimits{"free": {DailySpend: 10, MonthlySpend: 15}},
+		Tenants:     map[string]BudgetLimits{"vip": {}},
+	})
+
+	ledger.Record(Entry{Time: now.AddDate(0, 0, -1), Tenant: "a", Cost: 7})
+	ledger.Record(Entry{Time: now.Add(-time.Hour), Tenant: "a", Cost: 2})
+
+	// 月度窗口剩余6，日窗口剩余8，取剩余最少的窗口
+	remaining, tightest := budget.Remaining("a", "free", now)
+	if remaining != 6 || tightest == nil || tightest.Window != WindowMonthly {
+		t.Fatalf("Remaining() = %v, %+v, want 6 in the monthly window", remaining, tightest)
+	}
+
+	if err := budget.Check("a", "free", 6, now); err != nil {
+		t.Errorf("Check(6) = %v", err)
+	}
+	var budgetErr *BudgetError
+	if err := budget.Check("a", "free", 6.5, now); !errors.As(err, &budgetErr) || budgetErr.Spent != 9 || budgetErr.Estimate != 6.5 {
+		t.Errorf("Check(6.5) = %v, want BudgetError with spent 9", err)
+	}
+
+	if remaining, tightest := budget.Remaining("vip", "free", now); !math.IsInf(remaining, 1) || tightest != nil {
+		t.Errorf("Remaining() for unlimited tenant = %v, %+v", remaining, tightest)
+	}
+}
+
+func TestBudgetReserve(t *testing.T) {
+	now := time.Now()
+	ledger := newTestLedger(t)
+	budget := NewBudget(ledger, BudgetConfig{
+		DefaultTier: "free",
+		Tiers:       map[string]BudgetLimits{"free": {DailySpend: 1}},
+	})
+
+	first, err := reserveCost(budget, "a", 0.6, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 进行中请求预留的费用计入预算
+	if remaining, _ := budget.Remaining("a", "free", now); math.Abs(remaining-0.4) > 1e-9 {
+		t.Errorf("Remaining() with a reservation = %v, want 0.4", remaining)
+	}
+	_, err = reserveCost(budget, "a", 0.6, now)
+	var budgetErr *BudgetError
+	if !errors.As(err, &budgetErr) || budgetErr.Spent != 0.6 {
+		t.Fatalf("Reserve() = %v, want BudgetError counting the reservation as spent", err)
+	}
+
+	// 成功的请求按实际费用结算
+	ledger.Record(Entry{Time: now, Tenant: "a", Cost: 0.3})
+	first()
+	first()
+	if remaining, _ := budget.Remaining("a", "free", now); math.Abs(remaining-0.7) > 1e-9 {
+		t.Errorf("Remaining() after settling = %v, want 0.7", remaining)
+	}
+
+	// 失败的请求释放预留即退还预算
+	second, err := reserveCost(budget, "a", 0.7, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second()
+	if _, err := reserveCost(budget, "a", 0.7, now); err != nil {
+		t.Errorf("Reserve() after refund = %v", err)
+	}
+}
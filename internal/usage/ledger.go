@@ -0,0 +1,272 @@
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// retention 小时汇总的保留时间（覆盖月度配额与跨月查询）
+const retention = 400 * 24 * time.Hour
+
+// Entry 单次请求的用量记录
+type Entry struct {
+	Time            time.Time `json:"time"`
+	RequestID       string    `json:"request_id"`
+	Tenant          string    `json:"tenant"`
+	ClientID        string    `json:"client_id,omitempty"`
+	Model           string    `json:"model"`
+	Method          string    `json:"method"`
+	GeneratedImages int       `json:"generated_images"`
+	OutputTokens    int       `json:"output_tokens"`
+	TotalTokens     int       `json:"total_tokens"`
+	Cost            float64   `json:"cost,omitempty"`
+}
+
+// Totals 用量合计
+type Totals struct {
+	Requests        int64   `json:"requests"`
+	GeneratedImages int64   `json:"generated_images"`
+	OutputTokens    int64   `json:"output_tokens"`
+	TotalTokens     int64   `json:"total_tokens"`
+	Cost            float64 `json:"cost"`
+}
+
+// add 累加一条记录
+func (t *Totals) add(e Entry) {
+	t.Requests++
+	t.GeneratedImages += int64(e.GeneratedImages)
+	t.OutputTokens += int64(e.OutputTokens)
+	t.TotalTokens += int64(e.TotalTokens)
+	t.Cost += e.Cost
+}
+
+// merge 合并另一份合计
+func (t *Totals) merge(other Totals) {
+	t.Requests += other.Requests
+	t.GeneratedImages += other.GeneratedImages
+	t.OutputTokens += other.OutputTokens
+	t.TotalTokens += other.TotalTokens
+	t.Cost += other.Cost
+}
+
+// Filter 查询条件
+type Filter struct {
+	Tenant string // 为空表示全部租户
+	Model  string // 为空表示全部模型
+	Start  time.Time
+	End    time.Time
+}
+
+// Summary 查询结果
+type Summary struct {
+	Start   time.Time
+	End     time.Time
+	Total   Totals
+	ByModel map[string]Totals
+}
+
+// bucketKey 租户内小时汇总的索引
+type bucketKey struct {
+	hour  int64 // Unix小时
+	model string
+}
+
+// Ledger 用量台账：内存中按小时汇总，可选追加写入JSONL文件以便重启后恢复
+type Ledger struct {
+	mutex     sync.RWMutex
+	buckets   map[string]map[bucketKey]*Totals // 租户 -> 小时汇总
+	file      *os.File
+	writer    *bufio.Writer
+	lastPrune time.Time
+
+	reserveMutex sync.Mutex
+	reserved     map[string]Totals // 租户 -> 进行中请求预留的用量
+}
+
+// NewLedger 创建用量台账，path为空时只保存在内存中
+func NewLedger(path string) (*Ledger, error) {
+	l := &Ledger{
+		buckets:  make(map[string]map[bucketKey]*Totals),
+		reserved: make(map[string]Totals),
+	}
+
+	if path == "" {
+		return l, nil
+	}
+
+	if err := l.replay(path); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+	l.file = file
+	l.writer = bufio.NewWriter(file)
+
+	return l, nil
+}
+
+// replay 从JSONL文件恢复汇总数据
+func (l *Ledger) replay(path string) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open usage ledger: %w", err)
+	}
+	defer file.Close()
+
+	cutoff := time.Now().Add(-retention)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue // 跳过损坏的行（例如进程崩溃时写了一半）
+		}
+		if entry.Time.Before(cutoff) {
+			continue
+		}
+		l.addLocked(entry)
+	}
+
+	return scanner.Err()
+}
+
+// Record 记录一次请求的用量
+func (l *Ledger) Record(entry Entry) error {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.pruneLocked(time.Now())
+	l.addLocked(entry)
+
+	if l.writer == nil {
+		return nil
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode usage entry: %w", err)
+	}
+	l.writer.Write(line)
+	l.writer.WriteByte('\n')
+	if err := l.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write usage ledger: %w", err)
+	}
+
+	return nil
+}
+
+// addLocked 累加到小时汇总，调用方需持有写锁
+func (l *Ledger) addLocked(entry Entry) {
+	tenantBuckets, ok := l.buckets[entry.Tenant]
+	if !ok {
+		tenantBuckets = make(map[bucketKey]*Totals)
+		l.buckets[entry.Tenant] = tenantBuckets
+	}
+
+	key := bucketKey{hour: entry.Time.Unix() / 3600, model: entry.Model}
+	totals, ok := tenantBuckets[key]
+	if !ok {
+		totals = &Totals{}
+		tenantBuckets[key] = totals
+	}
+	totals.add(entry)
+}
+
+// Summarize 按条件汇总用量，时间范围按小时对齐
+func (l *Ledger) Summarize(filter Filter) Summary {
+	start := filter.Start.Truncate(time.Hour)
+	end := filter.End
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	summary := Summary{
+		Start:   start,
+		End:     end,
+		ByModel: make(map[string]Totals),
+	}
+
+	startHour := start.Unix() / 3600
+	endHour := (end.Unix() + 3599) / 3600 // 向上取整，包含结束时间所在的小时
+
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	for tenant, tenantBuckets := range l.buckets {
+		if filter.Tenant != "" && tenant != filter.Tenant {
+			continue
+		}
+		for key, totals := range tenantBuckets {
+			if key.hour < startHour || key.hour >= endHour {
+				continue
+			}
+			if filter.Model != "" && key.model != filter.Model {
+				continue
+			}
+			summary.Total.merge(*totals)
+			byModel := summary.ByModel[key.model]
+			byModel.merge(*totals)
+			summary.ByModel[key.model] = byModel
+		}
+	}
+
+	return summary
+}
+
+// Models 返回按名称排序的模型列表
+func (s Summary) Models() []string {
+	models := make([]string, 0, len(s.ByModel))
+	for model := range s.ByModel {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	return models
+}
+
+// pruneLocked 清理超过保留期的汇总数据，每小时最多执行一次，调用方需持有写锁
+func (l *Ledger) pruneLocked(now time.Time) {
+	if now.Sub(l.lastPrune) < time.Hour {
+		return
+	}
+	l.lastPrune = now
+
+	cutoff := now.Add(-retention).Unix() / 3600
+	for tenant, tenantBuckets := range l.buckets {
+		for key := range tenantBuckets {
+			if key.hour < cutoff {
+				delete(tenantBuckets, key)
+			}
+		}
+		if len(tenantBuckets) == 0 {
+			delete(l.buckets, tenant)
+		}
+	}
+}
+
+// Close 关闭台账文件
+func (l *Ledger) Close() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+	if err := l.writer.Flush(); err != nil {
+		return err
+	}
+	return l.file.Close()
+}
@@ -0,0 +1,56 @@
+package usage
+
+import "strings"
+
+// Price 单个模型的价格
+type Price struct {
+	PerImage float64            // 单图价格
+	Sizes    map[string]float64 // 按尺寸覆盖的单图价格
+}
+
+// PriceTable 模型价格表
+type PriceTable struct {
+	Currency string
+	models   map[string]Price
+}
+
+// NewPriceTable 创建价格表，尺寸不区分大小写
+func NewPriceTable(currency string, models map[string]Price) *PriceTable {
+	normalized := make(map[string]Price, len(models))
+	for name, price := range models {
+		sizes := make(map[string]float64, len(price.Sizes))
+		for size, perImage := range price.Sizes {
+			sizes[strings.ToUpper(size)] = perImage
+		}
+		normalized[name] = Price{PerImage: price.PerImage, Sizes: sizes}
+	}
+
+	return &PriceTable{
+		Currency: currency,
+		models:   normalized,
+	}
+}
+
+// UnitPrice 获取模型在指定尺寸下的单图价格，模型未定价时返回false
+func (t *PriceTable) UnitPrice(model, size string) (float64, bool) {
+	price, ok := t.models[model]
+	if !ok {
+		return 0, false
+	}
+	if perImage, ok := price.Sizes[strings.ToUpper(size)]; ok {
+		return perImage, true
+	}
+	return price.PerImage, true
+}
+
+// Price 获取模型的价格，模型未定价时返回false
+func (t *PriceTable) Price(model string) (Price, bool) {
+	price, ok := t.models[model]
+	return price, ok
+}
+
+// Cost 计算生成images张图片的费用，未定价的模型按0计算
+func (t *PriceTable) Cost(model, size string, images int) float64 {
+	unitPrice, _ := t.UnitPrice(model, size)
+	return unitPrice * float64(images)
+}
@@ -0,0 +1,138 @@
+package usage
+
+import (
+	"fmt"
+	"time"
+)
+
+// 配额窗口
+const (
+	WindowDaily   = "daily"
+	WindowMonthly = "monthly"
+)
+
+// 配额资源
+const (
+	ResourceImages = "images"
+	ResourceTokens = "tokens"
+)
+
+// Limits 配额上限，0表示不限制
+type Limits struct {
+	DailyImages   int64
+	MonthlyImages int64
+	DailyTokens   int64
+	MonthlyTokens int64
+}
+
+// QuotaConfig 配额配置
+type QuotaConfig struct {
+	DefaultTier string
+	Tiers       map[string]Limits
+	Tenants     map[string]Limits // 租户级覆盖，优先于分级
+}
+
+// Status 单项配额的使用状态
+type Status struct {
+	Window   string
+	Resource string
+	Used     int64
+	Limit    int64
+	ResetsAt time.Time
+}
+
+// QuotaError 超出配额错误
+type QuotaError struct {
+	Status
+}
+
+// Error 实现error接口
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("%s %s quota exceeded: used %d of %d", e.Window, e.Resource, e.Used, e.Limit)
+}
+
+// Quota 基于用量台账的配额检查器
+type Quota struct {
+	ledger *Ledger
+	config QuotaConfig
+}
+
+// NewQuota 创建配额检查器
+func NewQuota(ledger *Ledger, config QuotaConfig) *Quota {
+	return &Quota{
+		ledger: ledger,
+		config: config,
+	}
+}
+
+// LimitsFor 获取租户适用的配额
+func (q *Quota) LimitsFor(tenant, tier string) Limits {
+	if limits, ok := q.config.Tenants[tenant]; ok {
+		return limits
+	}
+	if limits, ok := q.config.Tiers[tier]; ok {
+		return limits
+	}
+	return q.config.Tiers[q.config.DefaultTier]
+}
+
+// Check 检查租户是否还能发起请求，images为本次请求最多可能生成的图片数
+func (q *Quota) Check(tenant, tier string, images int, now time.Time) error {
+	return q.check(tenant, tier, images, q.ledger.Reserved(tenant), now)
+}
+
+// Reserve 检查配额并为本次请求预留images张图片，同一租户的并发请求不会因为都通过检查而超出配额
+// 请求结束后必须调用返回的释放函数（成功时在记录用量之后）
+func (q *Quota) Reserve(tenant, tier string, images int, now time.Time) (func(), error) {
+	return q.ledger.Reserve(tenant, func(reserved Totals) (Totals, error) {
+		if err := q.check(tenant, tier, images, reserved, now); err != nil {
+			return Totals{}, err
+		}
+		return Totals{Requests: 1, GeneratedImages: int64(images)}, nil
+	})
+}
+
+// check 检查已记录的用量加上进行中请求的预留是否还能容纳本次请求
+func (q *Quota) check(tenant, tier string, images int, reserved Totals, now time.Time) error {
+	for _, status := range q.Status(tenant, tier, now) {
+		if status.Limit <= 0 {
+			continue
+		}
+		exceeded := status.Used >= status.Limit
+		if status.Resource == ResourceImages {
+			status.Used += reserved.GeneratedImages
+			exceeded = status.Used+int64(images) > status.Limit
+		}
+		if exceeded {
+			return &QuotaError{Status: status}
+		}
+	}
+	return nil
+}
+
+// Status 获取租户在当前日/月窗口内的配额使用情况
+func (q *Quota) Status(tenant, tier string, now time.Time) []Status {
+	limits := q.LimitsFor(tenant, tier)
+	dayStart, monthStart := windowStarts(now)
+
+	daily := q.ledger.Summarize(Filter{Tenant: tenant, Start: dayStart, End: now}).Total
+	monthly := q.ledger.Summarize(Filter{Tenant: tenant, Start: monthStart, End: now}).Total
+
+	dayReset := dayStart.AddDate(0, 0, 1)
+	monthReset := monthStart.AddDate(0, 1, 0)
+
+	return []Status{
+		{Window: WindowDaily, Resource: ResourceImages, Used: daily.GeneratedImages, Limit: limits.DailyImages, ResetsAt: dayReset},
+		{Window: WindowDaily, Resource: ResourceTokens, Used: daily.TotalTokens, Limit: limits.DailyTokens, ResetsAt: dayReset},
+		{Window: WindowMonthly, Resource: ResourceImages, Used: monthly.GeneratedImages, Limit: limits.MonthlyImages, ResetsAt: monthReset},
+		{Window: WindowMonthly, Resource: ResourceTokens, Used: monthly.TotalTokens, Limit: limits.MonthlyTokens, ResetsAt: monthReset},
+	}
+}
+
+// windowStarts 获取UTC自然日与自然月窗口的起始时间
+func windowStarts(now time.Time) (dayStart, monthStart time.Time) {
+	now = now.UTC()
+	dayStart = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return dayStart, monthStart
+}
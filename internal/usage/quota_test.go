@@ -0,0 +1,136 @@
+package usage
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestLedger(t *testing.T) *Ledger {
+	t.Helper()
+	ledger, err := NewLedger("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ledger
+}
+
+// quotaError 断言错误为指定窗口和资源的QuotaError
+func quotaError(t *testing.T, err error, window, resource string) *QuotaError {
+	t.Helper()
+	var quotaErr *QuotaError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("error = %v, want *QuotaError", err)
+	}
+	if quotaErr.Window != window || quotaErr.Resource != resource {
+		t.Fatalf("error = %s/%s, want %s/%s", quotaErr.Window, quotaErr.Resource, window, resource)
+	}
+	return quotaErr
+}
+
+func TestQuotaCheck(t *testing.T) {
+	now := time.Date(2026, 3, 15, 12, 30, 0, 0, time.UTC)
+	ledger := newTestLedger(t)
+	quota := NewQuota(ledger, QuotaConfig{
+		DefaultTier: "free",
+		Tiers: map[string]Limits{
+			"free": {DailyImages: 10, MonthlyImages: 12, DailyTokens: 1000},
+		},
+		Tenants: map[string]Limits{"vip": {}},
+	})
+
+	// 昨天的用量只计入月度窗口，上个月的用量不计入
+	ledger.Record(Entry{Time: now.AddDate(0, -1, 0), Tenant: "a", GeneratedImages: 100})
+	ledger.Record(Entry{Time: now.AddDate(0, 0, -1), Tenant: "a", GeneratedImages: 4})
+	ledger.Record(Entry{Time: now.Add(-time.Hour), Tenant: "a", GeneratedImages: 6, TotalTokens: 500})
+
+	if err := quota.Check("a", "free", 2, now); err != nil {
+		t.Fatalf("Check() = %v, want nil with 2 of 12 monthly images left", err)
+	}
+	quotaErr := quotaError(t, quota.Check("a", "free", 3, now), WindowMonthly, ResourceImages)
+	if quotaErr.Used != 10 || quotaErr.Limit != 12 {
+		t.Errorf("used/limit = %d/%d, want 10/12", quotaErr.Used, quotaErr.Limit)
+	}
+	if want := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC); !quotaErr.ResetsAt.Equal(want) {
+		t.Errorf("ResetsAt = %v, want %v", quotaErr.ResetsAt, want)
+	}
+
+	// token配额用完后拒绝，不论本次请求的图片数
+	ledger.Record(Entry{Time: now, Tenant: "a", TotalTokens: 500})
+	quotaError(t, quota.Check("a", "free", 0, now), WindowDaily, ResourceTokens)
+
+	// 租户级覆盖优先于分级，全为0表示不限制
+	ledger.Record(Entry{Time: now, Tenant: "vip", GeneratedImages: 1000})
+	if err := quota.Check("vip", "free", 1, now); err != nil {
+		t.Errorf("Check() for unlimited tenant = %v", err)
+	}
+}
+
+func TestQuotaReserve(t *testing.T) {
+	now := time.Now()
+	ledger := newTestLedger(t)
+	quota := NewQuota(ledger, QuotaConfig{
+		DefaultTier: "free",
+		Tiers:       map[string]Limits{"free": {DailyImages: 4}},
+	})
+
+	first, err := quota.Reserve("a", "free", 3, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 进行中请求的预留计入配额
+	_, err = quota.Reserve("a", "free", 2, now)
+	quotaError(t, err, WindowDaily, ResourceImages)
+	quotaError(t, quota.Check("a", "free", 2, now), WindowDaily, ResourceImages)
+	if _, err := quota.Reserve("b", "free", 4, now); err != nil {
+		t.Errorf("Reserve() for another tenant = %v", err)
+	}
+
+	// 失败的请求释放预留即退还配额，释放函数只生效一次
+	first()
+	first()
+	if got := ledger.Reserved("a"); got != (Totals{}) {
+		t.Errorf("Reserved() after release = %+v, want zero", got)
+	}
+	second, err := quota.Reserve("a", "free", 4, now)
+	if err != nil {
+		t.Fatalf("Reserve() after refund = %v", err)
+	}
+
+	// 成功的请求按实际生成的图片数结算
+	ledger.Record(Entry{Time: now, Tenant: "a", GeneratedImages: 1})
+	second()
+	if err := quota.Check("a", "free", 3, now); err != nil {
+		t.Errorf("Check() after settling 1 of 4 images = %v", err)
+	}
+}
+
+func TestQuotaReserveConcurrent(t *testing.T) {
+	now := time.Now()
+	quota := NewQuota(newTestLedger(t), QuotaConfig{
+		DefaultTier: "free",
+		Tiers:       map[string]Limits{"free": {DailyImages: 5}},
+	})
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	allowed := 0
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := quota.Reserve("a", "free", 1, now); err == nil {
+				mutex.Lock()
+				allowed++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != 5 {
+		t.Errorf("allowed = %d concurrent requests, want 5", allowed)
+	}
+}
@@ -0,0 +1,50 @@
+package usage
+
+import "sync"
+
+// Reserve 检查并预留租户的用量，检查与预留在同一把锁内完成，同一租户的并发请求不会同时通过检查
+// check收到租户其他进行中请求已预留的用量，返回本次需要预留的用量；返回错误时不预留
+// 请求结束后调用返回的释放函数：成功的请求先通过Record记录实际用量再释放，失败的请求直接释放即退还预留
+func (l *Ledger) Reserve(tenant string, check func(reserved Totals) (Totals, error)) (func(), error) {
+	l.reserveMutex.Lock()
+	defer l.reserveMutex.Unlock()
+
+	amount, err := check(l.reserved[tenant])
+	if err != nil {
+		return nil, err
+	}
+
+	total := l.reserved[tenant]
+	total.merge(amount)
+	l.reserved[tenant] = total
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { l.release(tenant, amount) })
+	}, nil
+}
+
+// Reserved 获取租户进行中请求预留的用量
+func (l *Ledger) Reserved(tenant string) Totals {
+	l.reserveMutex.Lock()
+	defer l.reserveMutex.Unlock()
+	return l.reserved[tenant]
+}
+
+// release 归还一次预留
+func (l *Ledger) release(tenant string, amount Totals) {
+	l.reserveMutex.Lock()
+	defer l.reserveMutex.Unlock()
+
+	total := l.reserved[tenant]
+	total.Requests -= amount.Requests
+	total.GeneratedImages -= amount.GeneratedImages
+	total.OutputTokens -= amount.OutputTokens
+	total.TotalTokens -= amount.TotalTokens
+	total.Cost -= amount.Cost
+	if total.Requests <= 0 {
+		delete(l.reserved, tenant)
+		return
+	}
+	l.reserved[tenant] = total
+}
@@ -1,81 +1,123 @@
 package logger
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"strings"
+
+	"gopkg.in/natefinch/lumberjack.v2"
 )
 
+// Options 日志器选项
+type Options struct {
+	Level      string // debug, info, warn, error
+	Format     string // json, text
+	Output     string // stdout, stderr, file
+	File       string
+	MaxSize    int // 单个日志文件的最大大小（MB）
+	MaxAge     int // 轮转文件的保留天数，0表示不按时间清理
+	MaxBackups int // 轮转文件的最大保留数量，0表示不限制
+	Compress   bool
+
+	Redaction RedactionOptions
+}
+
 // Logger 结构化日志器
 type Logger struct {
 	*slog.Logger
+	level  *slog.LevelVar
+	closer io.Closer
 }
 
-// New 创建新的日志器
-func New() *Logger {
-	// 创建JSON格式的日志处理器
-	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: slog.LevelInfo,
-	})
+// New 根据选项创建日志器
+func New(cfg Options) (*Logger, error) {
+	level := new(slog.LevelVar)
+	parsed, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	level.Set(parsed)
+
+	var (
+		output io.Writer
+		closer io.Closer
+	)
+	switch cfg.Output {
+	case "", "stdout":
+		output = os.Stdout
+	case "stderr":
+		output = os.Stderr
+	case "file":
+		rotator := &lumberjack.Logger{
+			Filename:   cfg.File,
+			MaxSize:    cfg.MaxSize,
+			MaxAge:     cfg.MaxAge,
+			MaxBackups: cfg.MaxBackups,
+			Compress:   cfg.Compress,
+		}
+		output, closer = rotator, rotator
+	default:
+		return nil, fmt.Errorf("invalid log output: %s", cfg.Output)
+	}
 
-	logger := slog.New(handler)
+	opts := &slog.HandlerOptions{Level: level}
 
-	return &Logger{
-		Logger: logger,
+	redactor, err := NewRedactor(cfg.Redaction)
+	if err != nil {
+		return nil, err
+	}
+	if redactor != nil {
+		opts.ReplaceAttr = redactor.ReplaceAttr
 	}
-}
 
-// NewWithLevel 创建指定级别的日志器
-func NewWithLevel(level string) *Logger {
-	var logLevel slog.Level
-	switch level {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "info":
-		logLevel = slog.LevelInfo
-	case "warn":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
+	var handler slog.Handler
+	switch cfg.Format {
+	case "", "json":
+		handler = slog.NewJSONHandler(output, opts)
+	case "text":
+		handler = slog.NewTextHandler(output, opts)
 	default:
-		logLevel = slog.LevelInfo
+		return nil, fmt.Errorf("invalid log format: %s", cfg.Format)
 	}
 
-	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
-	})
-
-	logger := slog.New(handler)
-
 	return &Logger{
-		Logger: logger,
-	}
+		Logger: slog.New(&contextHandler{Handler: handler}),
+		level:  level,
+		closer: closer,
+	}, nil
 }
 
-// NewTextLogger 创建文本格式的日志器
-func NewTextLogger(level string) *Logger {
-	var logLevel slog.Level
-	switch level {
+// ParseLevel 解析日志级别名称
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
 	case "debug":
-		logLevel = slog.LevelDebug
-	case "info":
-		logLevel = slog.LevelInfo
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
 	case "warn":
-		logLevel = slog.LevelWarn
+		return slog.LevelWarn, nil
 	case "error":
-		logLevel = slog.LevelError
+		return slog.LevelError, nil
 	default:
-		logLevel = slog.LevelInfo
+		return slog.LevelInfo, fmt.Errorf("invalid log level: %s", level)
 	}
+}
 
-	handler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
-	})
-
-	logger := slog.New(handler)
+// Level 返回当前日志级别名称
+func (l *Logger) Level() string {
+	return strings.ToLower(l.level.Level().String())
+}
 
-	return &Logger{
-		Logger: logger,
+// SetLevel 在运行时调整日志级别
+func (l *Logger) SetLevel(level string) error {
+	parsed, err := ParseLevel(level)
+	if err != nil {
+		return err
 	}
+	l.level.Set(parsed)
+	return nil
 }
 
 // WithFields 添加字段
@@ -87,6 +129,7 @@ func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
 
 	return &Logger{
 		Logger: l.Logger.With(args...),
+		level:  l.level,
 	}
 }
 
@@ -94,11 +137,21 @@ func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
 func (l *Logger) WithField(key string, value interface{}) *Logger {
 	return &Logger{
 		Logger: l.Logger.With(key, value),
+		level:  l.level,
+	}
+}
+
+// Close 关闭日志文件（输出到标准输出时无操作）
+func (l *Logger) Close() error {
+	if l.closer == nil {
+		return nil
 	}
+	return l.closer.Close()
 }
 
 // Fatal 记录致命错误并退出
 func (l *Logger) Fatal(msg string, args ...interface{}) {
 	l.Logger.Error(msg, args...)
+	l.Close()
 	os.Exit(1)
 }
@@ -0,0 +1,165 @@
+package logger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// 提示词脱敏方式
+const (
+	PromptHash     = "hash"
+	PromptTruncate = "truncate"
+	PromptOff      = "off"
+)
+
+// redactedValue 脱敏后的占位值
+const redactedValue = "[REDACTED]"
+
+// defaultPromptKeys 默认视为提示词的日志字段
+var defaultPromptKeys = []string{"prompt", "revised_prompt"}
+
+// defaultSecretKeys 默认视为密钥的日志字段（不区分大小写）
+var defaultSecretKeys = []string{"authorization", "api_key", "apikey", "token", "secret", "password"}
+
+// builtinPatterns 内置的敏感信息匹配规则
+var builtinPatterns = []redactPattern{
+	// Authorization头中的Bearer/ApiKey凭据
+	{regexp.MustCompile(`(?i)\b(bearer|apikey)\s+[A-Za-z0-9._~+/=-]+`), "$1 " + redactedValue},
+	// 本服务签发的API密钥
+	{regexp.MustCompile(`\bsia_[A-Za-z0-9_-]{8,}`), redactedValue},
+}
+
+// urlQueryPattern URL的查询串（签名URL的签名、过期时间等都在其中）
+var urlQueryPattern = regexp.MustCompile(`(https?://[^\s?#"'<>]+)\?[^\s#"'<>]*`)
+
+// RedactionOptions 脱敏选项
+type RedactionOptions struct {
+	Enabled      bool
+	Prompts      string // hash, truncate, off
+	PromptLength int    // 截断时保留的字符数
+	PromptKeys   []string
+	SecretKeys   []string
+	MaskURLQuery bool
+	Patterns     []RedactionPattern
+}
+
+// RedactionPattern 自定义脱敏规则
+type RedactionPattern struct {
+	Pattern     string
+	Replacement string // 为空时替换为[REDACTED]
+}
+
+// redactPattern 正则替换规则
+type redactPattern struct {
+	re          *regexp.Regexp
+	replacement string
+}
+
+// Redactor 日志脱敏器
+type Redactor struct {
+	prompts      string
+	promptLength int
+	promptKeys   map[string]bool
+	secretKeys   map[string]bool
+	maskURLQuery bool
+	patterns     []redactPattern
+}
+
+// NewRedactor 根据选项创建脱敏器，未启用时返回nil
+func NewRedactor(cfg RedactionOptions) (*Redactor, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	r := &Redactor{
+		prompts:      cfg.Prompts,
+		promptLength: cfg.PromptLength,
+		promptKeys:   toSet(defaultPromptKeys, cfg.PromptKeys),
+		secretKeys:   toSet(defaultSecretKeys, cfg.SecretKeys),
+		maskURLQuery: cfg.MaskURLQuery,
+		patterns:     append([]redactPattern(nil), builtinPatterns...),
+	}
+
+	for _, pattern := range cfg.Patterns {
+		re, err := regexp.Compile(pattern.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern.Pattern, err)
+		}
+		replacement := pattern.Replacement
+		if replacement == "" {
+			replacement = redactedValue
+		}
+		r.patterns = append(r.patterns, redactPattern{re: re, replacement: replacement})
+	}
+
+	return r, nil
+}
+
+// ReplaceAttr 用作slog.HandlerOptions.ReplaceAttr，对每个字段脱敏
+func (r *Redactor) ReplaceAttr(_ []string, a slog.Attr) slog.Attr {
+	key := strings.ToLower(a.Key)
+
+	if r.secretKeys[key] {
+		return slog.String(a.Key, redactedValue)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		value := a.Value.String()
+		if r.promptKeys[key] {
+			return slog.String(a.Key, r.Prompt(value))
+		}
+		return slog.String(a.Key, r.String(value))
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, r.String(err.Error()))
+		}
+	}
+
+	return a
+}
+
+// Prompt 按配置对提示词做哈希或截断
+func (r *Redactor) Prompt(prompt string) string {
+	switch r.prompts {
+	case PromptHash:
+		sum := sha256.Sum256([]byte(prompt))
+		return fmt.Sprintf("sha256:%s (%d chars)", hex.EncodeToString(sum[:6]), utf8.RuneCountInString(prompt))
+	case PromptTruncate:
+		if utf8.RuneCountInString(prompt) <= r.promptLength {
+			return r.String(prompt)
+		}
+		runes := []rune(prompt)
+		return fmt.Sprintf("%s…(%d chars)", r.String(string(runes[:r.promptLength])), len(runes))
+	default:
+		return r.String(prompt)
+	}
+}
+
+// String 对任意文本应用凭据、签名URL及自定义规则
+func (r *Redactor) String(s string) string {
+	if r.maskURLQuery {
+		s = urlQueryPattern.ReplaceAllString(s, "$1?"+redactedValue)
+	}
+	for _, pattern := range r.patterns {
+		s = pattern.re.ReplaceAllString(s, pattern.replacement)
+	}
+	return s
+}
+
+// toSet 合并默认值与配置值为小写集合
+func toSet(defaults, extra []string) map[string]bool {
+	set := make(map[string]bool, len(defaults)+len(extra))
+	for _, key := range defaults {
+		set[strings.ToLower(key)] = true
+	}
+	for _, key := range extra {
+		set[strings.ToLower(key)] = true
+	}
+	return set
+}
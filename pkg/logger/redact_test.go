@@ -0,0 +1,157 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNewRedactorDisabled(t *testing.T) {
+	redactor, err := NewRedactor(RedactionOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if redactor != nil {
+		t.Fatal("NewRedactor() returned a redactor while disabled")
+	}
+}
+
+func TestNewRedactorInvalidPattern(t *testing.T) {
+	_, err := NewRedactor(RedactionOptions{Enabled: true, Patterns: []RedactionPattern{{Pattern: "("}}})
+	if err == nil {
+		t.Fatal("NewRedactor() accepted an invalid pattern")
+	}
+}
+
+func TestRedactorString(t *testing.T) {
+	redactor, err := NewRedactor(RedactionOptions{
+		Enabled:      true,
+		MaskURLQuery: true,
+		Patterns: []RedactionPattern{
+			{Pattern: `\d{3}-\d{4}`},
+			{Pattern: `user=(\w+)`, Replacement: "user=***"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "bearer", input: "Authorization: Bearer abc.def-123", want: "Authorization: Bearer [REDACTED]"},
+		{name: "apikey case insensitive", input: "apikey XYZ987", want: "apikey [REDACTED]"},
+		{name: "service key", input: "key sia_live_0123456789 used", want: "key [REDACTED] used"},
+		{name: "short service prefix", input: "sia_abc", want: "sia_abc"},
+		{name: "signed url", input: "see https://cdn.example.com/a.png?sig=abc&exp=1 now", want: "see https://cdn.example.com/a.png?[REDACTED] now"},
+		{name: "url without query", input: "https://cdn.example.com/a.png", want: "https://cdn.example.com/a.png"},
+		{name: "custom default replacement", input: "call 555-1234", want: "call [REDACTED]"},
+		{name: "custom replacement", input: "user=alice", want: "user=***"},
+		{name: "plain", input: "nothing to hide", want: "nothing to hide"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactor.String(tt.input); got != tt.want {
+				t.Errorf("String(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactorPrompt(t *testing.T) {
+	tests := []struct {
+		name   string
+		mode   string
+		length int
+		input  string
+		want   string
+	}{
+		{name: "hash", mode: PromptHash, input: "a cat", want: "sha256:"},
+		{name: "truncate long", mode: PromptTruncate, length: 3, input: "一只橘猫在睡觉", want: "一只橘…(7 chars)"},
+		{name: "truncate short", mode: PromptTruncate, length: 10, input: "a cat", want: "a cat"},
+		{name: "truncate masks credentials", mode: PromptTruncate, length: 100, input: "Bearer secret-token", want: "Bearer [REDACTED]"},
+		{name: "off", mode: PromptOff, input: "a cat", want: "a cat"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redactor, err := NewRedactor(RedactionOptions{Enabled: true, Prompts: tt.mode, PromptLength: tt.length})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := redactor.Prompt(tt.input)
+			if tt.mode == PromptHash {
+				if !strings.HasPrefix(got, tt.want) || strings.Contains(got, tt.input) || !strings.HasSuffix(got, "(5 chars)") {
+					t.Errorf("Prompt(%q) = %q", tt.input, got)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Prompt(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactorReplaceAttr(t *testing.T) {
+	redactor, err := NewRedactor(RedactionOptions{
+		Enabled:      true,
+		Prompts:      PromptTruncate,
+		PromptLength: 4,
+		PromptKeys:   []string{"caption"},
+		SecretKeys:   []string{"X-Signature"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		attr slog.Attr
+		want string
+	}{
+		{name: "default secret key", attr: slog.String("Authorization", "anything"), want: redactedValue},
+		{name: "secret key of any kind", attr: slog.Int("password", 1234), want: redactedValue},
+		{name: "custom secret key", attr: slog.String("x-signature", "abc"), want: redactedValue},
+		{name: "default prompt key", attr: slog.String("prompt", "a sleeping cat"), want: "a sl…(14 chars)"},
+		{name: "custom prompt key", attr: slog.String("caption", "a sleeping cat"), want: "a sl…(14 chars)"},
+		{name: "string value", attr: slog.String("message", "Bearer abc"), want: "Bearer " + redactedValue},
+		{name: "error value", attr: slog.Any("error", errors.New("upstream rejected Bearer abc")), want: "upstream rejected Bearer " + redactedValue},
+		{name: "other kinds untouched", attr: slog.Int("count", 3), want: "3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := redactor.ReplaceAttr(nil, tt.attr)
+			if got.Key != tt.attr.Key || got.Value.String() != tt.want {
+				t.Errorf("ReplaceAttr(%v) = %v, want %s=%s", tt.attr, got, tt.attr.Key, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactorHandler(t *testing.T) {
+	redactor, err := NewRedactor(RedactionOptions{Enabled: true, Prompts: PromptHash})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{ReplaceAttr: redactor.ReplaceAttr}))
+	log.Info("generate", "prompt", "a secret plan", "api_key", "sk-123", slog.Group("request", "token", "abc"))
+
+	if strings.Contains(buf.String(), "a secret plan") || strings.Contains(buf.String(), "sk-123") || strings.Contains(buf.String(), `"abc"`) {
+		t.Fatalf("log line leaked sensitive values: %s", buf.String())
+	}
+
+	var line map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatal(err)
+	}
+	if line["msg"] != "generate" {
+		t.Errorf("msg = %v, want generate", line["msg"])
+	}
+}